@@ -0,0 +1,762 @@
+// Package apitest 提供基于httptest的内存版SBP OpenAPI模拟服务
+// 用于在没有真实OpenAPI的环境（如CI）中测试api.Client及上层业务流程
+package apitest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/helailiang/sbp-did-sdk-go/pkg/api"
+	"github.com/helailiang/sbp-did-sdk-go/pkg/wallet"
+)
+
+// 模拟服务返回的业务码，与api包的错误分类保持一致
+const (
+	CodeSuccess      = api.CodeSuccess
+	CodeBadRequest   = "400"
+	CodeUnauthorized = "401"
+	CodeNotFound     = "404"
+	CodeDuplicate    = "409"
+	CodeForbidden    = "403"
+)
+
+// Fault 故障注入配置
+type Fault struct {
+	Path           string        // 生效的接口路径，为空时对所有接口生效
+	Delay          time.Duration // 响应前的延迟
+	StatusCode     int           // 返回的HTTP状态码，为0时不修改
+	Code           string        // HTTP 200时返回的业务码，为空时不修改
+	Message        string        // 错误信息
+	DropConnection bool          // 直接断开连接，模拟连接被重置
+	Times          int           // 生效次数，<=0表示一直生效
+}
+
+// RecordedRequest 模拟服务收到的请求记录
+type RecordedRequest struct {
+	Method         string
+	Path           string
+	Token          string
+	IdempotencyKey string
+	Body           []byte
+}
+
+// DIDRecord DID状态
+type DIDRecord struct {
+	DID         string
+	ProjectNo   string
+	DIDDocument string
+	Version     int
+}
+
+// IssuerRecord 发证方状态
+type IssuerRecord struct {
+	api.RegisterIssuerRequest
+	Enabled bool
+}
+
+// TemplateRecord VC模板状态
+type TemplateRecord struct {
+	ProjectNo  string
+	IssuerDid  string
+	VCTemplate api.VCTemplate
+	Enabled    bool
+}
+
+// CredentialRecord VC状态
+type CredentialRecord struct {
+	VcId      string
+	ProjectNo string
+	IssuerDid string
+	VcHash    string
+	TxHash    string
+	Issued    bool
+	Revoked   bool
+}
+
+// Server 内存版OpenAPI模拟服务
+// 所有状态保存在内存中，并发安全
+type Server struct {
+	*httptest.Server
+
+	// RequireToken 为true时，除申请Token外的接口都必须携带有效Token
+	RequireToken bool
+
+	mu          sync.Mutex
+	dids        map[string]*DIDRecord
+	issuers     map[string]*IssuerRecord
+	templates   map[string]*TemplateRecord
+	credentials map[string]*CredentialRecord
+	projects    map[string]int
+	tokens      map[string]bool
+	tokenSeq    int
+	faults      []*Fault
+	requests    []RecordedRequest
+	idempotent  map[string][]byte
+	handlers    map[string]func(body []byte) (interface{}, string, string)
+}
+
+// NewServer 创建并启动模拟服务，使用完毕后需调用Close
+func NewServer() *Server {
+	s := &Server{
+		dids:        make(map[string]*DIDRecord),
+		issuers:     make(map[string]*IssuerRecord),
+		templates:   make(map[string]*TemplateRecord),
+		credentials: make(map[string]*CredentialRecord),
+		projects:    make(map[string]int),
+		tokens:      make(map[string]bool),
+		idempotent:  make(map[string][]byte),
+	}
+	s.handlers = map[string]func(body []byte) (interface{}, string, string){
+		"/api/sys/v1/did/register":          s.registerDID,
+		"/api/sys/v1/did/search":            s.queryDID,
+		"/api/sys/v1/did/update":            s.updateDID,
+		"/api/sys/v1/issuer/register":       s.registerIssuer,
+		"/api/sys/v1/issuer/search":         s.queryIssuer,
+		"/api/sys/v1/issuer/update":         s.updateIssuer,
+		"/api/sys/v1/issuer/status/update":  s.issuerStatus,
+		"/api/sys/v1/vc/register":           s.registerTemplate,
+		"/api/sys/v1/vc/search":             s.queryTemplate,
+		"/api/sys/v1/vc/update":             s.updateTemplate,
+		"/api/sys/v1/vc/status/update":      s.templateStatus,
+		"/api/sys/v1/vc/issue":              s.issueVC,
+		"/api/sys/v1/vc/evidence":           s.vcEvidence,
+		"/api/sys/v1/vc/evidence/search":    s.queryEvidence,
+		"/api/sys/v1/vc/revoke":             s.revokeVC,
+		"/api/sys/v1/vc/status/search":      s.revokeStatus,
+		"/api/sys/v1/vc/verify":             s.verifyVC,
+		"/api/sys/v1/vp/generate":           s.generateVP,
+		"/api/sys/v1/vp/verify":             s.verifyVP,
+		"/api/sys/v1/project/token":         s.getToken,
+		"/api/sys/v1/project/status/update": s.projectStatus,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client 返回指向模拟服务的api.Client
+func (s *Server) Client() *api.Client {
+	return api.NewClient(s.URL, "")
+}
+
+// InjectFault 注入故障，按注入顺序匹配第一个生效的故障
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults 清除所有故障
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// Requests 返回收到的请求记录
+func (s *Server) Requests() []RecordedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]RecordedRequest(nil), s.requests...)
+}
+
+// RevokeTokens 使已签发的Token全部失效
+func (s *Server) RevokeTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens = make(map[string]bool)
+}
+
+// DID 返回DID状态
+func (s *Server) DID(did string) (DIDRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.dids[did]
+	if !ok {
+		return DIDRecord{}, false
+	}
+	return *rec, true
+}
+
+// Issuer 返回发证方状态
+func (s *Server) Issuer(issuerDid string) (IssuerRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.issuers[issuerDid]
+	if !ok {
+		return IssuerRecord{}, false
+	}
+	return *rec, true
+}
+
+// Template 返回VC模板状态
+func (s *Server) Template(templateID string) (TemplateRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.templates[templateID]
+	if !ok {
+		return TemplateRecord{}, false
+	}
+	return *rec, true
+}
+
+// Credential 返回VC状态
+func (s *Server) Credential(vcID string) (CredentialRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.credentials[vcID]
+	if !ok {
+		return CredentialRecord{}, false
+	}
+	return *rec, true
+}
+
+// ProjectStatus 返回项目状态，未设置过时返回1（启用）
+func (s *Server) ProjectStatus(projectNo string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if status, ok := s.projects[projectNo]; ok {
+		return status
+	}
+	return 1
+}
+
+// serveHTTP 处理所有请求
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	token := r.Header.Get("token")
+	key := r.Header.Get(api.IdempotencyKeyHeader)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, RecordedRequest{
+		Method:         r.Method,
+		Path:           r.URL.Path,
+		Token:          token,
+		IdempotencyKey: key,
+		Body:           body,
+	})
+	fault := s.matchFault(r.URL.Path)
+	s.mu.Unlock()
+
+	if fault != nil {
+		if fault.Delay > 0 {
+			select {
+			case <-time.After(fault.Delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if fault.DropConnection {
+			if hj, ok := w.(http.Hijacker); ok {
+				if conn, _, err := hj.Hijack(); err == nil {
+					conn.Close()
+					return
+				}
+			}
+		}
+		if fault.StatusCode != 0 && fault.StatusCode != http.StatusOK {
+			writeJSON(w, fault.StatusCode, fault.Code, nil, fault.Message)
+			return
+		}
+		if fault.Code != "" {
+			writeJSON(w, http.StatusOK, fault.Code, nil, fault.Message)
+			return
+		}
+	}
+
+	handler, ok := s.handlers[r.URL.Path]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, CodeNotFound, nil, "no such endpoint: "+r.URL.Path)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, CodeBadRequest, nil, "method not allowed")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.RequireToken && r.URL.Path != "/api/sys/v1/project/token" && !s.tokens[token] {
+		writeJSON(w, http.StatusUnauthorized, CodeUnauthorized, nil, "invalid or expired token")
+		return
+	}
+	// 相同幂等键的重复请求直接返回首次结果
+	idemKey := r.URL.Path + "|" + key
+	if key != "" {
+		if cached, ok := s.idempotent[idemKey]; ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(cached)
+			return
+		}
+	}
+	data, code, msg := handler(body)
+	resp := marshalResponse(code, data, msg)
+	if key != "" && code == CodeSuccess {
+		s.idempotent[idemKey] = resp
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
+
+// matchFault 查找生效的故障，调用方需持有锁
+func (s *Server) matchFault(path string) *Fault {
+	for i, f := range s.faults {
+		if f.Path != "" && f.Path != path {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+// marshalResponse 生成通用响应结构
+func marshalResponse(code string, data interface{}, msg string) []byte {
+	if msg == "" && code == CodeSuccess {
+		msg = "success"
+	}
+	out, _ := json.Marshal(map[string]interface{}{
+		"code":    code,
+		"data":    data,
+		"message": msg,
+	})
+	return out
+}
+
+// writeJSON 写入通用响应
+func writeJSON(w http.ResponseWriter, status int, code string, data interface{}, msg string) {
+	if code == "" {
+		code = fmt.Sprintf("%d", status)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(marshalResponse(code, data, msg))
+}
+
+// txHash 生成模拟的交易哈希
+func txHash(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+	}
+	h.Write([]byte(time.Now().String()))
+	return "0x" + hex.EncodeToString(h.Sum(nil))
+}
+
+// decode 解析请求体
+func decode(body []byte, v interface{}) (string, string) {
+	if err := json.Unmarshal(body, v); err != nil {
+		return CodeBadRequest, "invalid request body: " + err.Error()
+	}
+	return CodeSuccess, ""
+}
+
+// documentID 从DID文档JSON中提取id
+func documentID(doc string) (string, bool) {
+	var d struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(doc), &d); err != nil || d.ID == "" {
+		return "", false
+	}
+	return d.ID, true
+}
+
+// ========== DID ========== //
+
+func (s *Server) registerDID(body []byte) (interface{}, string, string) {
+	var req api.RegisterDIDRequest
+	if code, msg := decode(body, &req); code != CodeSuccess {
+		return nil, code, msg
+	}
+	id, ok := documentID(req.DIDDocument)
+	if !ok {
+		return nil, CodeBadRequest, "didDocument must be a JSON document with id"
+	}
+	if req.Signature == "" {
+		return nil, CodeBadRequest, "signature is required"
+	}
+	if _, exists := s.dids[id]; exists {
+		return nil, CodeDuplicate, "did already exists"
+	}
+	s.dids[id] = &DIDRecord{DID: id, ProjectNo: req.ProjectNo, DIDDocument: req.DIDDocument, Version: 1}
+	return map[string]string{"did": id, "txHash": txHash(id)}, CodeSuccess, ""
+}
+
+func (s *Server) queryDID(body []byte) (interface{}, string, string) {
+	var req api.QueryDIDRequest
+	if code, msg := decode(body, &req); code != CodeSuccess {
+		return nil, code, msg
+	}
+	rec, ok := s.dids[req.DID]
+	if !ok {
+		return nil, CodeNotFound, "did not found"
+	}
+	return map[string]string{"didDocument": rec.DIDDocument}, CodeSuccess, ""
+}
+
+func (s *Server) updateDID(body []byte) (interface{}, string, string) {
+	var req api.UpdateDIDRequest
+	if code, msg := decode(body, &req); code != CodeSuccess {
+		return nil, code, msg
+	}
+	id, ok := documentID(req.DIDDocument)
+	if !ok {
+		return nil, CodeBadRequest, "didDocument must be a JSON document with id"
+	}
+	rec, exists := s.dids[id]
+	if !exists {
+		return nil, CodeNotFound, "did not found"
+	}
+	rec.DIDDocument = req.DIDDocument
+	rec.Version++
+	return map[string]string{"did": id, "txHash": txHash(id)}, CodeSuccess, ""
+}
+
+// ========== Issuer ========== //
+
+func (s *Server) registerIssuer(body []byte) (interface{}, string, string) {
+	var req api.RegisterIssuerRequest
+	if code, msg := decode(body, &req); code != CodeSuccess {
+		return nil, code, msg
+	}
+	if req.IssuerDid == "" {
+		return nil, CodeBadRequest, "issuerDid is required"
+	}
+	if _, ok := s.dids[req.IssuerDid]; !ok {
+		return nil, CodeNotFound, "issuer did not registered"
+	}
+	if _, exists := s.issuers[req.IssuerDid]; exists {
+		return nil, CodeDuplicate, "issuer already exists"
+	}
+	s.issuers[req.IssuerDid] = &IssuerRecord{RegisterIssuerRequest: req, Enabled: true}
+	return map[string]string{"txHash": txHash(req.IssuerDid)}, CodeSuccess, ""
+}
+
+func (s *Server) queryIssuer(body []byte) (interface{}, string, string) {
+	var req api.QueryIssuerRequest
+	if code, msg := decode(body, &req); code != CodeSuccess {
+		return nil, code, msg
+	}
+	rec, ok := s.issuers[req.IssuerDid]
+	if !ok {
+		return nil, CodeNotFound, "issuer not found"
+	}
+	return api.Issuer{IssuerDid: rec.IssuerDid, IssuerName: rec.IssuerName}, CodeSuccess, ""
+}
+
+func (s *Server) updateIssuer(body []byte) (interface{}, string, string) {
+	var req api.UpdateIssuerRequest
+	if code, msg := decode(body, &req); code != CodeSuccess {
+		return nil, code, msg
+	}
+	rec, ok := s.issuers[req.IssuerDid]
+	if !ok {
+		return nil, CodeNotFound, "issuer not found"
+	}
+	rec.IssuerName = req.IssuerName
+	return map[string]string{"txHash": txHash(req.IssuerDid)}, CodeSuccess, ""
+}
+
+// issuerStatus 切换发证方的启用/禁用状态
+func (s *Server) issuerStatus(body []byte) (interface{}, string, string) {
+	var req api.IssuerStatusRequest
+	if code, msg := decode(body, &req); code != CodeSuccess {
+		return nil, code, msg
+	}
+	rec, ok := s.issuers[req.IssuerDid]
+	if !ok {
+		return nil, CodeNotFound, "issuer not found"
+	}
+	rec.Enabled = !rec.Enabled
+	return map[string]bool{"enabled": rec.Enabled}, CodeSuccess, ""
+}
+
+// ========== VC模板 ========== //
+
+// checkIssuer 校验发证方存在且已启用
+func (s *Server) checkIssuer(issuerDid string) (string, string) {
+	rec, ok := s.issuers[issuerDid]
+	if !ok {
+		return CodeNotFound, "issuer not found"
+	}
+	if !rec.Enabled {
+		return CodeForbidden, "issuer is disabled"
+	}
+	return CodeSuccess, ""
+}
+
+func (s *Server) registerTemplate(body []byte) (interface{}, string, string) {
+	var req api.RegisterVCTemplateRequest
+	if code, msg := decode(body, &req); code != CodeSuccess {
+		return nil, code, msg
+	}
+	if code, msg := s.checkIssuer(req.IssuerDid); code != CodeSuccess {
+		return nil, code, msg
+	}
+	id := req.VCTemplate.TemplateId
+	if id == "" {
+		return nil, CodeBadRequest, "templateId is required"
+	}
+	if _, exists := s.templates[id]; exists {
+		return nil, CodeDuplicate, "vc template already exists"
+	}
+	s.templates[id] = &TemplateRecord{
+		ProjectNo:  req.ProjectNo,
+		IssuerDid:  req.IssuerDid,
+		VCTemplate: req.VCTemplate,
+		Enabled:    true,
+	}
+	return map[string]string{"txHash": txHash(id)}, CodeSuccess, ""
+}
+
+func (s *Server) queryTemplate(body []byte) (interface{}, string, string) {
+	var req api.QueryVCTemplateRequest
+	if code, msg := decode(body, &req); code != CodeSuccess {
+		return nil, code, msg
+	}
+	rec, ok := s.templates[req.VCTemplateId]
+	if !ok {
+		return nil, CodeNotFound, "vc template not found"
+	}
+	tpl, _ := json.Marshal(rec.VCTemplate)
+	return map[string]string{"vcTemplate": string(tpl)}, CodeSuccess, ""
+}
+
+func (s *Server) updateTemplate(body []byte) (interface{}, string, string) {
+	var req api.UpdateVCTemplateRequest
+	if code, msg := decode(body, &req); code != CodeSuccess {
+		return nil, code, msg
+	}
+	rec, ok := s.templates[req.VCTemplate.TemplateId]
+	if !ok {
+		return nil, CodeNotFound, "vc template not found"
+	}
+	if rec.IssuerDid != req.IssuerDid {
+		return nil, CodeForbidden, "vc template belongs to another issuer"
+	}
+	rec.VCTemplate = req.VCTemplate
+	return map[string]string{"txHash": txHash(req.VCTemplate.TemplateId)}, CodeSuccess, ""
+}
+
+// templateStatus 切换VC模板的启用/禁用状态
+func (s *Server) templateStatus(body []byte) (interface{}, string, string) {
+	var req api.VCTemplateStatusRequest
+	if code, msg := decode(body, &req); code != CodeSuccess {
+		return nil, code, msg
+	}
+	rec, ok := s.templates[req.VCTemplateId]
+	if !ok {
+		return nil, CodeNotFound, "vc template not found"
+	}
+	rec.Enabled = !rec.Enabled
+	return map[string]bool{"enabled": rec.Enabled}, CodeSuccess, ""
+}
+
+// ========== VC ========== //
+
+func (s *Server) issueVC(body []byte) (interface{}, string, string) {
+	var req api.IssueVCRequest
+	if code, msg := decode(body, &req); code != CodeSuccess {
+		return nil, code, msg
+	}
+	tpl, ok := s.templates[req.VCTemplateId]
+	if !ok {
+		return nil, CodeNotFound, "vc template not found"
+	}
+	if !tpl.Enabled {
+		return nil, CodeForbidden, "vc template is disabled"
+	}
+	if code, msg := s.checkIssuer(req.VC.Issuer); code != CodeSuccess {
+		return nil, code, msg
+	}
+	if req.VC.ID == "" {
+		return nil, CodeBadRequest, "vc id is required"
+	}
+	rec, exists := s.credentials[req.VC.ID]
+	if exists && rec.Issued {
+		return nil, CodeDuplicate, "vc already issued"
+	}
+	if !exists {
+		rec = &CredentialRecord{VcId: req.VC.ID, ProjectNo: req.ProjectNo, IssuerDid: req.VC.Issuer}
+		s.credentials[req.VC.ID] = rec
+	}
+	rec.Issued = true
+	return map[string]string{"vcId": rec.VcId}, CodeSuccess, ""
+}
+
+func (s *Server) vcEvidence(body []byte) (interface{}, string, string) {
+	var req api.VCEvidenceRequest
+	if code, msg := decode(body, &req); code != CodeSuccess {
+		return nil, code, msg
+	}
+	if req.VcId == "" || req.VcHash == "" {
+		return nil, CodeBadRequest, "vcId and vcHash are required"
+	}
+	if code, msg := s.checkIssuer(req.IssuerDid); code != CodeSuccess {
+		return nil, code, msg
+	}
+	rec, exists := s.credentials[req.VcId]
+	if exists && rec.VcHash != "" {
+		return nil, CodeDuplicate, "vc evidence already exists"
+	}
+	if !exists {
+		rec = &CredentialRecord{VcId: req.VcId, ProjectNo: req.ProjectNo}
+		s.credentials[req.VcId] = rec
+	}
+	rec.IssuerDid = req.IssuerDid
+	rec.VcHash = req.VcHash
+	rec.TxHash = txHash(req.VcId, req.VcHash)
+	return map[string]string{"txHash": rec.TxHash}, CodeSuccess, ""
+}
+
+func (s *Server) queryEvidence(body []byte) (interface{}, string, string) {
+	var req api.QueryVCEvidenceRequest
+	if code, msg := decode(body, &req); code != CodeSuccess {
+		return nil, code, msg
+	}
+	rec, ok := s.credentials[req.VcId]
+	if !ok || rec.VcHash == "" {
+		return nil, CodeNotFound, "vc evidence not found"
+	}
+	return map[string]string{
+		"vcId":      rec.VcId,
+		"vcHash":    rec.VcHash,
+		"issuerDid": rec.IssuerDid,
+		"txHash":    rec.TxHash,
+	}, CodeSuccess, ""
+}
+
+// credentialID 从VC JSON中提取id
+func credentialID(vc string) (string, bool) {
+	return documentID(vc)
+}
+
+func (s *Server) revokeVC(body []byte) (interface{}, string, string) {
+	var req api.VCRevokeRequest
+	if code, msg := decode(body, &req); code != CodeSuccess {
+		return nil, code, msg
+	}
+	id, ok := credentialID(req.VC)
+	if !ok {
+		return nil, CodeBadRequest, "vc must be a JSON credential with id"
+	}
+	rec, exists := s.credentials[id]
+	if !exists {
+		return nil, CodeNotFound, "vc not found"
+	}
+	if rec.Revoked {
+		return nil, CodeDuplicate, "vc already revoked"
+	}
+	rec.Revoked = true
+	return map[string]string{"txHash": txHash(id, "revoke")}, CodeSuccess, ""
+}
+
+func (s *Server) revokeStatus(body []byte) (interface{}, string, string) {
+	var req api.VCRevokeStatusRequest
+	if code, msg := decode(body, &req); code != CodeSuccess {
+		return nil, code, msg
+	}
+	rec, ok := s.credentials[req.VcId]
+	if !ok {
+		return nil, CodeNotFound, "vc not found"
+	}
+	return map[string]bool{"revokeStatus": rec.Revoked}, CodeSuccess, ""
+}
+
+// verifyVC VC已签发或已存证、未吊销且发证方已启用时核验通过
+func (s *Server) verifyVC(body []byte) (interface{}, string, string) {
+	var req api.VCVerifyRequest
+	if code, msg := decode(body, &req); code != CodeSuccess {
+		return nil, code, msg
+	}
+	id, ok := credentialID(req.VC)
+	if !ok {
+		return nil, CodeBadRequest, "vc must be a JSON credential with id"
+	}
+	rec, exists := s.credentials[id]
+	valid := exists && !rec.Revoked
+	if valid {
+		code, _ := s.checkIssuer(rec.IssuerDid)
+		valid = code == CodeSuccess
+	}
+	return map[string]bool{"verificationStatus": valid}, CodeSuccess, ""
+}
+
+// ========== VP ========== //
+
+func (s *Server) generateVP(body []byte) (interface{}, string, string) {
+	var req api.GenerateVPRequest
+	if code, msg := decode(body, &req); code != CodeSuccess {
+		return nil, code, msg
+	}
+	if req.Holder == "" {
+		return nil, CodeBadRequest, "holder is required"
+	}
+	if _, ok := s.dids[req.Holder]; !ok {
+		return nil, CodeNotFound, "holder did not registered"
+	}
+	vp := req.VerifiablePresentation
+	if vp.Proof == nil {
+		vp.Proof = &wallet.Proof{
+			Type:               "JsonWebSignature2020",
+			Created:            time.Now().UTC().Format(time.RFC3339),
+			ProofPurpose:       "authentication",
+			VerificationMethod: req.Holder + "#keys-1",
+			Challenge:          req.Challenge,
+			Domain:             req.Domain,
+			Jws:                txHash(req.Holder, req.Challenge),
+		}
+	}
+	return vp, CodeSuccess, ""
+}
+
+// verifyVP VP持有者已注册、包含证明且证明的challenge匹配时核验通过
+func (s *Server) verifyVP(body []byte) (interface{}, string, string) {
+	var req api.VerifyVPRequest
+	if code, msg := decode(body, &req); code != CodeSuccess {
+		return nil, code, msg
+	}
+	_, holderOK := s.dids[req.Holder]
+	valid := holderOK && req.Proof != nil && req.Proof.Challenge == req.Challenge
+	return map[string]bool{"verificationStatus": valid}, CodeSuccess, ""
+}
+
+// ========== 项目管理 ========== //
+
+func (s *Server) getToken(body []byte) (interface{}, string, string) {
+	var req api.GetTokenRequest
+	if code, msg := decode(body, &req); code != CodeSuccess {
+		return nil, code, msg
+	}
+	if req.ProjectNo == "" {
+		return nil, CodeBadRequest, "projectNo is required"
+	}
+	s.tokenSeq++
+	token := fmt.Sprintf("apitest-token-%d", s.tokenSeq)
+	s.tokens[token] = true
+	return map[string]string{"token": token}, CodeSuccess, ""
+}
+
+func (s *Server) projectStatus(body []byte) (interface{}, string, string) {
+	var req api.ProjectEnableRequest
+	if code, msg := decode(body, &req); code != CodeSuccess {
+		return nil, code, msg
+	}
+	if req.ProjectNo == "" {
+		return nil, CodeBadRequest, "projectNo is required"
+	}
+	s.projects[req.ProjectNo] = req.Status
+	return nil, CodeSuccess, ""
+}
@@ -2,11 +2,15 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"reflect"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // Client OpenAPI通用RESTful客户端
@@ -16,21 +20,35 @@ import (
 type Client struct {
 	BaseURL    string
 	Token      string // 私有项目需要
-	ProjectNO  string // 私有项目需要
+	ProjectNO  string // 项目编号，请求的ProjectNo为空时自动填充
 	HTTPClient *http.Client
+	Retry      *RetryPolicy // 重试策略，为nil时不重试
+	// TokenProvider 自动获取/刷新Token，设置后优先于Token字段
+	TokenProvider TokenProvider
+	// Middlewares 请求中间件，可通过Use追加
+	Middlewares []Middleware
 }
 
 // NewClient 创建OpenAPI客户端
-func NewClient(baseURL, token string) *Client {
+// 可通过opts设置超时、mTLS证书、CA证书池和代理，默认超时10秒
+func NewClient(baseURL, token string, opts ...ClientOption) *Client {
+	o := &clientOptions{timeout: DefaultTimeout}
+	for _, opt := range opts {
+		opt(o)
+	}
 	return &Client{
 		BaseURL:    baseURL,
 		Token:      token,
-		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+		HTTPClient: o.buildHTTPClient(),
 	}
 }
 
 // doRequest 执行HTTP请求
-func (c *Client) doRequest(method, path string, body interface{}, headers map[string]string) ([]byte, int, error) {
+// ctx被取消或超时时，正在进行的请求会被中断
+func (c *Client) doRequest(ctx context.Context, method, path string, body interface{}, headers map[string]string) ([]byte, int, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
 	url := c.BaseURL + path
 	var reqBody []byte
 	var err error
@@ -41,7 +59,33 @@ func (c *Client) doRequest(method, path string, body interface{}, headers map[st
 		}
 	}
 
-	req, err := http.NewRequest(method, url, bytes.NewReader(reqBody))
+	// 写操作携带幂等键，重试时复用，避免重复上链
+	if method != http.MethodGet {
+		headers = copyHeaders(headers)
+		if _, ok := headers[IdempotencyKeyHeader]; !ok {
+			key := idempotencyKeyFromContext(ctx)
+			if key == "" {
+				key = uuid.NewString()
+			}
+			headers[IdempotencyKeyHeader] = key
+		}
+	}
+
+	attempts := c.Retry.attempts()
+	for attempt := 1; ; attempt++ {
+		respBody, statusCode, err := c.send(ctx, method, url, reqBody, headers)
+		if attempt >= attempts || !c.Retry.shouldRetry(ctx, statusCode, err) {
+			return respBody, statusCode, err
+		}
+		if err := sleep(ctx, c.Retry.backoff(attempt)); err != nil {
+			return respBody, statusCode, fmt.Errorf("http request failed: %w", err)
+		}
+	}
+}
+
+// send 发送一次HTTP请求
+func (c *Client) send(ctx context.Context, method, url string, reqBody []byte, headers map[string]string) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(reqBody))
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -54,41 +98,158 @@ func (c *Client) doRequest(method, path string, body interface{}, headers map[st
 	for k, v := range headers {
 		req.Header.Set(k, v)
 	}
+	if err := c.beforeRequest(req); err != nil {
+		return nil, 0, fmt.Errorf("middleware rejected request: %w", err)
+	}
 
+	start := time.Now()
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, 0, fmt.Errorf("http request failed: %w", err)
+		err = fmt.Errorf("http request failed: %w", err)
+		c.onError(req, err, time.Since(start))
+		return nil, 0, err
 	}
 	defer resp.Body.Close()
 
 	respBody, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, resp.StatusCode, fmt.Errorf("failed to read response: %w", err)
+		err = fmt.Errorf("failed to read response: %w", err)
+		c.onError(req, err, time.Since(start))
+		return nil, resp.StatusCode, err
 	}
+	elapsed := time.Since(start)
+	c.afterResponse(req, resp, respBody, elapsed)
 
 	if resp.StatusCode >= 400 {
-		return respBody, resp.StatusCode, fmt.Errorf("http error: %d, %s", resp.StatusCode, string(respBody))
+		apiErr := newError(resp.StatusCode, respBody)
+		c.onError(req, apiErr, elapsed)
+		return respBody, resp.StatusCode, apiErr
 	}
 
 	return respBody, resp.StatusCode, nil
 }
 
+// copyHeaders 复制请求头，避免修改调用方传入的map
+func copyHeaders(headers map[string]string) map[string]string {
+	out := make(map[string]string, len(headers)+1)
+	for k, v := range headers {
+		out[k] = v
+	}
+	return out
+}
+
+// fillProjectNo 请求结构体的ProjectNo为空时使用客户端的ProjectNO
+// 返回填充后的副本，不修改调用方传入的请求
+func (c *Client) fillProjectNo(req interface{}) interface{} {
+	if c.ProjectNO == "" || req == nil {
+		return req
+	}
+	v := reflect.ValueOf(req)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return req
+	}
+	field := v.Elem().FieldByName("ProjectNo")
+	if !field.IsValid() || field.Kind() != reflect.String || field.String() != "" {
+		return req
+	}
+	cp := reflect.New(v.Elem().Type())
+	cp.Elem().Set(v.Elem())
+	cp.Elem().FieldByName("ProjectNo").SetString(c.ProjectNO)
+	return cp.Interface()
+}
+
+// postJSON 以POST方式调用OpenAPI接口，token放header，参数放body，响应解析到out
+// 业务码不为"0"时返回*Error；使用TokenProvider时，401会刷新Token后重试一次
+func (c *Client) postJSON(ctx context.Context, path string, req, out interface{}) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	req = c.fillProjectNo(req)
+	if c.TokenProvider == nil {
+		return c.postJSONWithToken(ctx, path, c.Token, req, out)
+	}
+	// 刷新Token后的重试与首次请求共用幂等键
+	if idempotencyKeyFromContext(ctx) == "" {
+		ctx = WithIdempotencyKey(ctx, uuid.NewString())
+	}
+	token, err := c.TokenProvider.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get token: %w", err)
+	}
+	err = c.postJSONWithToken(ctx, path, token, req, out)
+	if !IsUnauthorized(err) {
+		return err
+	}
+	c.TokenProvider.Invalidate(token)
+	if token, err = c.TokenProvider.Token(ctx); err != nil {
+		return fmt.Errorf("failed to refresh token: %w", err)
+	}
+	return c.postJSONWithToken(ctx, path, token, req, out)
+}
+
+// postJSONWithToken 使用指定token发送一次OpenAPI调用
+func (c *Client) postJSONWithToken(ctx context.Context, path, token string, req, out interface{}) error {
+	headers := map[string]string{}
+	if token != "" {
+		headers["token"] = token
+		headers["Authorization"] = "Bearer " + token
+	}
+	respBytes, statusCode, err := c.PostWithContext(ctx, path, req, headers)
+	if err != nil {
+		return err
+	}
+	// 仅当响应带有非"0"的业务码时视为业务错误
+	var envelope struct {
+		Code *string `json:"code"`
+	}
+	if err := json.Unmarshal(respBytes, &envelope); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if envelope.Code != nil && *envelope.Code != CodeSuccess {
+		return newError(statusCode, respBytes)
+	}
+	if err := json.Unmarshal(respBytes, out); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
+
 // Get GET请求
 func (c *Client) Get(path string, headers map[string]string) ([]byte, int, error) {
-	return c.doRequest("GET", path, nil, headers)
+	return c.GetWithContext(context.Background(), path, headers)
+}
+
+// GetWithContext 带上下文的GET请求
+func (c *Client) GetWithContext(ctx context.Context, path string, headers map[string]string) ([]byte, int, error) {
+	return c.doRequest(ctx, "GET", path, nil, headers)
 }
 
 // Post POST请求
 func (c *Client) Post(path string, body interface{}, headers map[string]string) ([]byte, int, error) {
-	return c.doRequest("POST", path, body, headers)
+	return c.PostWithContext(context.Background(), path, body, headers)
+}
+
+// PostWithContext 带上下文的POST请求
+func (c *Client) PostWithContext(ctx context.Context, path string, body interface{}, headers map[string]string) ([]byte, int, error) {
+	return c.doRequest(ctx, "POST", path, body, headers)
 }
 
 // Put PUT请求
 func (c *Client) Put(path string, body interface{}, headers map[string]string) ([]byte, int, error) {
-	return c.doRequest("PUT", path, body, headers)
+	return c.PutWithContext(context.Background(), path, body, headers)
+}
+
+// PutWithContext 带上下文的PUT请求
+func (c *Client) PutWithContext(ctx context.Context, path string, body interface{}, headers map[string]string) ([]byte, int, error) {
+	return c.doRequest(ctx, "PUT", path, body, headers)
 }
 
 // Delete DELETE请求
 func (c *Client) Delete(path string, headers map[string]string) ([]byte, int, error) {
-	return c.doRequest("DELETE", path, nil, headers)
+	return c.DeleteWithContext(context.Background(), path, headers)
+}
+
+// DeleteWithContext 带上下文的DELETE请求
+func (c *Client) DeleteWithContext(ctx context.Context, path string, headers map[string]string) ([]byte, int, error) {
+	return c.doRequest(ctx, "DELETE", path, nil, headers)
 }
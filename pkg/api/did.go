@@ -1,22 +1,18 @@
 package api
 
 import (
-	"encoding/json"
+	"context"
 )
 
 // RegisterDID 注册DID文档
 func (c *Client) RegisterDID(req *RegisterDIDRequest) (*CommonResponse, error) {
-	path := "/api/sys/v1/did/register"
-	headers := map[string]string{}
-	if c.Token != "" {
-		headers["token"] = c.Token
-	}
-	respBytes, _, err := c.Post(path, req, headers)
-	if err != nil {
-		return nil, err
-	}
+	return c.RegisterDIDWithContext(context.Background(), req)
+}
+
+// RegisterDIDWithContext 注册DID文档，ctx用于控制请求的取消和超时
+func (c *Client) RegisterDIDWithContext(ctx context.Context, req *RegisterDIDRequest) (*CommonResponse, error) {
 	var resp CommonResponse
-	if err := json.Unmarshal(respBytes, &resp); err != nil {
+	if err := c.postJSON(ctx, "/api/sys/v1/did/register", req, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -24,17 +20,13 @@ func (c *Client) RegisterDID(req *RegisterDIDRequest) (*CommonResponse, error) {
 
 // QueryDID 查询DID文档
 func (c *Client) QueryDID(req *QueryDIDRequest) (*QueryDIDResponse, error) {
-	path := "/api/sys/v1/did/search"
-	headers := map[string]string{}
-	if c.Token != "" {
-		headers["token"] = c.Token
-	}
-	respBytes, _, err := c.Post(path, req, headers)
-	if err != nil {
-		return nil, err
-	}
+	return c.QueryDIDWithContext(context.Background(), req)
+}
+
+// QueryDIDWithContext 查询DID文档，ctx用于控制请求的取消和超时
+func (c *Client) QueryDIDWithContext(ctx context.Context, req *QueryDIDRequest) (*QueryDIDResponse, error) {
 	var resp QueryDIDResponse
-	if err := json.Unmarshal(respBytes, &resp); err != nil {
+	if err := c.postJSON(ctx, "/api/sys/v1/did/search", req, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -42,17 +34,13 @@ func (c *Client) QueryDID(req *QueryDIDRequest) (*QueryDIDResponse, error) {
 
 // UpdateDID 更新DID文档
 func (c *Client) UpdateDID(req *UpdateDIDRequest) (*UpdateDIDResponse, error) {
-	path := "/api/sys/v1/did/update"
-	headers := map[string]string{}
-	if c.Token != "" {
-		headers["token"] = c.Token
-	}
-	respBytes, _, err := c.Post(path, req, headers)
-	if err != nil {
-		return nil, err
-	}
+	return c.UpdateDIDWithContext(context.Background(), req)
+}
+
+// UpdateDIDWithContext 更新DID文档，ctx用于控制请求的取消和超时
+func (c *Client) UpdateDIDWithContext(ctx context.Context, req *UpdateDIDRequest) (*UpdateDIDResponse, error) {
 	var resp UpdateDIDResponse
-	if err := json.Unmarshal(respBytes, &resp); err != nil {
+	if err := c.postJSON(ctx, "/api/sys/v1/did/update", req, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
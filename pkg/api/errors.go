@@ -0,0 +1,86 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// CodeSuccess 业务码"0"表示成功
+const CodeSuccess = "0"
+
+// 错误分类的哨兵错误，配合 errors.Is 使用：
+//
+//	if errors.Is(err, api.ErrNotFound) { ... }
+var (
+	ErrNotFound     = errors.New("openapi: not found")
+	ErrUnauthorized = errors.New("openapi: unauthorized")
+	ErrDuplicate    = errors.New("openapi: duplicate")
+)
+
+// 业务码分类表，可按实际平台返回的业务码补充
+var (
+	NotFoundCodes     = map[string]bool{"404": true}
+	UnauthorizedCodes = map[string]bool{"401": true, "403": true}
+	DuplicateCodes    = map[string]bool{"409": true}
+)
+
+// Error OpenAPI错误
+// HTTP状态码>=400，或HTTP成功但业务码不为"0"时返回
+type Error struct {
+	StatusCode int    // HTTP状态码
+	Code       string // 业务码
+	Message    string // 错误信息
+	Body       []byte // 原始响应体
+}
+
+// Error 实现error接口
+func (e *Error) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("openapi error: http %d, code %s, %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("openapi error: http %d, %s", e.StatusCode, string(e.Body))
+}
+
+// Is 支持 errors.Is(err, ErrNotFound) 等分类判断
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound || NotFoundCodes[e.Code]
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden || UnauthorizedCodes[e.Code]
+	case ErrDuplicate:
+		return e.StatusCode == http.StatusConflict || DuplicateCodes[e.Code]
+	}
+	return false
+}
+
+// IsNotFound 判断是否为资源不存在错误
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsUnauthorized 判断是否为鉴权失败错误
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+// IsDuplicate 判断是否为重复提交错误
+func IsDuplicate(err error) bool {
+	return errors.Is(err, ErrDuplicate)
+}
+
+// newError 根据HTTP状态码和响应体构造Error，响应体为通用响应结构时解析业务码和信息
+func newError(statusCode int, body []byte) *Error {
+	e := &Error{StatusCode: statusCode, Body: body}
+	var envelope struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &envelope) == nil {
+		e.Code = envelope.Code
+		e.Message = envelope.Message
+	}
+	return e
+}
@@ -1,22 +1,18 @@
 package api
 
 import (
-	"encoding/json"
+	"context"
 )
 
 // RegisterIssuer 注册发证方
 func (c *Client) RegisterIssuer(req *RegisterIssuerRequest) (*CommonResponse, error) {
-	path := "/api/sys/v1/issuer/register"
-	headers := map[string]string{}
-	if c.Token != "" {
-		headers["token"] = c.Token
-	}
-	respBytes, _, err := c.Post(path, req, headers)
-	if err != nil {
-		return nil, err
-	}
+	return c.RegisterIssuerWithContext(context.Background(), req)
+}
+
+// RegisterIssuerWithContext 注册发证方，ctx用于控制请求的取消和超时
+func (c *Client) RegisterIssuerWithContext(ctx context.Context, req *RegisterIssuerRequest) (*CommonResponse, error) {
 	var resp CommonResponse
-	if err := json.Unmarshal(respBytes, &resp); err != nil {
+	if err := c.postJSON(ctx, "/api/sys/v1/issuer/register", req, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -24,17 +20,13 @@ func (c *Client) RegisterIssuer(req *RegisterIssuerRequest) (*CommonResponse, er
 
 // QueryIssuer 查询发证方
 func (c *Client) QueryIssuer(req *QueryIssuerRequest) (*QueryIssuerResponse, error) {
-	path := "/api/sys/v1/issuer/search"
-	headers := map[string]string{}
-	if c.Token != "" {
-		headers["token"] = c.Token
-	}
-	respBytes, _, err := c.Post(path, req, headers)
-	if err != nil {
-		return nil, err
-	}
+	return c.QueryIssuerWithContext(context.Background(), req)
+}
+
+// QueryIssuerWithContext 查询发证方，ctx用于控制请求的取消和超时
+func (c *Client) QueryIssuerWithContext(ctx context.Context, req *QueryIssuerRequest) (*QueryIssuerResponse, error) {
 	var resp QueryIssuerResponse
-	if err := json.Unmarshal(respBytes, &resp); err != nil {
+	if err := c.postJSON(ctx, "/api/sys/v1/issuer/search", req, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -42,17 +34,13 @@ func (c *Client) QueryIssuer(req *QueryIssuerRequest) (*QueryIssuerResponse, err
 
 // UpdateIssuer 更新发证方
 func (c *Client) UpdateIssuer(req *UpdateIssuerRequest) (*UpdateIssuerResponse, error) {
-	path := "/api/sys/v1/issuer/update"
-	headers := map[string]string{}
-	if c.Token != "" {
-		headers["token"] = c.Token
-	}
-	respBytes, _, err := c.Post(path, req, headers)
-	if err != nil {
-		return nil, err
-	}
+	return c.UpdateIssuerWithContext(context.Background(), req)
+}
+
+// UpdateIssuerWithContext 更新发证方，ctx用于控制请求的取消和超时
+func (c *Client) UpdateIssuerWithContext(ctx context.Context, req *UpdateIssuerRequest) (*UpdateIssuerResponse, error) {
 	var resp UpdateIssuerResponse
-	if err := json.Unmarshal(respBytes, &resp); err != nil {
+	if err := c.postJSON(ctx, "/api/sys/v1/issuer/update", req, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -60,17 +48,13 @@ func (c *Client) UpdateIssuer(req *UpdateIssuerRequest) (*UpdateIssuerResponse,
 
 // IssuerStatus 启用/禁用发证方
 func (c *Client) IssuerStatus(req *IssuerStatusRequest) (*IssuerStatusResponse, error) {
-	path := "/api/sys/v1/issuer/status/update"
-	headers := map[string]string{}
-	if c.Token != "" {
-		headers["token"] = c.Token
-	}
-	respBytes, _, err := c.Post(path, req, headers)
-	if err != nil {
-		return nil, err
-	}
+	return c.IssuerStatusWithContext(context.Background(), req)
+}
+
+// IssuerStatusWithContext 启用/禁用发证方，ctx用于控制请求的取消和超时
+func (c *Client) IssuerStatusWithContext(ctx context.Context, req *IssuerStatusRequest) (*IssuerStatusResponse, error) {
 	var resp IssuerStatusResponse
-	if err := json.Unmarshal(respBytes, &resp); err != nil {
+	if err := c.postJSON(ctx, "/api/sys/v1/issuer/status/update", req, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
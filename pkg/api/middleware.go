@@ -0,0 +1,233 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SDK标识，用于User-Agent和版本请求头
+const (
+	SDKName          = "sbp-did-sdk-go"
+	SDKVersion       = "0.1.0"
+	SDKVersionHeader = "X-SDK-Version"
+)
+
+// Middleware 请求中间件
+// 每次HTTP请求（含重试）都会依次调用各中间件的钩子，未设置的钩子会被跳过
+type Middleware struct {
+	// BeforeRequest 请求发送前调用，可修改请求头（如注入Header、请求签名），返回错误时中止请求
+	BeforeRequest func(req *http.Request) error
+	// AfterResponse 收到HTTP响应后调用，body为已读取的响应体
+	AfterResponse func(req *http.Request, resp *http.Response, body []byte, elapsed time.Duration)
+	// OnError 请求失败时调用，包括网络错误和HTTP状态码>=400
+	OnError func(req *http.Request, err error, elapsed time.Duration)
+}
+
+// Use 追加中间件，按添加顺序执行
+func (c *Client) Use(mws ...Middleware) {
+	c.Middlewares = append(c.Middlewares, mws...)
+}
+
+// beforeRequest 依次执行BeforeRequest钩子
+func (c *Client) beforeRequest(req *http.Request) error {
+	for _, mw := range c.Middlewares {
+		if mw.BeforeRequest == nil {
+			continue
+		}
+		if err := mw.BeforeRequest(req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// afterResponse 依次执行AfterResponse钩子
+func (c *Client) afterResponse(req *http.Request, resp *http.Response, body []byte, elapsed time.Duration) {
+	for _, mw := range c.Middlewares {
+		if mw.AfterResponse != nil {
+			mw.AfterResponse(req, resp, body, elapsed)
+		}
+	}
+}
+
+// onError 依次执行OnError钩子
+func (c *Client) onError(req *http.Request, err error, elapsed time.Duration) {
+	for _, mw := range c.Middlewares {
+		if mw.OnError != nil {
+			mw.OnError(req, err, elapsed)
+		}
+	}
+}
+
+// UserAgentMiddleware 设置User-Agent和SDK版本请求头
+// userAgent为空时使用"sbp-did-sdk-go/<版本>"
+func UserAgentMiddleware(userAgent string) Middleware {
+	if userAgent == "" {
+		userAgent = SDKName + "/" + SDKVersion
+	}
+	return Middleware{
+		BeforeRequest: func(req *http.Request) error {
+			req.Header.Set("User-Agent", userAgent)
+			req.Header.Set(SDKVersionHeader, SDKVersion)
+			return nil
+		},
+	}
+}
+
+// HeaderMiddleware 为每个请求注入固定请求头
+func HeaderMiddleware(headers map[string]string) Middleware {
+	return Middleware{
+		BeforeRequest: func(req *http.Request) error {
+			for k, v := range headers {
+				req.Header.Set(k, v)
+			}
+			return nil
+		},
+	}
+}
+
+// 日志中需要脱敏的请求头
+var sensitiveHeaders = []string{"Authorization", "Token"}
+
+// LoggingMiddleware 以key=value形式记录每次请求，token等敏感请求头会被脱敏
+// logger为nil时使用log标准库默认logger
+func LoggingMiddleware(logger *log.Logger) Middleware {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return Middleware{
+		AfterResponse: func(req *http.Request, resp *http.Response, body []byte, elapsed time.Duration) {
+			// HTTP错误由OnError记录
+			if resp.StatusCode >= 400 {
+				return
+			}
+			logger.Printf("openapi request method=%s path=%s status=%d latency_ms=%d headers=%s",
+				req.Method, req.URL.Path, resp.StatusCode, elapsed.Milliseconds(), formatHeaders(req.Header))
+		},
+		OnError: func(req *http.Request, err error, elapsed time.Duration) {
+			logger.Printf("openapi error method=%s path=%s latency_ms=%d headers=%s error=%q",
+				req.Method, req.URL.Path, elapsed.Milliseconds(), formatHeaders(req.Header), err.Error())
+		},
+	}
+}
+
+// formatHeaders 格式化请求头并对敏感值脱敏
+func formatHeaders(h http.Header) string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		v := strings.Join(h.Values(k), ",")
+		for _, s := range sensitiveHeaders {
+			if strings.EqualFold(k, s) {
+				v = redact(v)
+			}
+		}
+		parts = append(parts, fmt.Sprintf("%s:%s", k, v))
+	}
+	return "{" + strings.Join(parts, " ") + "}"
+}
+
+// redact 脱敏，仅保留末4位
+func redact(v string) string {
+	if len(v) <= 4 {
+		return "****"
+	}
+	return "****" + v[len(v)-4:]
+}
+
+// DefaultLatencyBuckets 默认延迟分桶上限
+var DefaultLatencyBuckets = []time.Duration{
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// LatencyHistogram 按接口路径统计请求延迟分布，并发安全
+type LatencyHistogram struct {
+	buckets []time.Duration
+
+	mu    sync.Mutex
+	stats map[string]*LatencyStats
+}
+
+// LatencyStats 单个接口路径的延迟统计
+type LatencyStats struct {
+	Count   int64           // 请求总数
+	Errors  int64           // 失败次数
+	Sum     time.Duration   // 总耗时
+	Buckets []time.Duration // 分桶上限
+	Counts  []int64         // 各分桶计数（非累计），最后一个为超出最大分桶的计数
+}
+
+// NewLatencyHistogram 创建延迟直方图，buckets为空时使用DefaultLatencyBuckets
+func NewLatencyHistogram(buckets []time.Duration) *LatencyHistogram {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	sorted := append([]time.Duration(nil), buckets...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return &LatencyHistogram{
+		buckets: sorted,
+		stats:   make(map[string]*LatencyStats),
+	}
+}
+
+// Observe 记录一次请求耗时
+func (h *LatencyHistogram) Observe(path string, elapsed time.Duration, failed bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	st, ok := h.stats[path]
+	if !ok {
+		st = &LatencyStats{Buckets: h.buckets, Counts: make([]int64, len(h.buckets)+1)}
+		h.stats[path] = st
+	}
+	st.Count++
+	st.Sum += elapsed
+	if failed {
+		st.Errors++
+	}
+	i := sort.Search(len(h.buckets), func(i int) bool { return elapsed <= h.buckets[i] })
+	st.Counts[i]++
+}
+
+// Snapshot 返回当前统计数据的副本，key为接口路径
+func (h *LatencyHistogram) Snapshot() map[string]LatencyStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make(map[string]LatencyStats, len(h.stats))
+	for path, st := range h.stats {
+		cp := *st
+		cp.Counts = append([]int64(nil), st.Counts...)
+		out[path] = cp
+	}
+	return out
+}
+
+// MetricsMiddleware 将每次请求的延迟记录到直方图
+func MetricsMiddleware(h *LatencyHistogram) Middleware {
+	return Middleware{
+		AfterResponse: func(req *http.Request, resp *http.Response, body []byte, elapsed time.Duration) {
+			if resp.StatusCode < 400 {
+				h.Observe(req.URL.Path, elapsed, false)
+			}
+		},
+		OnError: func(req *http.Request, err error, elapsed time.Duration) {
+			h.Observe(req.URL.Path, elapsed, true)
+		},
+	}
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/helailiang/sbp-did-sdk-go/pkg/config"
+)
+
+// DefaultTimeout 默认请求超时时间
+const DefaultTimeout = 10 * time.Second
+
+// ClientOption NewClient的可选配置
+type ClientOption func(*clientOptions)
+
+// clientOptions 构建HTTP客户端所需的传输配置
+type clientOptions struct {
+	timeout    time.Duration
+	tlsConfig  *tls.Config
+	proxy      func(*http.Request) (*url.URL, error)
+	httpClient *http.Client
+}
+
+// tls 返回可修改的TLS配置
+func (o *clientOptions) tls() *tls.Config {
+	if o.tlsConfig == nil {
+		o.tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return o.tlsConfig
+}
+
+// WithTimeout 设置单次HTTP请求的超时时间，<=0表示不限制
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithTLSConfig 使用自定义TLS配置，会被复制后使用
+// 与WithClientCertificate、WithRootCAs同时使用时，后者在此配置基础上追加
+func WithTLSConfig(cfg *tls.Config) ClientOption {
+	return func(o *clientOptions) {
+		if cfg == nil {
+			o.tlsConfig = nil
+			return
+		}
+		clone := cfg.Clone()
+		if o.tlsConfig != nil {
+			clone.Certificates = append(clone.Certificates, o.tlsConfig.Certificates...)
+			if clone.RootCAs == nil {
+				clone.RootCAs = o.tlsConfig.RootCAs
+			}
+		}
+		o.tlsConfig = clone
+	}
+}
+
+// WithClientCertificate 设置mTLS客户端证书
+func WithClientCertificate(cert tls.Certificate) ClientOption {
+	return func(o *clientOptions) {
+		cfg := o.tls()
+		cfg.Certificates = append(cfg.Certificates, cert)
+	}
+}
+
+// WithRootCAs 设置校验服务端证书的CA证书池，用于私有CA签发的网关证书
+func WithRootCAs(pool *x509.CertPool) ClientOption {
+	return func(o *clientOptions) {
+		o.tls().RootCAs = pool
+	}
+}
+
+// WithProxy 通过指定的HTTP/HTTPS代理访问OpenAPI
+// proxyURL为nil时不使用代理（包括环境变量中的代理）
+func WithProxy(proxyURL *url.URL) ClientOption {
+	return func(o *clientOptions) {
+		if proxyURL == nil {
+			o.proxy = func(*http.Request) (*url.URL, error) { return nil, nil }
+			return
+		}
+		o.proxy = http.ProxyURL(proxyURL)
+	}
+}
+
+// WithHTTPClient 使用调用方提供的http.Client，忽略其他传输配置
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(o *clientOptions) {
+		o.httpClient = httpClient
+	}
+}
+
+// buildHTTPClient 根据配置构建http.Client
+// 未设置TLS和代理时使用默认Transport
+func (o *clientOptions) buildHTTPClient() *http.Client {
+	if o.httpClient != nil {
+		return o.httpClient
+	}
+	hc := &http.Client{Timeout: o.timeout}
+	if o.tlsConfig == nil && o.proxy == nil {
+		return hc
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if o.tlsConfig != nil {
+		transport.TLSClientConfig = o.tlsConfig
+	}
+	if o.proxy != nil {
+		transport.Proxy = o.proxy
+	}
+	hc.Transport = transport
+	return hc
+}
+
+// OptionsFromConfig 根据配置中的超时、TLS和代理字段生成ClientOption
+// 证书文件在此时读取，文件不存在或格式错误时返回错误
+func OptionsFromConfig(cfg *config.Config) ([]ClientOption, error) {
+	var opts []ClientOption
+	if cfg.TimeoutSeconds > 0 {
+		opts = append(opts, WithTimeout(time.Duration(cfg.TimeoutSeconds)*time.Second))
+	}
+	if cfg.TLSServerName != "" || cfg.TLSInsecureSkipVerify {
+		opts = append(opts, WithTLSConfig(&tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			ServerName:         cfg.TLSServerName,
+			InsecureSkipVerify: cfg.TLSInsecureSkipVerify,
+		}))
+	}
+	if cfg.TLSCertFile != "" || cfg.TLSKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		opts = append(opts, WithClientCertificate(cert))
+	}
+	if cfg.TLSCAFile != "" {
+		pem, err := ioutil.ReadFile(cfg.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid certificates found in CA file %s", cfg.TLSCAFile)
+		}
+		opts = append(opts, WithRootCAs(pool))
+	}
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url: %w", err)
+		}
+		opts = append(opts, WithProxy(proxyURL))
+	}
+	return opts, nil
+}
+
+// NewClientFromConfig 根据配置创建OpenAPI客户端
+// 使用OpenAPIEndpoint、Token和ProjectID（自动填充请求的ProjectNo），私有项目必须配置Token；
+// 超时、TLS和代理配置通过OptionsFromConfig生效，opts在其后应用
+func NewClientFromConfig(cfg *config.Config, opts ...ClientOption) (*Client, error) {
+	if cfg == nil {
+		return nil, errors.New("config is nil")
+	}
+	if cfg.OpenAPIEndpoint == "" {
+		return nil, errors.New("OpenAPIEndpoint is required")
+	}
+	if cfg.ProjectVisibility != "" && cfg.ProjectVisibility != "public" && cfg.ProjectVisibility != "private" {
+		return nil, fmt.Errorf("ProjectVisibility must be 'public' or 'private', got: %s", cfg.ProjectVisibility)
+	}
+	token, err := cfg.GetToken()
+	if err != nil {
+		return nil, err
+	}
+	cfgOpts, err := OptionsFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	client := NewClient(strings.TrimRight(cfg.OpenAPIEndpoint, "/"), token, append(cfgOpts, opts...)...)
+	client.ProjectNO = cfg.ProjectID
+	return client, nil
+}
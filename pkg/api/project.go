@@ -0,0 +1,33 @@
+package api
+
+import (
+	"context"
+)
+
+// GetToken 获取项目访问Token
+func (c *Client) GetToken(req *GetTokenRequest) (*GetTokenResponse, error) {
+	return c.GetTokenWithContext(context.Background(), req)
+}
+
+// GetTokenWithContext 获取项目访问Token，ctx用于控制请求的取消和超时
+func (c *Client) GetTokenWithContext(ctx context.Context, req *GetTokenRequest) (*GetTokenResponse, error) {
+	var resp GetTokenResponse
+	if err := c.postJSON(ctx, "/api/sys/v1/project/token", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ProjectEnable 启用/禁用项目
+func (c *Client) ProjectEnable(req *ProjectEnableRequest) (*ProjectEnableResponse, error) {
+	return c.ProjectEnableWithContext(context.Background(), req)
+}
+
+// ProjectEnableWithContext 启用/禁用项目，ctx用于控制请求的取消和超时
+func (c *Client) ProjectEnableWithContext(ctx context.Context, req *ProjectEnableRequest) (*ProjectEnableResponse, error) {
+	var resp ProjectEnableResponse
+	if err := c.postJSON(ctx, "/api/sys/v1/project/status/update", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
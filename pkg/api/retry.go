@@ -0,0 +1,119 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// IdempotencyKeyHeader 幂等键请求头
+// 同一次逻辑调用的所有重试共用一个幂等键，服务端据此避免重复上链
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// RetryPolicy 请求重试策略
+// 网络错误（如连接被重置）和RetryStatusCodes中的HTTP状态码会触发重试，
+// 业务码错误和ctx取消不会重试
+type RetryPolicy struct {
+	MaxAttempts      int           // 最大尝试次数（含首次），<=1表示不重试
+	InitialBackoff   time.Duration // 首次重试前的等待时间
+	MaxBackoff       time.Duration // 单次等待时间上限
+	Multiplier       float64       // 指数退避倍数
+	Jitter           float64       // 随机抖动比例，取值0~1
+	RetryStatusCodes []int         // 触发重试的HTTP状态码
+}
+
+// DefaultRetryPolicy 默认重试策略：最多3次，200ms起指数退避，对429和5xx网关类错误重试
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		RetryStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// attempts 返回最大尝试次数
+func (p *RetryPolicy) attempts() int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// shouldRetry 判断一次请求失败后是否需要重试
+func (p *RetryPolicy) shouldRetry(ctx context.Context, statusCode int, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err == nil {
+		return false
+	}
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		for _, code := range p.RetryStatusCodes {
+			if apiErr.StatusCode == code {
+				return true
+			}
+		}
+		return false
+	}
+	// 没有拿到HTTP响应，视为网络错误
+	return statusCode == 0
+}
+
+// backoff 计算第attempt次重试前的等待时间（attempt从1开始）
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	d := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		d = d * (1 - jitter + 2*jitter*rand.Float64())
+	}
+	return time.Duration(d)
+}
+
+// sleep 等待d，ctx被取消时提前返回
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type idempotencyKeyCtxKey struct{}
+
+// WithIdempotencyKey 为本次调用指定幂等键，未指定时客户端自动生成
+// 跨进程重试同一笔上链操作时应复用同一个幂等键
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyCtxKey{}, key)
+}
+
+// idempotencyKeyFromContext 获取ctx中指定的幂等键
+func idempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyCtxKey{}).(string)
+	return key
+}
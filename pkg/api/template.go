@@ -1,22 +1,18 @@
 package api
 
 import (
-	"encoding/json"
+	"context"
 )
 
 // RegisterVCTemplate 注册VC模板
 func (c *Client) RegisterVCTemplate(req *RegisterVCTemplateRequest) (*CommonResponse, error) {
-	path := "/api/sys/v1/vc/register"
-	headers := map[string]string{}
-	if c.Token != "" {
-		headers["token"] = c.Token
-	}
-	respBytes, _, err := c.Post(path, req, headers)
-	if err != nil {
-		return nil, err
-	}
+	return c.RegisterVCTemplateWithContext(context.Background(), req)
+}
+
+// RegisterVCTemplateWithContext 注册VC模板，ctx用于控制请求的取消和超时
+func (c *Client) RegisterVCTemplateWithContext(ctx context.Context, req *RegisterVCTemplateRequest) (*CommonResponse, error) {
 	var resp CommonResponse
-	if err := json.Unmarshal(respBytes, &resp); err != nil {
+	if err := c.postJSON(ctx, "/api/sys/v1/vc/register", req, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -24,17 +20,41 @@ func (c *Client) RegisterVCTemplate(req *RegisterVCTemplateRequest) (*CommonResp
 
 // QueryVCTemplate 查询VC模板
 func (c *Client) QueryVCTemplate(req *QueryVCTemplateRequest) (*QueryVCTemplateResponse, error) {
-	path := "/api/sys/v1/vc/search"
-	headers := map[string]string{}
-	if c.Token != "" {
-		headers["token"] = c.Token
+	return c.QueryVCTemplateWithContext(context.Background(), req)
+}
+
+// QueryVCTemplateWithContext 查询VC模板，ctx用于控制请求的取消和超时
+func (c *Client) QueryVCTemplateWithContext(ctx context.Context, req *QueryVCTemplateRequest) (*QueryVCTemplateResponse, error) {
+	var resp QueryVCTemplateResponse
+	if err := c.postJSON(ctx, "/api/sys/v1/vc/search", req, &resp); err != nil {
+		return nil, err
 	}
-	respBytes, _, err := c.Post(path, req, headers)
-	if err != nil {
+	return &resp, nil
+}
+
+// UpdateVCTemplate 更新VC模板
+func (c *Client) UpdateVCTemplate(req *UpdateVCTemplateRequest) (*UpdateVCTemplateResponse, error) {
+	return c.UpdateVCTemplateWithContext(context.Background(), req)
+}
+
+// UpdateVCTemplateWithContext 更新VC模板，ctx用于控制请求的取消和超时
+func (c *Client) UpdateVCTemplateWithContext(ctx context.Context, req *UpdateVCTemplateRequest) (*UpdateVCTemplateResponse, error) {
+	var resp UpdateVCTemplateResponse
+	if err := c.postJSON(ctx, "/api/sys/v1/vc/update", req, &resp); err != nil {
 		return nil, err
 	}
-	var resp QueryVCTemplateResponse
-	if err := json.Unmarshal(respBytes, &resp); err != nil {
+	return &resp, nil
+}
+
+// VCTemplateStatus 启用/禁用VC模板
+func (c *Client) VCTemplateStatus(req *VCTemplateStatusRequest) (*VCTemplateStatusResponse, error) {
+	return c.VCTemplateStatusWithContext(context.Background(), req)
+}
+
+// VCTemplateStatusWithContext 启用/禁用VC模板，ctx用于控制请求的取消和超时
+func (c *Client) VCTemplateStatusWithContext(ctx context.Context, req *VCTemplateStatusRequest) (*VCTemplateStatusResponse, error) {
+	var resp VCTemplateStatusResponse
+	if err := c.postJSON(ctx, "/api/sys/v1/vc/status/update", req, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenProvider 为私有项目提供访问Token
+// Client设置TokenProvider后，每次请求从中获取Token，收到401时调用Invalidate并重试一次
+type TokenProvider interface {
+	// Token 返回当前有效的Token
+	Token(ctx context.Context) (string, error)
+	// Invalidate 标记token已失效，下次获取时重新申请
+	Invalidate(token string)
+}
+
+// ProjectTokenProvider 通过项目管理接口（GetToken）获取并缓存Token
+// 缓存的Token临近过期时主动刷新；并发安全
+type ProjectTokenProvider struct {
+	// TTL Token有效期，Token不是JWT或不含exp时使用，默认30分钟
+	TTL time.Duration
+	// RefreshBefore 在过期前多久主动刷新，默认1分钟
+	RefreshBefore time.Duration
+
+	client  *Client
+	request GetTokenRequest
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewProjectTokenProvider 创建项目Token提供者
+// client用于调用Token接口，req为申请Token的参数
+func NewProjectTokenProvider(client *Client, req *GetTokenRequest) *ProjectTokenProvider {
+	// 使用独立的客户端副本申请Token，避免与client.TokenProvider相互递归
+	tokenClient := *client
+	tokenClient.TokenProvider = nil
+	return &ProjectTokenProvider{
+		TTL:           30 * time.Minute,
+		RefreshBefore: time.Minute,
+		client:        &tokenClient,
+		request:       *req,
+	}
+}
+
+// Token 返回缓存的Token，缓存为空或即将过期时重新申请
+func (p *ProjectTokenProvider) Token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.token != "" && time.Now().Before(p.expiresAt.Add(-p.RefreshBefore)) {
+		return p.token, nil
+	}
+	resp, err := p.client.GetTokenWithContext(ctx, &p.request)
+	if err != nil {
+		return "", err
+	}
+	if resp.Data.Token == "" {
+		return "", errors.New("openapi: empty token in GetToken response")
+	}
+	p.token = resp.Data.Token
+	p.expiresAt = p.expiry(p.token)
+	return p.token, nil
+}
+
+// Invalidate 清除缓存的token；缓存已被其他调用刷新时不做处理
+func (p *ProjectTokenProvider) Invalidate(token string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.token == token {
+		p.token = ""
+	}
+}
+
+// expiry 计算Token过期时间，JWT优先使用exp声明
+func (p *ProjectTokenProvider) expiry(token string) time.Time {
+	if exp, ok := jwtExpiry(token); ok {
+		return exp
+	}
+	return time.Now().Add(p.TTL)
+}
+
+// jwtExpiry 解析JWT格式Token的exp声明
+func jwtExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.Exp, 0), true
+}
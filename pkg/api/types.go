@@ -1,5 +1,9 @@
 package api
 
+import (
+	"github.com/helailiang/sbp-did-sdk-go/pkg/wallet"
+)
+
 // 通用响应结构
 // code: "0" 表示成功
 // data: 具体数据
@@ -258,6 +262,39 @@ type VCVerifyResponse struct {
 	Message string `json:"message"`
 }
 
+// ========== VP ========== //
+// VP结构体复用wallet.VerifiablePresentation（W3C标准）
+// https://www.w3.org/TR/vc-data-model/#presentations-0
+type GenerateVPRequest struct {
+	ProjectNo                     string `json:"projectNo"`
+	wallet.VerifiablePresentation `json:"vp"`
+	Challenge                     string `json:"challenge,omitempty"`
+	Domain                        string `json:"domain,omitempty"`
+}
+
+type GenerateVPResponse struct {
+	Code string `json:"code"`
+	Data struct {
+		wallet.VerifiablePresentation
+	} `json:"data"`
+	Message string `json:"message"`
+}
+
+type VerifyVPRequest struct {
+	ProjectNo                     string `json:"projectNo"`
+	wallet.VerifiablePresentation `json:"vp"`
+	Challenge                     string `json:"challenge,omitempty"`
+	Domain                        string `json:"domain,omitempty"`
+}
+
+type VerifyVPResponse struct {
+	Code string `json:"code"`
+	Data struct {
+		VerificationStatus bool `json:"verificationStatus"`
+	} `json:"data"`
+	Message string `json:"message"`
+}
+
 // ========== 项目管理 ========== //
 type GetTokenRequest struct {
 	ProjectNo      string `json:"projectNo"`
@@ -1,40 +1,42 @@
 package api
 
 import (
-	"encoding/json"
+	"context"
 )
 
 // QueryVCEvidence 查询VC存证
 func (c *Client) QueryVCEvidence(req *QueryVCEvidenceRequest) (*QueryVCEvidenceResponse, error) {
-	path := "/api/sys/v1/vc/evidence/search"
-	headers := map[string]string{}
-	if c.Token != "" {
-		headers["token"] = c.Token
-	}
-	respBytes, _, err := c.Post(path, req, headers)
-	if err != nil {
-		return nil, err
-	}
+	return c.QueryVCEvidenceWithContext(context.Background(), req)
+}
+
+// QueryVCEvidenceWithContext 查询VC存证，ctx用于控制请求的取消和超时
+func (c *Client) QueryVCEvidenceWithContext(ctx context.Context, req *QueryVCEvidenceRequest) (*QueryVCEvidenceResponse, error) {
 	var resp QueryVCEvidenceResponse
-	if err := json.Unmarshal(respBytes, &resp); err != nil {
+	if err := c.postJSON(ctx, "/api/sys/v1/vc/evidence/search", req, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
 }
 
+// SearchVCEvidence QueryVCEvidence的别名，VCEvidenceSearchRequest与QueryVCEvidenceRequest字段相同
+func (c *Client) SearchVCEvidence(req *VCEvidenceSearchRequest) (*VCEvidenceSearchResponse, error) {
+	return c.SearchVCEvidenceWithContext(context.Background(), req)
+}
+
+// SearchVCEvidenceWithContext QueryVCEvidenceWithContext的别名
+func (c *Client) SearchVCEvidenceWithContext(ctx context.Context, req *VCEvidenceSearchRequest) (*VCEvidenceSearchResponse, error) {
+	return c.QueryVCEvidenceWithContext(ctx, (*QueryVCEvidenceRequest)(req))
+}
+
 // IssueVC 签发VC
 func (c *Client) IssueVC(req *IssueVCRequest) (*IssueVCResponse, error) {
-	path := "/api/sys/v1/vc/issue"
-	headers := map[string]string{}
-	if c.Token != "" {
-		headers["token"] = c.Token
-	}
-	respBytes, _, err := c.Post(path, req, headers)
-	if err != nil {
-		return nil, err
-	}
+	return c.IssueVCWithContext(context.Background(), req)
+}
+
+// IssueVCWithContext 签发VC，ctx用于控制请求的取消和超时
+func (c *Client) IssueVCWithContext(ctx context.Context, req *IssueVCRequest) (*IssueVCResponse, error) {
 	var resp IssueVCResponse
-	if err := json.Unmarshal(respBytes, &resp); err != nil {
+	if err := c.postJSON(ctx, "/api/sys/v1/vc/issue", req, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -42,17 +44,13 @@ func (c *Client) IssueVC(req *IssueVCRequest) (*IssueVCResponse, error) {
 
 // VCEvidence VC存证
 func (c *Client) VCEvidence(req *VCEvidenceRequest) (*VCEvidenceResponse, error) {
-	path := "/api/sys/v1/vc/evidence"
-	headers := map[string]string{}
-	if c.Token != "" {
-		headers["token"] = c.Token
-	}
-	respBytes, _, err := c.Post(path, req, headers)
-	if err != nil {
-		return nil, err
-	}
+	return c.VCEvidenceWithContext(context.Background(), req)
+}
+
+// VCEvidenceWithContext VC存证，ctx用于控制请求的取消和超时
+func (c *Client) VCEvidenceWithContext(ctx context.Context, req *VCEvidenceRequest) (*VCEvidenceResponse, error) {
 	var resp VCEvidenceResponse
-	if err := json.Unmarshal(respBytes, &resp); err != nil {
+	if err := c.postJSON(ctx, "/api/sys/v1/vc/evidence", req, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -60,17 +58,13 @@ func (c *Client) VCEvidence(req *VCEvidenceRequest) (*VCEvidenceResponse, error)
 
 // VCRevoke 吊销VC
 func (c *Client) VCRevoke(req *VCRevokeRequest) (*VCRevokeResponse, error) {
-	path := "/api/sys/v1/vc/revoke"
-	headers := map[string]string{}
-	if c.Token != "" {
-		headers["token"] = c.Token
-	}
-	respBytes, _, err := c.Post(path, req, headers)
-	if err != nil {
-		return nil, err
-	}
+	return c.VCRevokeWithContext(context.Background(), req)
+}
+
+// VCRevokeWithContext 吊销VC，ctx用于控制请求的取消和超时
+func (c *Client) VCRevokeWithContext(ctx context.Context, req *VCRevokeRequest) (*VCRevokeResponse, error) {
 	var resp VCRevokeResponse
-	if err := json.Unmarshal(respBytes, &resp); err != nil {
+	if err := c.postJSON(ctx, "/api/sys/v1/vc/revoke", req, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -78,17 +72,13 @@ func (c *Client) VCRevoke(req *VCRevokeRequest) (*VCRevokeResponse, error) {
 
 // VCRevokeStatus 查询VC吊销状态
 func (c *Client) VCRevokeStatus(req *VCRevokeStatusRequest) (*VCRevokeStatusResponse, error) {
-	path := "/api/sys/v1/vc/status/search"
-	headers := map[string]string{}
-	if c.Token != "" {
-		headers["token"] = c.Token
-	}
-	respBytes, _, err := c.Post(path, req, headers)
-	if err != nil {
-		return nil, err
-	}
+	return c.VCRevokeStatusWithContext(context.Background(), req)
+}
+
+// VCRevokeStatusWithContext 查询VC吊销状态，ctx用于控制请求的取消和超时
+func (c *Client) VCRevokeStatusWithContext(ctx context.Context, req *VCRevokeStatusRequest) (*VCRevokeStatusResponse, error) {
 	var resp VCRevokeStatusResponse
-	if err := json.Unmarshal(respBytes, &resp); err != nil {
+	if err := c.postJSON(ctx, "/api/sys/v1/vc/status/search", req, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -96,17 +86,13 @@ func (c *Client) VCRevokeStatus(req *VCRevokeStatusRequest) (*VCRevokeStatusResp
 
 // VCVerify 核验VC
 func (c *Client) VCVerify(req *VCVerifyRequest) (*VCVerifyResponse, error) {
-	path := "/api/sys/v1/vc/verify"
-	headers := map[string]string{}
-	if c.Token != "" {
-		headers["token"] = c.Token
-	}
-	respBytes, _, err := c.Post(path, req, headers)
-	if err != nil {
-		return nil, err
-	}
+	return c.VCVerifyWithContext(context.Background(), req)
+}
+
+// VCVerifyWithContext 核验VC，ctx用于控制请求的取消和超时
+func (c *Client) VCVerifyWithContext(ctx context.Context, req *VCVerifyRequest) (*VCVerifyResponse, error) {
 	var resp VCVerifyResponse
-	if err := json.Unmarshal(respBytes, &resp); err != nil {
+	if err := c.postJSON(ctx, "/api/sys/v1/vc/verify", req, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
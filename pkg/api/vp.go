@@ -1,19 +1,33 @@
 package api
 
 import (
-	"fmt"
+	"context"
 )
 
 // GenerateVP 生成VP
-func (c *Client) GenerateVP(projectID string, vpReq interface{}) ([]byte, error) {
-	path := fmt.Sprintf("/v1/vp/%s/generate", projectID)
-	resp, _, err := c.Post(path, vpReq, nil)
-	return resp, err
+func (c *Client) GenerateVP(req *GenerateVPRequest) (*GenerateVPResponse, error) {
+	return c.GenerateVPWithContext(context.Background(), req)
+}
+
+// GenerateVPWithContext 生成VP，ctx用于控制请求的取消和超时
+func (c *Client) GenerateVPWithContext(ctx context.Context, req *GenerateVPRequest) (*GenerateVPResponse, error) {
+	var resp GenerateVPResponse
+	if err := c.postJSON(ctx, "/api/sys/v1/vp/generate", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
 }
 
 // VerifyVP 验证VP
-func (c *Client) VerifyVP(projectID string, verifyReq interface{}) ([]byte, error) {
-	path := fmt.Sprintf("/v1/vp/%s/verify", projectID)
-	resp, _, err := c.Post(path, verifyReq, nil)
-	return resp, err
+func (c *Client) VerifyVP(req *VerifyVPRequest) (*VerifyVPResponse, error) {
+	return c.VerifyVPWithContext(context.Background(), req)
+}
+
+// VerifyVPWithContext 验证VP，ctx用于控制请求的取消和超时
+func (c *Client) VerifyVPWithContext(ctx context.Context, req *VerifyVPRequest) (*VerifyVPResponse, error) {
+	var resp VerifyVPResponse
+	if err := c.postJSON(ctx, "/api/sys/v1/vp/verify", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
 }
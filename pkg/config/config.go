@@ -3,6 +3,7 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"strings"
 )
 
@@ -25,8 +26,17 @@ type Config struct {
 	ProjectVisibility string `json:"project_visibility" yaml:"project_visibility"` // public 或 private
 	Token           string `json:"token" yaml:"token"` // 私有项目需要提供Token
 	
+	// OpenAPI传输配置（可选）
+	TimeoutSeconds        int    `json:"timeout_seconds" yaml:"timeout_seconds"`                 // 请求超时秒数，0表示使用默认值
+	TLSCertFile           string `json:"tls_cert_file" yaml:"tls_cert_file"`                     // mTLS客户端证书（PEM）
+	TLSKeyFile            string `json:"tls_key_file" yaml:"tls_key_file"`                       // mTLS客户端私钥（PEM）
+	TLSCAFile             string `json:"tls_ca_file" yaml:"tls_ca_file"`                         // 私有CA证书（PEM）
+	TLSServerName         string `json:"tls_server_name" yaml:"tls_server_name"`                 // 覆盖校验的服务端证书名称
+	TLSInsecureSkipVerify bool   `json:"tls_insecure_skip_verify" yaml:"tls_insecure_skip_verify"` // 跳过服务端证书校验，仅用于测试
+	ProxyURL              string `json:"proxy_url" yaml:"proxy_url"`                             // HTTP/HTTPS代理地址
+	
 	// 算法配置
-	DefaultAlgorithm string `json:"default_algorithm" yaml:"default_algorithm"` // ECDSA, RSA, SM2
+	DefaultAlgorithm string `json:"default_algorithm" yaml:"default_algorithm"` // SECP256K1, P256, P384, RSA, SM2, ED25519（ECDSA等同于SECP256K1）
 	DefaultHashAlgorithm string `json:"default_hash_algorithm" yaml:"default_hash_algorithm"` // SHA256, SM3
 	
 	// 日志配置
@@ -77,9 +87,22 @@ func (c *Config) Validate() error {
 		errors = append(errors, "Token is required for private projects")
 	}
 	
+	// 验证传输配置
+	if c.TimeoutSeconds < 0 {
+		errors = append(errors, "TimeoutSeconds must not be negative")
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		errors = append(errors, "TLSCertFile and TLSKeyFile must be set together")
+	}
+	if c.ProxyURL != "" {
+		if u, err := url.Parse(c.ProxyURL); err != nil || u.Scheme == "" || u.Host == "" {
+			errors = append(errors, fmt.Sprintf("ProxyURL is invalid: %s", c.ProxyURL))
+		}
+	}
+	
 	// 验证算法配置
 	if !isValidAlgorithm(c.DefaultAlgorithm) {
-		errors = append(errors, fmt.Sprintf("DefaultAlgorithm must be one of: ECDSA, RSA, SM2, got: %s", c.DefaultAlgorithm))
+		errors = append(errors, fmt.Sprintf("DefaultAlgorithm must be one of: SECP256K1, P256, P384, ECDSA, RSA, SM2, ED25519, got: %s", c.DefaultAlgorithm))
 	}
 	
 	if !isValidHashAlgorithm(c.DefaultHashAlgorithm) {
@@ -95,7 +118,7 @@ func (c *Config) Validate() error {
 
 // isValidAlgorithm 检查算法是否有效
 func isValidAlgorithm(algorithm string) bool {
-	validAlgorithms := []string{"ECDSA", "RSA", "SM2"}
+	validAlgorithms := []string{"ECDSA", "SECP256K1", "P256", "P384", "RSA", "SM2", "ED25519"}
 	for _, valid := range validAlgorithms {
 		if algorithm == valid {
 			return true
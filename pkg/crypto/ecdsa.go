@@ -0,0 +1,230 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+)
+
+// 椭圆曲线签名算法标识
+// "ECDSA" 为历史标识，等同于 SECP256K1
+const (
+	AlgorithmECDSA     = "ECDSA"
+	AlgorithmSecp256k1 = "SECP256K1"
+	AlgorithmP256      = "P256"
+	AlgorithmP384      = "P384"
+)
+
+// oidNamedCurveSecp256k1 secp256k1曲线
+var oidNamedCurveSecp256k1 = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+
+// NormalizeECAlgorithm 将历史标识 "ECDSA" 映射为 SECP256K1，其余原样返回
+func NormalizeECAlgorithm(algorithm string) string {
+	if algorithm == AlgorithmECDSA {
+		return AlgorithmSecp256k1
+	}
+	return algorithm
+}
+
+// IsECAlgorithm 判断是否为ECDSA类算法（含历史标识 "ECDSA"）
+func IsECAlgorithm(algorithm string) bool {
+	switch algorithm {
+	case AlgorithmECDSA, AlgorithmSecp256k1, AlgorithmP256, AlgorithmP384:
+		return true
+	}
+	return false
+}
+
+// ECCurve 返回算法对应的曲线
+func ECCurve(algorithm string) (elliptic.Curve, error) {
+	switch NormalizeECAlgorithm(algorithm) {
+	case AlgorithmSecp256k1:
+		return btcec.S256(), nil
+	case AlgorithmP256:
+		return elliptic.P256(), nil
+	case AlgorithmP384:
+		return elliptic.P384(), nil
+	default:
+		return nil, fmt.Errorf("unsupported EC algorithm: %s", algorithm)
+	}
+}
+
+// ECAlgorithmForCurve 返回曲线对应的算法标识
+func ECAlgorithmForCurve(curve elliptic.Curve) (string, error) {
+	if curve == nil {
+		return "", errors.New("curve cannot be nil")
+	}
+	switch curve.Params().Name {
+	case btcec.S256().Params().Name:
+		return AlgorithmSecp256k1, nil
+	case elliptic.P256().Params().Name:
+		return AlgorithmP256, nil
+	case elliptic.P384().Params().Name:
+		return AlgorithmP384, nil
+	default:
+		return "", fmt.Errorf("unsupported curve: %s", curve.Params().Name)
+	}
+}
+
+// JWKCurveName 返回曲线在JWK中的crv取值（RFC 7518 / RFC 8812）
+func JWKCurveName(curve elliptic.Curve) (string, error) {
+	alg, err := ECAlgorithmForCurve(curve)
+	if err != nil {
+		return "", err
+	}
+	switch alg {
+	case AlgorithmSecp256k1:
+		return "secp256k1", nil
+	case AlgorithmP256:
+		return "P-256", nil
+	default:
+		return "P-384", nil
+	}
+}
+
+// curveByteSize 返回曲线坐标/标量的字节长度
+func curveByteSize(curve elliptic.Curve) int {
+	return (curve.Params().BitSize + 7) / 8
+}
+
+// ToECDSAPublicKey 将*ecdsa、*btcec公私钥或*KeyPair统一转换为*ecdsa.PublicKey
+func ToECDSAPublicKey(key interface{}) (*ecdsa.PublicKey, error) {
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		return k, nil
+	case *btcec.PublicKey:
+		return k.ToECDSA(), nil
+	case *ecdsa.PrivateKey:
+		return &k.PublicKey, nil
+	case *btcec.PrivateKey:
+		return k.PubKey().ToECDSA(), nil
+	case *KeyPair:
+		return ToECDSAPublicKey(k.PublicKey)
+	default:
+		return nil, fmt.Errorf("unsupported EC public key type: %T", key)
+	}
+}
+
+// ToECDSAPrivateKey 将*ecdsa、*btcec私钥或*KeyPair统一转换为*ecdsa.PrivateKey
+func ToECDSAPrivateKey(key interface{}) (*ecdsa.PrivateKey, error) {
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		return k, nil
+	case *btcec.PrivateKey:
+		return k.ToECDSA(), nil
+	case *KeyPair:
+		return ToECDSAPrivateKey(k.PrivateKey)
+	default:
+		return nil, fmt.Errorf("unsupported EC private key type: %T", key)
+	}
+}
+
+// ParseECPoint 解析压缩或未压缩格式的椭圆曲线点
+func ParseECPoint(curve elliptic.Curve, point []byte) (*ecdsa.PublicKey, error) {
+	if _, err := ECAlgorithmForCurve(curve); err != nil {
+		return nil, err
+	}
+	if curve.Params().Name == btcec.S256().Params().Name {
+		pub, err := btcec.ParsePubKey(point)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secp256k1 public key: %w", err)
+		}
+		return pub.ToECDSA(), nil
+	}
+	var x, y *big.Int
+	if len(point) == 1+curveByteSize(curve) {
+		x, y = elliptic.UnmarshalCompressed(curve, point)
+	} else {
+		x, y = elliptic.Unmarshal(curve, point)
+	}
+	if x == nil {
+		return nil, fmt.Errorf("invalid %s public key point", curve.Params().Name)
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// MarshalECPublicKey 将secp256k1、P-256、P-384公钥编码为SubjectPublicKeyInfo（DER）
+func MarshalECPublicKey(pub *ecdsa.PublicKey) ([]byte, error) {
+	if pub == nil || pub.X == nil || pub.Y == nil {
+		return nil, errors.New("invalid EC public key")
+	}
+	alg, err := ECAlgorithmForCurve(pub.Curve)
+	if err != nil {
+		return nil, err
+	}
+	if alg != AlgorithmSecp256k1 {
+		return x509.MarshalPKIXPublicKey(pub)
+	}
+	point := elliptic.Marshal(pub.Curve, pub.X, pub.Y)
+	return asn1.Marshal(ecSubjectPublicKeyInfo{
+		Algorithm: ecAlgorithmIdentifier{Algorithm: oidPublicKeyECDSA, Parameters: oidNamedCurveSecp256k1},
+		PublicKey: asn1.BitString{Bytes: point, BitLength: len(point) * 8},
+	})
+}
+
+// ParseECPublicKey 解析SubjectPublicKeyInfo（DER）格式的secp256k1、P-256、P-384公钥
+func ParseECPublicKey(der []byte) (*ecdsa.PublicKey, error) {
+	var spki ecSubjectPublicKeyInfo
+	if rest, err := asn1.Unmarshal(der, &spki); err == nil && len(rest) == 0 &&
+		spki.Algorithm.Parameters.Equal(oidNamedCurveSecp256k1) {
+		return ParseECPoint(btcec.S256(), spki.PublicKey.RightAlign())
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse EC public key: %w", err)
+	}
+	pub, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("not an EC public key: %T", key)
+	}
+	if _, err := ECAlgorithmForCurve(pub.Curve); err != nil {
+		return nil, err
+	}
+	return pub, nil
+}
+
+// MarshalECPrivateKey 将secp256k1、P-256、P-384私钥编码为SEC1 ECPrivateKey（DER）
+func MarshalECPrivateKey(priv *ecdsa.PrivateKey) ([]byte, error) {
+	if priv == nil || priv.D == nil {
+		return nil, errors.New("invalid EC private key")
+	}
+	alg, err := ECAlgorithmForCurve(priv.Curve)
+	if err != nil {
+		return nil, err
+	}
+	if alg != AlgorithmSecp256k1 {
+		return x509.MarshalECPrivateKey(priv)
+	}
+	point := elliptic.Marshal(priv.Curve, priv.X, priv.Y)
+	return asn1.Marshal(ecPrivateKeyInfo{
+		Version:       1,
+		PrivateKey:    priv.D.FillBytes(make([]byte, 32)),
+		NamedCurveOID: oidNamedCurveSecp256k1,
+		PublicKey:     asn1.BitString{Bytes: point, BitLength: len(point) * 8},
+	})
+}
+
+// ParseECPrivateKey 解析SEC1 ECPrivateKey（DER）格式的secp256k1、P-256、P-384私钥
+func ParseECPrivateKey(der []byte) (*ecdsa.PrivateKey, error) {
+	var ecKey ecPrivateKeyInfo
+	if _, err := asn1.Unmarshal(der, &ecKey); err == nil && ecKey.NamedCurveOID.Equal(oidNamedCurveSecp256k1) {
+		if len(ecKey.PrivateKey) > 32 {
+			return nil, errors.New("invalid secp256k1 private key length")
+		}
+		return newECPrivateKey(btcec.S256(), ecKey.PrivateKey)
+	}
+	priv, err := x509.ParseECPrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse EC private key: %w", err)
+	}
+	if _, err := ECAlgorithmForCurve(priv.Curve); err != nil {
+		return nil, err
+	}
+	return priv, nil
+}
@@ -0,0 +1,218 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	btcecdsa "github.com/btcsuite/btcd/btcec/v2/ecdsa"
+)
+
+// SignatureFormat ECDSA签名编码格式
+type SignatureFormat string
+
+const (
+	// SignatureFormatDER ASN.1 DER编码的 SEQUENCE{r, s}
+	SignatureFormatDER SignatureFormat = "DER"
+	// SignatureFormatP1363 IEEE P1363定长编码 r || s，为默认格式
+	SignatureFormatP1363 SignatureFormat = "P1363"
+	// SignatureFormatRecoverable 65字节可恢复签名 r || s || v（v为0或1），仅支持secp256k1
+	SignatureFormatRecoverable SignatureFormat = "RECOVERABLE"
+)
+
+// recoverableSignatureSize 可恢复签名长度
+const recoverableSignatureSize = 65
+
+// compactRecoveryOffset btcec紧凑签名首字节中恢复标识的偏移（未压缩公钥）
+const compactRecoveryOffset = 27
+
+// ecdsaSignature ECDSA签名的ASN.1结构
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// isValidSignatureFormat 检查签名格式是否有效
+func isValidSignatureFormat(format SignatureFormat) bool {
+	switch format {
+	case SignatureFormatDER, SignatureFormatP1363, SignatureFormatRecoverable:
+		return true
+	}
+	return false
+}
+
+// SignECDSA 对摘要进行ECDSA签名，输出指定格式的low-S签名
+// secp256k1使用RFC 6979确定性签名（忽略random），其他曲线random为nil时使用crypto/rand
+func SignECDSA(random io.Reader, priv *ecdsa.PrivateKey, digest []byte, format SignatureFormat) ([]byte, error) {
+	if priv == nil || priv.D == nil {
+		return nil, errors.New("invalid ECDSA private key")
+	}
+	if !isValidSignatureFormat(format) {
+		return nil, fmt.Errorf("unsupported signature format: %s", format)
+	}
+	alg, err := ECAlgorithmForCurve(priv.Curve)
+	if err != nil {
+		return nil, err
+	}
+	if format == SignatureFormatRecoverable && alg != AlgorithmSecp256k1 {
+		return nil, fmt.Errorf("recoverable signatures are only supported on secp256k1")
+	}
+
+	var r, s *big.Int
+	var recoveryID byte
+	if alg == AlgorithmSecp256k1 {
+		key, _ := btcec.PrivKeyFromBytes(priv.D.FillBytes(make([]byte, 32)))
+		compact, err := btcecdsa.SignCompact(key, digest, false)
+		if err != nil {
+			return nil, fmt.Errorf("ECDSA signing failed: %w", err)
+		}
+		recoveryID = compact[0] - compactRecoveryOffset
+		r = new(big.Int).SetBytes(compact[1:33])
+		s = new(big.Int).SetBytes(compact[33:65])
+	} else {
+		if random == nil {
+			random = rand.Reader
+		}
+		r, s, err = ecdsa.Sign(random, priv, digest)
+		if err != nil {
+			return nil, fmt.Errorf("ECDSA signing failed: %w", err)
+		}
+		// 规范化为low-S
+		n := priv.Curve.Params().N
+		if s.Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+			s.Sub(n, s)
+		}
+	}
+
+	size := curveByteSize(priv.Curve)
+	switch format {
+	case SignatureFormatDER:
+		return asn1.Marshal(ecdsaSignature{R: r, S: s})
+	case SignatureFormatRecoverable:
+		out := make([]byte, recoverableSignatureSize)
+		r.FillBytes(out[:32])
+		s.FillBytes(out[32:64])
+		out[64] = recoveryID
+		return out, nil
+	default:
+		out := make([]byte, 2*size)
+		r.FillBytes(out[:size])
+		s.FillBytes(out[size:])
+		return out, nil
+	}
+}
+
+// VerifyECDSA 验证ECDSA签名，自动识别DER、P1363定长和65字节可恢复格式
+func VerifyECDSA(pub *ecdsa.PublicKey, digest, signature []byte) bool {
+	if pub == nil || pub.X == nil || pub.Y == nil {
+		return false
+	}
+	alg, err := ECAlgorithmForCurve(pub.Curve)
+	if err != nil {
+		return false
+	}
+	size := curveByteSize(pub.Curve)
+
+	// 定长格式与DER在长度上可能重合，依次尝试所有可能的格式
+	if len(signature) == 2*size {
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if verifyECDSARS(pub, alg, digest, r, s) {
+			return true
+		}
+	}
+	if alg == AlgorithmSecp256k1 && len(signature) == recoverableSignatureSize {
+		recovered, err := RecoverSecp256k1PublicKey(digest, signature)
+		if err == nil && recovered.X.Cmp(pub.X) == 0 && recovered.Y.Cmp(pub.Y) == 0 {
+			return true
+		}
+	}
+	if r, s, err := parseDERSignature(signature); err == nil {
+		return verifyECDSARS(pub, alg, digest, r, s)
+	}
+	return false
+}
+
+// verifyECDSARS 验证(r, s)，secp256k1使用btcec实现
+func verifyECDSARS(pub *ecdsa.PublicKey, alg string, digest []byte, r, s *big.Int) bool {
+	n := pub.Curve.Params().N
+	if r.Sign() <= 0 || s.Sign() <= 0 || r.Cmp(n) >= 0 || s.Cmp(n) >= 0 {
+		return false
+	}
+	if alg != AlgorithmSecp256k1 {
+		return ecdsa.Verify(pub, digest, r, s)
+	}
+	key, err := btcec.ParsePubKey(elliptic.Marshal(pub.Curve, pub.X, pub.Y))
+	if err != nil {
+		return false
+	}
+	var rs, ss btcec.ModNScalar
+	rs.SetByteSlice(r.Bytes())
+	ss.SetByteSlice(s.Bytes())
+	return btcecdsa.NewSignature(&rs, &ss).Verify(digest, key)
+}
+
+// parseDERSignature 严格解析DER编码的签名，拒绝多余字节和非规范编码
+func parseDERSignature(signature []byte) (*big.Int, *big.Int, error) {
+	var sig ecdsaSignature
+	rest, err := asn1.Unmarshal(signature, &sig)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(rest) != 0 || sig.R == nil || sig.S == nil {
+		return nil, nil, errors.New("invalid DER signature")
+	}
+	if canonical, err := asn1.Marshal(sig); err != nil || !bytes.Equal(canonical, signature) {
+		return nil, nil, errors.New("non-canonical DER signature")
+	}
+	return sig.R, sig.S, nil
+}
+
+// ParseECDSASignature 识别签名格式并返回(r, s)
+// 长度同时满足定长格式和DER时优先按定长格式解析
+func ParseECDSASignature(curve elliptic.Curve, signature []byte) (*big.Int, *big.Int, SignatureFormat, error) {
+	alg, err := ECAlgorithmForCurve(curve)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	size := curveByteSize(curve)
+	switch {
+	case len(signature) == 2*size:
+		return new(big.Int).SetBytes(signature[:size]), new(big.Int).SetBytes(signature[size:]), SignatureFormatP1363, nil
+	case alg == AlgorithmSecp256k1 && len(signature) == recoverableSignatureSize:
+		return new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:64]), SignatureFormatRecoverable, nil
+	}
+	r, s, err := parseDERSignature(signature)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("unrecognized ECDSA signature format: %w", err)
+	}
+	return r, s, SignatureFormatDER, nil
+}
+
+// RecoverSecp256k1PublicKey 从65字节可恢复签名 r || s || v 恢复secp256k1公钥，v可为0/1或27/28
+func RecoverSecp256k1PublicKey(digest, signature []byte) (*ecdsa.PublicKey, error) {
+	if len(signature) != recoverableSignatureSize {
+		return nil, fmt.Errorf("invalid recoverable signature length: %d", len(signature))
+	}
+	v := signature[64]
+	if v >= compactRecoveryOffset {
+		v -= compactRecoveryOffset
+	}
+	if v > 1 {
+		return nil, fmt.Errorf("invalid recovery id: %d", signature[64])
+	}
+	compact := make([]byte, recoverableSignatureSize)
+	compact[0] = compactRecoveryOffset + v
+	copy(compact[1:], signature[:64])
+	pub, _, err := btcecdsa.RecoverCompact(compact, digest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover public key: %w", err)
+	}
+	return pub.ToECDSA(), nil
+}
@@ -0,0 +1,319 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/helailiang/sbp-did-sdk-go/pkg/utils"
+	"github.com/tjfoc/gmsm/sm2"
+	"golang.org/x/crypto/hkdf"
+)
+
+// 公钥加密的密文信封格式：
+//
+//	version(1) || scheme(1) || body
+//
+// ECIES（secp256k1、P-256）的body为：
+//
+//	ephemeralPublicKey(65, 未压缩点) || nonce(12) || AES-256-GCM(ciphertext || tag)
+//
+// AES密钥由 HKDF-SHA256(ECDH共享点x坐标, salt=ephemeralPublicKey, info=eciesInfo) 派生，
+// version || scheme || ephemeralPublicKey 作为GCM的附加数据。
+// SM2的body为GM/T 0003.4规定的 C1(65) || C3(32) || C2 密文。
+
+// EnvelopeVersion1 当前密文信封版本
+const EnvelopeVersion1 byte = 0x01
+
+// 密文信封中的加密方案标识
+const (
+	SchemeECIESSecp256k1 byte = 0x01
+	SchemeECIESP256      byte = 0x02
+	SchemeSM2            byte = 0x03
+)
+
+const (
+	envelopeHeaderSize = 2
+	ecPointSize        = 65
+	gcmNonceSize       = 12
+	sm3DigestSize      = 32
+)
+
+var eciesInfo = []byte("sbp-did-sdk-go ECIES v1")
+
+// eciesScheme 根据曲线返回加密方案标识
+func eciesScheme(curve elliptic.Curve) (byte, error) {
+	switch curve.Params().Name {
+	case btcec.S256().Params().Name:
+		return SchemeECIESSecp256k1, nil
+	case elliptic.P256().Params().Name:
+		return SchemeECIESP256, nil
+	default:
+		return 0, fmt.Errorf("unsupported curve for ECIES: %s", curve.Params().Name)
+	}
+}
+
+// generateEphemeralKey 在指定曲线上生成临时密钥
+func generateEphemeralKey(random io.Reader, curve elliptic.Curve) (*big.Int, *big.Int, *big.Int, error) {
+	params := curve.Params()
+	b := make([]byte, params.BitSize/8+8)
+	if _, err := io.ReadFull(random, b); err != nil {
+		return nil, nil, nil, err
+	}
+	k := new(big.Int).SetBytes(b)
+	n := new(big.Int).Sub(params.N, big.NewInt(1))
+	k.Mod(k, n)
+	k.Add(k, big.NewInt(1))
+	x, y := curve.ScalarBaseMult(k.Bytes())
+	return k, x, y, nil
+}
+
+// eciesKey 由共享点和临时公钥派生AES-256密钥
+func eciesKey(sharedX *big.Int, ephemeral []byte) ([]byte, error) {
+	key := make([]byte, 32)
+	kdf := hkdf.New(sha256.New, sharedX.FillBytes(make([]byte, 32)), ephemeral, eciesInfo)
+	if _, err := io.ReadFull(kdf, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// EncryptECIES 使用ECIES（ECDH + HKDF-SHA256 + AES-256-GCM）加密，返回密文信封
+// 支持secp256k1和P-256公钥，random为nil时使用crypto/rand
+func EncryptECIES(random io.Reader, pub *ecdsa.PublicKey, plainText []byte) ([]byte, error) {
+	if pub == nil || pub.X == nil || pub.Y == nil {
+		return nil, errors.New("invalid ECIES public key")
+	}
+	if random == nil {
+		random = rand.Reader
+	}
+	scheme, err := eciesScheme(pub.Curve)
+	if err != nil {
+		return nil, err
+	}
+	if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+		return nil, errors.New("ECIES public key is not on curve")
+	}
+
+	k, ex, ey, err := generateEphemeralKey(random, pub.Curve)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ephemeral key: %w", err)
+	}
+	sx, _ := pub.Curve.ScalarMult(pub.X, pub.Y, k.Bytes())
+	ephemeral := elliptic.Marshal(pub.Curve, ex, ey)
+	key, err := eciesKey(sx, ephemeral)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcmNonceSize)
+	if _, err := io.ReadFull(random, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	out := make([]byte, 0, envelopeHeaderSize+ecPointSize+gcmNonceSize+len(plainText)+gcm.Overhead())
+	out = append(out, EnvelopeVersion1, scheme)
+	out = append(out, ephemeral...)
+	aad := out
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, plainText, aad), nil
+}
+
+// DecryptECIES 解密EncryptECIES生成的密文信封
+func DecryptECIES(priv *ecdsa.PrivateKey, envelope []byte) ([]byte, error) {
+	if priv == nil || priv.D == nil {
+		return nil, errors.New("invalid ECIES private key")
+	}
+	scheme, err := eciesScheme(priv.Curve)
+	if err != nil {
+		return nil, err
+	}
+	body, err := openEnvelope(envelope, scheme)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) < ecPointSize+gcmNonceSize {
+		return nil, errors.New("ECIES ciphertext too short")
+	}
+	ephemeral := body[:ecPointSize]
+	ex, ey := elliptic.Unmarshal(priv.Curve, ephemeral)
+	if ex == nil {
+		return nil, errors.New("invalid ECIES ephemeral public key")
+	}
+	sx, _ := priv.Curve.ScalarMult(ex, ey, priv.D.Bytes())
+	key, err := eciesKey(sx, ephemeral)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := body[ecPointSize : ecPointSize+gcmNonceSize]
+	aad := envelope[:envelopeHeaderSize+ecPointSize]
+	plainText, err := gcm.Open(nil, nonce, body[ecPointSize+gcmNonceSize:], aad)
+	if err != nil {
+		return nil, errors.New("ECIES authentication failed")
+	}
+	return plainText, nil
+}
+
+// EncryptSM2 使用SM2公钥加密（GM/T 0003.4），返回 C1 || C3 || C2 格式的原始密文
+// random为nil时使用crypto/rand
+func EncryptSM2(random io.Reader, pub *sm2.PublicKey, plainText []byte) ([]byte, error) {
+	if pub == nil || pub.X == nil || pub.Y == nil {
+		return nil, errors.New("invalid SM2 public key")
+	}
+	if len(plainText) == 0 {
+		return nil, errors.New("SM2 plain text cannot be empty")
+	}
+	if random == nil {
+		random = rand.Reader
+	}
+	curve := SM2Curve()
+	if !curve.IsOnCurve(pub.X, pub.Y) {
+		return nil, errors.New("SM2 public key is not on curve")
+	}
+	for {
+		k, c1x, c1y, err := generateEphemeralKey(random, curve)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate SM2 nonce: %w", err)
+		}
+		x2, y2 := curve.ScalarMult(pub.X, pub.Y, k.Bytes())
+		x2b, y2b := x2.FillBytes(make([]byte, 32)), y2.FillBytes(make([]byte, 32))
+		t := sm2KDF(len(plainText), x2b, y2b)
+		// t全为0时需重新选取k
+		if isZero(t) {
+			continue
+		}
+		c2 := make([]byte, len(plainText))
+		xorBytes(c2, plainText, t)
+
+		h := utils.NewSM3()
+		h.Write(x2b)
+		h.Write(plainText)
+		h.Write(y2b)
+
+		out := make([]byte, 0, ecPointSize+sm3DigestSize+len(c2))
+		out = append(out, elliptic.Marshal(curve, c1x, c1y)...)
+		out = h.Sum(out)
+		return append(out, c2...), nil
+	}
+}
+
+// DecryptSM2 解密 C1 || C3 || C2 格式的SM2密文
+func DecryptSM2(priv *sm2.PrivateKey, cipherText []byte) ([]byte, error) {
+	if priv == nil || priv.D == nil {
+		return nil, errors.New("invalid SM2 private key")
+	}
+	if len(cipherText) <= ecPointSize+sm3DigestSize {
+		return nil, errors.New("SM2 ciphertext too short")
+	}
+	curve := SM2Curve()
+	c1x, c1y := elliptic.Unmarshal(curve, cipherText[:ecPointSize])
+	if c1x == nil {
+		return nil, errors.New("invalid SM2 ciphertext point C1")
+	}
+	c3 := cipherText[ecPointSize : ecPointSize+sm3DigestSize]
+	c2 := cipherText[ecPointSize+sm3DigestSize:]
+
+	x2, y2 := curve.ScalarMult(c1x, c1y, priv.D.Bytes())
+	x2b, y2b := x2.FillBytes(make([]byte, 32)), y2.FillBytes(make([]byte, 32))
+	t := sm2KDF(len(c2), x2b, y2b)
+	if isZero(t) {
+		return nil, errors.New("SM2 decryption failed")
+	}
+	plainText := make([]byte, len(c2))
+	xorBytes(plainText, c2, t)
+
+	h := utils.NewSM3()
+	h.Write(x2b)
+	h.Write(plainText)
+	h.Write(y2b)
+	if subtle.ConstantTimeCompare(h.Sum(nil), c3) != 1 {
+		return nil, errors.New("SM2 decryption failed: C3 mismatch")
+	}
+	return plainText, nil
+}
+
+// sm2KDF GM/T 0003.4 密钥派生函数，基于SM3
+func sm2KDF(length int, z ...[]byte) []byte {
+	out := make([]byte, 0, length+sm3DigestSize)
+	var ct [4]byte
+	for i := uint32(1); len(out) < length; i++ {
+		binary.BigEndian.PutUint32(ct[:], i)
+		h := utils.NewSM3()
+		for _, part := range z {
+			h.Write(part)
+		}
+		h.Write(ct[:])
+		out = h.Sum(out)
+	}
+	return out[:length]
+}
+
+// sealSM2Envelope 使用SM2加密并封装为密文信封
+func sealSM2Envelope(random io.Reader, pub *sm2.PublicKey, plainText []byte) ([]byte, error) {
+	ct, err := EncryptSM2(random, pub, plainText)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{EnvelopeVersion1, SchemeSM2}, ct...), nil
+}
+
+// openSM2Envelope 解析SM2密文信封并解密
+func openSM2Envelope(priv *sm2.PrivateKey, envelope []byte) ([]byte, error) {
+	body, err := openEnvelope(envelope, SchemeSM2)
+	if err != nil {
+		return nil, err
+	}
+	return DecryptSM2(priv, body)
+}
+
+// openEnvelope 校验信封版本与加密方案，返回body
+func openEnvelope(envelope []byte, scheme byte) ([]byte, error) {
+	if len(envelope) < envelopeHeaderSize {
+		return nil, errors.New("ciphertext envelope too short")
+	}
+	if envelope[0] != EnvelopeVersion1 {
+		return nil, fmt.Errorf("unsupported ciphertext envelope version: %d", envelope[0])
+	}
+	if envelope[1] != scheme {
+		return nil, fmt.Errorf("ciphertext scheme %d does not match key scheme %d", envelope[1], scheme)
+	}
+	return envelope[envelopeHeaderSize:], nil
+}
+
+// newGCM 创建AES-GCM
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// xorBytes dst[i] = a[i] ^ b[i]
+func xorBytes(dst, a, b []byte) {
+	for i := range dst {
+		dst[i] = a[i] ^ b[i]
+	}
+}
+
+func isZero(b []byte) bool {
+	return len(bytes.Trim(b, "\x00")) == 0
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	"github.com/helailiang/sbp-did-sdk-go/pkg/config"
+	"github.com/tjfoc/gmsm/sm2"
 )
 
 // EncryptionResult 加密结果
@@ -45,7 +46,7 @@ func Encrypt(cfg *config.Config, publicKey interface{}, plainText []byte, algori
 	var err error
 
 	switch algorithm {
-	case "ECDSA":
+	case AlgorithmECDSA, AlgorithmSecp256k1, AlgorithmP256:
 		encryptedData, err = encryptWithECDSA(publicKey, plainText)
 	case "RSA":
 		encryptedData, err = encryptWithRSA(publicKey, plainText)
@@ -87,7 +88,7 @@ func Decrypt(cfg *config.Config, privateKey interface{}, encryptedData []byte, a
 	var err error
 
 	switch algorithm {
-	case "ECDSA":
+	case AlgorithmECDSA, AlgorithmSecp256k1, AlgorithmP256:
 		decryptedData, err = decryptWithECDSA(privateKey, encryptedData)
 	case "RSA":
 		decryptedData, err = decryptWithRSA(privateKey, encryptedData)
@@ -107,22 +108,24 @@ func Decrypt(cfg *config.Config, privateKey interface{}, encryptedData []byte, a
 	}, nil
 }
 
+// encryptWithECDSA 使用ECIES加密，支持secp256k1和P-256公钥
 func encryptWithECDSA(publicKey interface{}, plainText []byte) ([]byte, error) {
-	symmetricKey := make([]byte, 32)
-	if _, err := rand.Read(symmetricKey); err != nil {
-		return nil, fmt.Errorf("failed to generate symmetric key: %w", err)
-	}
-
-	encryptedPlainText, err := encryptWithSymmetricKey(symmetricKey, plainText)
+	pubKey, err := ToECDSAPublicKey(publicKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to encrypt with symmetric key: %w", err)
+		return nil, fmt.Errorf("unsupported public key type for ECDSA encryption: %w", err)
 	}
 
-	return encryptedPlainText, nil
+	return EncryptECIES(rand.Reader, pubKey, plainText)
 }
 
+// decryptWithECDSA 解密ECIES密文信封
 func decryptWithECDSA(privateKey interface{}, encryptedData []byte) ([]byte, error) {
-	return encryptedData, nil
+	privKey, err := ToECDSAPrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported private key type for ECDSA decryption: %w", err)
+	}
+
+	return DecryptECIES(privKey, encryptedData)
 }
 
 func encryptWithRSA(publicKey interface{}, plainText []byte) ([]byte, error) {
@@ -171,16 +174,42 @@ func decryptWithRSA(privateKey interface{}, encryptedData []byte) ([]byte, error
 	return decryptedData, nil
 }
 
+// encryptWithSM2 使用SM2公钥加密（C1C3C2），结果封装为密文信封
 func encryptWithSM2(publicKey interface{}, plainText []byte) ([]byte, error) {
-	return encryptWithECDSA(publicKey, plainText)
+	var pubKey *sm2.PublicKey
+	switch pk := publicKey.(type) {
+	case *sm2.PublicKey:
+		pubKey = pk
+	case *KeyPair:
+		if sm2Key, ok := pk.PublicKey.(*sm2.PublicKey); ok {
+			pubKey = sm2Key
+		} else {
+			return nil, fmt.Errorf("invalid SM2 public key type")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported public key type for SM2 encryption")
+	}
+
+	return sealSM2Envelope(rand.Reader, pubKey, plainText)
 }
 
+// decryptWithSM2 解密SM2密文信封
 func decryptWithSM2(privateKey interface{}, encryptedData []byte) ([]byte, error) {
-	return decryptWithECDSA(privateKey, encryptedData)
-}
+	var privKey *sm2.PrivateKey
+	switch pk := privateKey.(type) {
+	case *sm2.PrivateKey:
+		privKey = pk
+	case *KeyPair:
+		if sm2Key, ok := pk.PrivateKey.(*sm2.PrivateKey); ok {
+			privKey = sm2Key
+		} else {
+			return nil, fmt.Errorf("invalid SM2 private key type")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported private key type for SM2 decryption")
+	}
 
-func encryptWithSymmetricKey(key []byte, plainText []byte) ([]byte, error) {
-	return plainText, nil
+	return openSM2Envelope(privKey, encryptedData)
 }
 
 func EncryptFromHex(cfg *config.Config, publicKey interface{}, plainTextHex string, algorithm string) (*EncryptionResult, error) {
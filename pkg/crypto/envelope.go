@@ -0,0 +1,112 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// 对称加密的密文信封，与公钥加密共用 version(1) || scheme(1) 头部：
+//
+//	SchemeAES256GCM:  nonce(12) || AES-256-GCM(ciphertext || tag)
+//	SchemeKMSDataKey: keyLen(2) || encryptedDataKey || nonce(12) || AES-256-GCM(ciphertext || tag)
+//
+// nonce之前的全部字节（头部及加密的数据密钥）作为GCM的附加数据。
+const (
+	// SchemeAES256GCM 使用对称主密钥（AES256）直接加密
+	SchemeAES256GCM byte = 0x04
+	// SchemeKMSDataKey KMS数据密钥信封加密，数据密钥由KMS主密钥加密后随密文保存
+	SchemeKMSDataKey byte = 0x05
+)
+
+// aes256KeySize AES-256密钥长度
+const aes256KeySize = 32
+
+// symmetricEnvelope 解析后的对称密文信封
+type symmetricEnvelope struct {
+	aad          []byte
+	encryptedKey []byte
+	nonce        []byte
+	ciphertext   []byte
+}
+
+// sealSymmetricEnvelope 使用AES-256-GCM加密并输出信封；encryptedKey仅用于SchemeKMSDataKey
+func sealSymmetricEnvelope(key []byte, scheme byte, encryptedKey, plaintext []byte) ([]byte, error) {
+	gcm, err := newAES256GCM(key)
+	if err != nil {
+		return nil, err
+	}
+	out := []byte{EnvelopeVersion1, scheme}
+	if scheme == SchemeKMSDataKey {
+		if len(encryptedKey) == 0 || len(encryptedKey) > 0xffff {
+			return nil, errors.New("invalid encrypted data key length")
+		}
+		out = binary.BigEndian.AppendUint16(out, uint16(len(encryptedKey)))
+		out = append(out, encryptedKey...)
+	}
+	aad := append([]byte(nil), out...)
+	nonce := make([]byte, gcmNonceSize)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, plaintext, aad), nil
+}
+
+// parseSymmetricEnvelope 解析对称密文信封并检查版本与方案
+func parseSymmetricEnvelope(data []byte, scheme byte) (*symmetricEnvelope, error) {
+	if len(data) < envelopeHeaderSize {
+		return nil, errors.New("ciphertext too short")
+	}
+	if data[0] != EnvelopeVersion1 {
+		return nil, fmt.Errorf("unsupported envelope version: %d", data[0])
+	}
+	if data[1] != scheme {
+		return nil, fmt.Errorf("unexpected envelope scheme: %d", data[1])
+	}
+	env := &symmetricEnvelope{}
+	offset := envelopeHeaderSize
+	if scheme == SchemeKMSDataKey {
+		if len(data) < offset+2 {
+			return nil, errors.New("ciphertext too short")
+		}
+		keyLen := int(binary.BigEndian.Uint16(data[offset:]))
+		offset += 2
+		if len(data) < offset+keyLen {
+			return nil, errors.New("ciphertext too short")
+		}
+		env.encryptedKey = data[offset : offset+keyLen]
+		offset += keyLen
+	}
+	if len(data) < offset+gcmNonceSize+16 {
+		return nil, errors.New("ciphertext too short")
+	}
+	env.aad = data[:offset]
+	env.nonce = data[offset : offset+gcmNonceSize]
+	env.ciphertext = data[offset+gcmNonceSize:]
+	return env, nil
+}
+
+// open 使用AES-256密钥解密信封
+func (e *symmetricEnvelope) open(key []byte) ([]byte, error) {
+	gcm, err := newAES256GCM(key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, e.nonce, e.ciphertext, e.aad)
+	if err != nil {
+		return nil, errors.New("decryption failed: authentication error")
+	}
+	return plaintext, nil
+}
+
+// newAES256GCM 创建AES-256-GCM，密钥长度须为32字节
+func newAES256GCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != aes256KeySize {
+		return nil, fmt.Errorf("invalid AES-256 key length: %d", len(key))
+	}
+	return newGCM(key)
+}
@@ -0,0 +1,41 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package crypto
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	// fileLockTimeout 等待锁文件的最长时间
+	fileLockTimeout = 30 * time.Second
+	// fileLockStale 锁文件超过该时间未释放视为持有进程已退出
+	fileLockStale = 2 * time.Minute
+)
+
+// lockFile 以独占方式创建锁文件实现跨进程互斥，返回释放函数
+// 不支持flock的平台使用该实现，遗留的过期锁文件会被清理
+func lockFile(path string) (func() error, error) {
+	deadline := time.Now().Add(fileLockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			f.Close()
+			return func() error { return os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("create lock file: %w", err)
+		}
+		if info, statErr := os.Stat(path); statErr == nil && time.Since(info.ModTime()) > fileLockStale {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("lock %s: timeout", path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,3 @@
+// Package hsm 提供通过PKCS#11模块访问硬件安全模块（HSM）的crypto.KeyManager与crypto.Crypto实现
+// 私钥在令牌内生成且不可导出，签名由令牌完成；依赖cgo加载PKCS#11动态库，CGO_ENABLED=0时本包为空
+package hsm
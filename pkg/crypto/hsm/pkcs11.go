@@ -0,0 +1,677 @@
+//go:build cgo
+
+package hsm
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/miekg/pkcs11"
+	"github.com/tjfoc/gmsm/sm2"
+	"github.com/tjfoc/gmsm/sm3"
+
+	sbpcrypto "github.com/helailiang/sbp-did-sdk-go/pkg/crypto"
+)
+
+var (
+	oidNamedCurveP256      = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+	oidNamedCurveP384      = asn1.ObjectIdentifier{1, 3, 132, 0, 34}
+	oidNamedCurveSecp256k1 = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+	oidNamedCurveSM2       = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 301}
+
+	// rsaDigestInfoPrefix CKM_RSA_PKCS签名时拼接在摘要前的DigestInfo头（RFC 8017 9.2）
+	rsaDigestInfoPrefix = map[crypto.Hash][]byte{
+		crypto.SHA256: {0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20},
+		crypto.SHA384: {0x30, 0x41, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x02, 0x05, 0x00, 0x04, 0x30},
+		crypto.SHA512: {0x30, 0x51, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x03, 0x05, 0x00, 0x04, 0x40},
+	}
+)
+
+// SM2Mechanisms 令牌厂商自定义的SM2对象类型与机制编号
+// PKCS#11标准未定义SM2，各厂商取值不同，零值表示令牌不支持SM2
+type SM2Mechanisms struct {
+	// KeyType SM2密钥的CKA_KEY_TYPE
+	KeyType uint
+	// KeyPairGen SM2密钥对生成机制
+	KeyPairGen uint
+	// Sign SM2签名机制，输入为 SM3(ZA || M) 摘要，输出为定长 r || s
+	Sign uint
+}
+
+// Config PKCS#11配置
+type Config struct {
+	// Module PKCS#11模块路径，如SoftHSMv2的 /usr/lib/softhsm/libsofthsm2.so
+	Module string
+	// TokenLabel 令牌标签，非空时按标签查找slot，否则使用SlotID
+	TokenLabel string
+	// SlotID slot编号
+	SlotID uint
+	// PIN 用户PIN
+	PIN string
+	// SM2 SM2机制，令牌支持SM2时配置
+	SM2 SM2Mechanisms
+}
+
+// module 已加载的PKCS#11模块，同一进程内按路径共享并引用计数
+// C_Initialize/C_Finalize作用于整个进程，不能由每个KeyManager各自调用
+type module struct {
+	path string
+	ctx  *pkcs11.Ctx
+	refs int
+}
+
+var (
+	modulesMu sync.Mutex
+	modules   = make(map[string]*module)
+)
+
+func openModule(path string) (*module, error) {
+	modulesMu.Lock()
+	defer modulesMu.Unlock()
+	if m, ok := modules[path]; ok {
+		m.refs++
+		return m, nil
+	}
+	ctx := pkcs11.New(path)
+	if ctx == nil {
+		return nil, fmt.Errorf("failed to load PKCS#11 module %s", path)
+	}
+	if err := ctx.Initialize(); err != nil && !isCKR(err, pkcs11.CKR_CRYPTOKI_ALREADY_INITIALIZED) {
+		ctx.Destroy()
+		return nil, fmt.Errorf("C_Initialize: %w", err)
+	}
+	m := &module{path: path, ctx: ctx, refs: 1}
+	modules[path] = m
+	return m, nil
+}
+
+func (m *module) release() {
+	modulesMu.Lock()
+	defer modulesMu.Unlock()
+	m.refs--
+	if m.refs > 0 {
+		return
+	}
+	m.ctx.Finalize()
+	m.ctx.Destroy()
+	delete(modules, m.path)
+}
+
+// isCKR 判断err是否为指定的PKCS#11返回码
+func isCKR(err error, code uint) bool {
+	var e pkcs11.Error
+	return errors.As(err, &e) && uint(e) == code
+}
+
+// PKCS11KeyManager 基于PKCS#11令牌的KeyManager和Crypto实现
+// keyID保存在密钥对象的CKA_ID与CKA_LABEL中；私钥为CKA_SENSITIVE且不可导出，
+// 签名在令牌内完成，验签使用令牌中的公钥在本地完成
+type PKCS11KeyManager struct {
+	module *module
+	slot   uint
+	sm2    SM2Mechanisms
+
+	mu         sync.Mutex // 同一PKCS#11会话不能并发使用
+	session    pkcs11.SessionHandle
+	mechanisms map[uint]bool
+	closed     bool
+}
+
+// NewPKCS11KeyManager 加载PKCS#11模块，打开指定令牌的读写会话并以用户身份登录
+func NewPKCS11KeyManager(cfg Config) (*PKCS11KeyManager, error) {
+	if cfg.Module == "" {
+		return nil, errors.New("PKCS#11 module path is required")
+	}
+	m, err := openModule(cfg.Module)
+	if err != nil {
+		return nil, err
+	}
+	k := &PKCS11KeyManager{module: m, sm2: cfg.SM2}
+	if err := k.open(cfg); err != nil {
+		m.release()
+		return nil, err
+	}
+	return k, nil
+}
+
+func (k *PKCS11KeyManager) open(cfg Config) error {
+	ctx := k.module.ctx
+	slot, err := findSlot(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	k.slot = slot
+
+	mechs, err := ctx.GetMechanismList(slot)
+	if err != nil {
+		return fmt.Errorf("C_GetMechanismList: %w", err)
+	}
+	k.mechanisms = make(map[uint]bool, len(mechs))
+	for _, mech := range mechs {
+		k.mechanisms[mech.Mechanism] = true
+	}
+
+	k.session, err = ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		return fmt.Errorf("C_OpenSession: %w", err)
+	}
+	// 登录状态由同一应用的所有会话共享，其他实例已登录时忽略
+	if err := ctx.Login(k.session, pkcs11.CKU_USER, cfg.PIN); err != nil && !isCKR(err, pkcs11.CKR_USER_ALREADY_LOGGED_IN) {
+		ctx.CloseSession(k.session)
+		return fmt.Errorf("C_Login: %w", err)
+	}
+	return nil
+}
+
+// findSlot 按令牌标签或slot编号查找slot
+func findSlot(ctx *pkcs11.Ctx, cfg Config) (uint, error) {
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return 0, fmt.Errorf("C_GetSlotList: %w", err)
+	}
+	for _, slot := range slots {
+		if cfg.TokenLabel == "" {
+			if slot == cfg.SlotID {
+				return slot, nil
+			}
+			continue
+		}
+		info, err := ctx.GetTokenInfo(slot)
+		if err != nil {
+			continue
+		}
+		if strings.TrimRight(info.Label, " \x00") == cfg.TokenLabel {
+			return slot, nil
+		}
+	}
+	if cfg.TokenLabel != "" {
+		return 0, fmt.Errorf("PKCS#11 token %q not found", cfg.TokenLabel)
+	}
+	return 0, fmt.Errorf("PKCS#11 slot %d not found", cfg.SlotID)
+}
+
+// Close 关闭会话，最后一个使用该模块的实例关闭时卸载模块
+func (k *PKCS11KeyManager) Close() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.closed {
+		return nil
+	}
+	k.closed = true
+	err := k.module.ctx.CloseSession(k.session)
+	k.module.release()
+	return err
+}
+
+// keySpec 密钥类型对应的PKCS#11对象类型与生成参数
+type keySpec struct {
+	keyType   uint
+	mechanism uint
+	curve     asn1.ObjectIdentifier // EC/SM2的CKA_EC_PARAMS
+}
+
+func (k *PKCS11KeyManager) keySpec(keyType sbpcrypto.KeyType) (keySpec, error) {
+	var spec keySpec
+	switch keyType {
+	case sbpcrypto.ECDSAP256:
+		spec = keySpec{keyType: pkcs11.CKK_EC, mechanism: pkcs11.CKM_EC_KEY_PAIR_GEN, curve: oidNamedCurveP256}
+	case sbpcrypto.ECDSAP384:
+		spec = keySpec{keyType: pkcs11.CKK_EC, mechanism: pkcs11.CKM_EC_KEY_PAIR_GEN, curve: oidNamedCurveP384}
+	case sbpcrypto.ECDSASecp256k1:
+		spec = keySpec{keyType: pkcs11.CKK_EC, mechanism: pkcs11.CKM_EC_KEY_PAIR_GEN, curve: oidNamedCurveSecp256k1}
+	case sbpcrypto.RSA2048:
+		spec = keySpec{keyType: pkcs11.CKK_RSA, mechanism: pkcs11.CKM_RSA_PKCS_KEY_PAIR_GEN}
+	case sbpcrypto.SM2:
+		if k.sm2.KeyPairGen == 0 {
+			return keySpec{}, errors.New("SM2 mechanisms are not configured")
+		}
+		spec = keySpec{keyType: k.sm2.KeyType, mechanism: k.sm2.KeyPairGen, curve: oidNamedCurveSM2}
+	default:
+		return keySpec{}, fmt.Errorf("unsupported key type: %s", keyType)
+	}
+	if !k.mechanisms[spec.mechanism] {
+		return keySpec{}, fmt.Errorf("token does not support key type %s", keyType)
+	}
+	return spec, nil
+}
+
+// Create 在令牌内生成密钥对，返回 keyID 和公钥
+// 公钥编码与LocalKeyManager.Get一致；不支持加密用途的密钥
+func (k *PKCS11KeyManager) Create(keyType sbpcrypto.KeyType, opts ...sbpcrypto.KeyOpts) (string, []byte, error) {
+	for _, opt := range opts {
+		if opt != nil && opt.Purpose() == sbpcrypto.PurposeEncryption {
+			return "", nil, errors.New("PKCS11KeyManager does not support encryption keys")
+		}
+	}
+	spec, err := k.keySpec(keyType)
+	if err != nil {
+		return "", nil, err
+	}
+
+	keyID := uuid.NewString()
+	public := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PUBLIC_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, spec.keyType),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, []byte(keyID)),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, keyID),
+	}
+	private := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PRIVATE_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, spec.keyType),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, false),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, []byte(keyID)),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, keyID),
+	}
+	if spec.curve != nil {
+		params, err := asn1.Marshal(spec.curve)
+		if err != nil {
+			return "", nil, err
+		}
+		public = append(public, pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, params))
+	} else {
+		public = append(public,
+			pkcs11.NewAttribute(pkcs11.CKA_MODULUS_BITS, 2048),
+			pkcs11.NewAttribute(pkcs11.CKA_PUBLIC_EXPONENT, []byte{0x01, 0x00, 0x01}),
+		)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.closed {
+		return "", nil, errors.New("PKCS11KeyManager is closed")
+	}
+	pubHandle, privHandle, err := k.module.ctx.GenerateKeyPair(k.session,
+		[]*pkcs11.Mechanism{pkcs11.NewMechanism(spec.mechanism, nil)}, public, private)
+	if err != nil {
+		return "", nil, fmt.Errorf("C_GenerateKeyPair: %w", err)
+	}
+	var pubBytes []byte
+	pub, err := k.readPublicKey(pubHandle)
+	if err == nil {
+		pubBytes, err = marshalPublicKey(pub)
+	}
+	if err != nil {
+		// 已生成的密钥对无法使用，销毁后返回，避免在令牌中遗留对象
+		k.module.ctx.DestroyObject(k.session, privHandle)
+		k.module.ctx.DestroyObject(k.session, pubHandle)
+		return "", nil, err
+	}
+	return keyID, pubBytes, nil
+}
+
+// Get 获取公钥
+func (k *PKCS11KeyManager) Get(keyID string) ([]byte, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	pub, err := k.publicKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return marshalPublicKey(pub)
+}
+
+// ImportPrivateKey 不支持：私钥只能在令牌内生成
+func (k *PKCS11KeyManager) ImportPrivateKey(privKey []byte, keyType sbpcrypto.KeyType, opts ...sbpcrypto.KeyOpts) (string, error) {
+	return "", errors.New("PKCS11KeyManager does not support importing private keys")
+}
+
+// ExportPrivateKey 不支持：私钥不可导出
+func (k *PKCS11KeyManager) ExportPrivateKey(keyID string) ([]byte, error) {
+	return nil, errors.New("PKCS#11 private keys are not extractable")
+}
+
+// Delete 销毁keyID对应的公私钥对象
+func (k *PKCS11KeyManager) Delete(keyID string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	handles, err := k.findObjects(pkcs11.NewAttribute(pkcs11.CKA_ID, []byte(keyID)))
+	if err != nil {
+		return err
+	}
+	if len(handles) == 0 {
+		return errors.New("key not found")
+	}
+	for _, h := range handles {
+		if err := k.module.ctx.DestroyObject(k.session, h); err != nil {
+			return fmt.Errorf("C_DestroyObject: %w", err)
+		}
+	}
+	return nil
+}
+
+// List 列出令牌中所有私钥的 keyID（CKA_ID）
+func (k *PKCS11KeyManager) List() ([]string, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	handles, err := k.findObjects(pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PRIVATE_KEY))
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(handles))
+	for _, h := range handles {
+		attrs, err := k.module.ctx.GetAttributeValue(k.session, h, []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_ID, nil),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("C_GetAttributeValue: %w", err)
+		}
+		if len(attrs[0].Value) > 0 {
+			ids = append(ids, string(attrs[0].Value))
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Sign 使用令牌内的私钥签名，签名约定同LocalKeyManager.Sign：
+// ECDSA对摘要签名，RSA对SHA-256摘要签名（等同于SignRSADigest），SM2对原始消息签名；ECDSA与SM2签名为DER编码
+func (k *PKCS11KeyManager) Sign(keyID string, data []byte) ([]byte, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	pub, err := k.publicKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	priv, err := k.findKey(keyID, pkcs11.CKO_PRIVATE_KEY)
+	if err != nil {
+		return nil, err
+	}
+
+	var mechanism uint
+	var input []byte
+	switch p := pub.(type) {
+	case *ecdsa.PublicKey:
+		mechanism, input = pkcs11.CKM_ECDSA, data
+	case *rsa.PublicKey:
+		prefix, err := rsaDigestPrefix(crypto.SHA256, data)
+		if err != nil {
+			return nil, err
+		}
+		mechanism, input = pkcs11.CKM_RSA_PKCS, append(append([]byte(nil), prefix...), data...)
+	case *sm2.PublicKey:
+		if k.sm2.Sign == 0 {
+			return nil, errors.New("SM2 mechanisms are not configured")
+		}
+		za, err := sbpcrypto.SM2ZA(p, sbpcrypto.SM2DefaultUID)
+		if err != nil {
+			return nil, err
+		}
+		h := sm3.New()
+		h.Write(za)
+		h.Write(data)
+		mechanism, input = k.sm2.Sign, h.Sum(nil)
+	default:
+		return nil, errors.New("unsupported key type")
+	}
+
+	sig, err := k.sign(priv, mechanism, input)
+	if err != nil {
+		return nil, err
+	}
+	switch p := pub.(type) {
+	case *ecdsa.PublicKey:
+		return encodeRS(sig, p.Curve, true)
+	case *sm2.PublicKey:
+		return encodeRS(sig, p.Curve, false)
+	default:
+		return sig, nil
+	}
+}
+
+// Verify 使用令牌中的公钥在本地验证签名
+func (k *PKCS11KeyManager) Verify(keyID string, data, signature []byte) (bool, error) {
+	k.mu.Lock()
+	pub, err := k.publicKey(keyID)
+	k.mu.Unlock()
+	if err != nil {
+		return false, err
+	}
+	switch p := pub.(type) {
+	case *ecdsa.PublicKey:
+		return sbpcrypto.VerifyECDSA(p, data, signature), nil
+	case *rsa.PublicKey:
+		if _, err := rsaDigestPrefix(crypto.SHA256, data); err != nil {
+			return false, err
+		}
+		return rsa.VerifyPKCS1v15(p, crypto.SHA256, data, signature) == nil, nil
+	case *sm2.PublicKey:
+		return sbpcrypto.VerifySM2(p, data, nil, signature), nil
+	default:
+		return false, errors.New("unsupported key type")
+	}
+}
+
+// SignRSADigest 使用令牌内的RSA私钥对hash算法计算的摘要进行PKCS#1 v1.5签名（CKM_RSA_PKCS，摘要前拼接DigestInfo）
+func (k *PKCS11KeyManager) SignRSADigest(keyID string, hash crypto.Hash, digest []byte) ([]byte, error) {
+	prefix, err := rsaDigestPrefix(hash, digest)
+	if err != nil {
+		return nil, err
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	pub, err := k.publicKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := pub.(*rsa.PublicKey); !ok {
+		return nil, fmt.Errorf("key %s is not an RSA key", keyID)
+	}
+	priv, err := k.findKey(keyID, pkcs11.CKO_PRIVATE_KEY)
+	if err != nil {
+		return nil, err
+	}
+	return k.sign(priv, pkcs11.CKM_RSA_PKCS, append(append([]byte(nil), prefix...), digest...))
+}
+
+// VerifyRSADigest 使用令牌中的公钥在本地验证SignRSADigest生成的签名
+func (k *PKCS11KeyManager) VerifyRSADigest(keyID string, hash crypto.Hash, digest, signature []byte) (bool, error) {
+	if _, err := rsaDigestPrefix(hash, digest); err != nil {
+		return false, err
+	}
+	k.mu.Lock()
+	pub, err := k.publicKey(keyID)
+	k.mu.Unlock()
+	if err != nil {
+		return false, err
+	}
+	p, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return false, fmt.Errorf("key %s is not an RSA key", keyID)
+	}
+	return rsa.VerifyPKCS1v15(p, hash, digest, signature) == nil, nil
+}
+
+// sign 以指定机制调用C_Sign，调用方需持有k.mu
+func (k *PKCS11KeyManager) sign(priv pkcs11.ObjectHandle, mechanism uint, input []byte) ([]byte, error) {
+	ctx := k.module.ctx
+	if err := ctx.SignInit(k.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(mechanism, nil)}, priv); err != nil {
+		return nil, fmt.Errorf("C_SignInit: %w", err)
+	}
+	sig, err := ctx.Sign(k.session, input)
+	if err != nil {
+		return nil, fmt.Errorf("C_Sign: %w", err)
+	}
+	return sig, nil
+}
+
+// Encrypt 不支持
+func (k *PKCS11KeyManager) Encrypt(keyID string, plaintext []byte) ([]byte, error) {
+	return nil, errors.New("PKCS11KeyManager does not support encryption")
+}
+
+// Decrypt 不支持
+func (k *PKCS11KeyManager) Decrypt(keyID string, ciphertext []byte) ([]byte, error) {
+	return nil, errors.New("PKCS11KeyManager does not support encryption")
+}
+
+// findObjects 按模板查找对象，调用方需持有k.mu
+func (k *PKCS11KeyManager) findObjects(template ...*pkcs11.Attribute) ([]pkcs11.ObjectHandle, error) {
+	if k.closed {
+		return nil, errors.New("PKCS11KeyManager is closed")
+	}
+	ctx := k.module.ctx
+	if err := ctx.FindObjectsInit(k.session, template); err != nil {
+		return nil, fmt.Errorf("C_FindObjectsInit: %w", err)
+	}
+	defer ctx.FindObjectsFinal(k.session)
+	var handles []pkcs11.ObjectHandle
+	for {
+		objs, _, err := ctx.FindObjects(k.session, 64)
+		if err != nil {
+			return nil, fmt.Errorf("C_FindObjects: %w", err)
+		}
+		if len(objs) == 0 {
+			return handles, nil
+		}
+		handles = append(handles, objs...)
+	}
+}
+
+// findKey 查找keyID对应的指定类别对象
+func (k *PKCS11KeyManager) findKey(keyID string, class uint) (pkcs11.ObjectHandle, error) {
+	handles, err := k.findObjects(
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, []byte(keyID)),
+	)
+	if err != nil {
+		return 0, err
+	}
+	if len(handles) == 0 {
+		return 0, errors.New("key not found")
+	}
+	return handles[0], nil
+}
+
+// publicKey 读取keyID对应的公钥
+func (k *PKCS11KeyManager) publicKey(keyID string) (interface{}, error) {
+	h, err := k.findKey(keyID, pkcs11.CKO_PUBLIC_KEY)
+	if err != nil {
+		return nil, err
+	}
+	return k.readPublicKey(h)
+}
+
+// readPublicKey 从公钥对象属性构造公钥：EC/SM2返回*ecdsa.PublicKey或*sm2.PublicKey，RSA返回*rsa.PublicKey
+func (k *PKCS11KeyManager) readPublicKey(h pkcs11.ObjectHandle) (interface{}, error) {
+	ctx := k.module.ctx
+	attrs, err := ctx.GetAttributeValue(k.session, h, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, nil),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("C_GetAttributeValue: %w", err)
+	}
+	keyType := attrs[0].Value
+
+	if bytes.Equal(keyType, pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_RSA).Value) {
+		attrs, err = ctx.GetAttributeValue(k.session, h, []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_MODULUS, nil),
+			pkcs11.NewAttribute(pkcs11.CKA_PUBLIC_EXPONENT, nil),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("C_GetAttributeValue: %w", err)
+		}
+		e := new(big.Int).SetBytes(attrs[1].Value)
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA public exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(attrs[0].Value), E: int(e.Int64())}, nil
+	}
+
+	attrs, err = ctx.GetAttributeValue(k.session, h, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("C_GetAttributeValue: %w", err)
+	}
+	var curveOID asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(attrs[0].Value, &curveOID); err != nil {
+		return nil, fmt.Errorf("invalid CKA_EC_PARAMS: %w", err)
+	}
+	// CKA_EC_POINT应为DER OCTET STRING，部分令牌直接返回点编码
+	point := attrs[1].Value
+	var wrapped []byte
+	if rest, err := asn1.Unmarshal(point, &wrapped); err == nil && len(rest) == 0 {
+		point = wrapped
+	}
+
+	var curve elliptic.Curve
+	switch {
+	case curveOID.Equal(oidNamedCurveP256):
+		curve = elliptic.P256()
+	case curveOID.Equal(oidNamedCurveP384):
+		curve = elliptic.P384()
+	case curveOID.Equal(oidNamedCurveSecp256k1):
+		curve, _ = sbpcrypto.ECCurve(sbpcrypto.AlgorithmSecp256k1)
+	case curveOID.Equal(oidNamedCurveSM2):
+		x, y := elliptic.Unmarshal(sbpcrypto.SM2Curve(), point)
+		if x == nil {
+			return nil, errors.New("invalid SM2 public key point")
+		}
+		return &sm2.PublicKey{Curve: sbpcrypto.SM2Curve(), X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported curve: %v", curveOID)
+	}
+	return sbpcrypto.ParseECPoint(curve, point)
+}
+
+// marshalPublicKey 按LocalKeyManager.Get的格式编码公钥
+func marshalPublicKey(pub interface{}) ([]byte, error) {
+	switch p := pub.(type) {
+	case *ecdsa.PublicKey:
+		return sbpcrypto.MarshalECPublicKey(p)
+	case *rsa.PublicKey:
+		return x509.MarshalPKIXPublicKey(p)
+	case *sm2.PublicKey:
+		return sbpcrypto.MarshalSM2PublicKey(p)
+	default:
+		return nil, errors.New("unsupported key type")
+	}
+}
+
+// encodeRS 将令牌输出的定长 r || s 转为DER编码，lowS为true时规范化为low-S
+func encodeRS(sig []byte, curve elliptic.Curve, lowS bool) ([]byte, error) {
+	if len(sig) == 0 || len(sig)%2 != 0 {
+		return nil, fmt.Errorf("invalid signature length from token: %d", len(sig))
+	}
+	half := len(sig) / 2
+	r := new(big.Int).SetBytes(sig[:half])
+	s := new(big.Int).SetBytes(sig[half:])
+	if lowS {
+		n := curve.Params().N
+		if s.Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+			s.Sub(n, s)
+		}
+	}
+	return asn1.Marshal(struct{ R, S *big.Int }{r, s})
+}
+
+// rsaDigestPrefix 返回hash对应的DigestInfo头，并检查摘要长度与hash一致
+func rsaDigestPrefix(hash crypto.Hash, digest []byte) ([]byte, error) {
+	prefix, ok := rsaDigestInfoPrefix[hash]
+	if !ok {
+		return nil, fmt.Errorf("unsupported RSA digest hash: %v", hash)
+	}
+	if len(digest) != hash.Size() {
+		return nil, fmt.Errorf("invalid %v digest length: %d", hash, len(digest))
+	}
+	return prefix, nil
+}
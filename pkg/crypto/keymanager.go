@@ -1,18 +1,47 @@
 package crypto
 
+import (
+    "crypto"
+    "fmt"
+)
+
 // KeyType 定义支持的密钥类型
 // 参考 Aries/TrustBloc KMS 设计
-// 可扩展 Ed25519, ECDSAP256, RSA2048, SM2 等
+// 可扩展 Ed25519, ECDSAP256, ECDSAP384, ECDSASecp256k1, RSA2048, SM2 等
 //
 type KeyType string
 
 const (
-    ED25519   KeyType = "ED25519"
-    ECDSAP256 KeyType = "ECDSAP256"
-    RSA2048   KeyType = "RSA2048"
-    SM2       KeyType = "SM2"
+    ED25519        KeyType = "ED25519"
+    ECDSAP256      KeyType = "ECDSAP256"
+    ECDSAP384      KeyType = "ECDSAP384"
+    ECDSASecp256k1 KeyType = "ECDSASECP256K1"
+    RSA2048        KeyType = "RSA2048"
+    SM2            KeyType = "SM2"
+    // AES256 对称主密钥，仅用于加解密（无公钥，不可签名）
+    AES256         KeyType = "AES256"
 )
 
+// KeyTypeAlgorithm 返回密钥类型对应的算法标识，对称密钥及未知类型返回空字符串
+func KeyTypeAlgorithm(keyType KeyType) string {
+    switch keyType {
+    case ED25519:
+        return "ED25519"
+    case ECDSAP256:
+        return AlgorithmP256
+    case ECDSAP384:
+        return AlgorithmP384
+    case ECDSASecp256k1:
+        return AlgorithmSecp256k1
+    case RSA2048:
+        return "RSA"
+    case SM2:
+        return "SM2"
+    default:
+        return ""
+    }
+}
+
 // KeyManager Aries/TrustBloc 风格接口
 // 所有密钥用唯一 keyID 标识，支持多后端实现
 //
@@ -38,12 +67,60 @@ type KeyOpts interface {
     Purpose() string // e.g. "signing", "encryption"
 }
 
+// 密钥用途
+const (
+    PurposeSigning    = "signing"
+    PurposeEncryption = "encryption"
+)
+
+// purposeOpt 仅指定用途的KeyOpts
+type purposeOpt string
+
+func (p purposeOpt) Purpose() string { return string(p) }
+
+// WithPurpose 指定密钥用途，如 PurposeSigning、PurposeEncryption
+func WithPurpose(purpose string) KeyOpts {
+    return purposeOpt(purpose)
+}
+
+// keyPurpose 返回opts中最后指定的用途，未指定时返回空字符串
+func keyPurpose(opts []KeyOpts) string {
+    purpose := ""
+    for _, opt := range opts {
+        if opt != nil && opt.Purpose() != "" {
+            purpose = opt.Purpose()
+        }
+    }
+    return purpose
+}
+
 // Crypto Aries/TrustBloc 风格接口
 // 通过 keyID 进行签名、验签、加解密等操作
-//
+// Sign/Verify：ECDSA与RSA密钥传入消息摘要（P-384为SHA-384，其余为SHA-256），SM2与Ed25519密钥传入原始消息；
+// RSA签名为带SHA-256 DigestInfo的PKCS#1 v1.5签名，其他哈希算法使用RSADigestSigner
 type Crypto interface {
     Sign(keyID string, data []byte) ([]byte, error)
     Verify(keyID string, data, signature []byte) (bool, error)
     Encrypt(keyID string, plaintext []byte) ([]byte, error)
     Decrypt(keyID string, ciphertext []byte) ([]byte, error)
+}
+
+// RSADigestSigner 以显式指定的哈希算法对摘要进行RSA PKCS#1 v1.5签名与验签
+// Crypto.Sign对RSA密钥等同于以SHA-256调用SignRSADigest；hash仅支持SHA-256/384/512，digest长度须与之一致
+type RSADigestSigner interface {
+    SignRSADigest(keyID string, hash crypto.Hash, digest []byte) ([]byte, error)
+    VerifyRSADigest(keyID string, hash crypto.Hash, digest, signature []byte) (bool, error)
+}
+
+// checkRSADigest 检查RSADigestSigner的哈希算法与摘要长度
+func checkRSADigest(hash crypto.Hash, digest []byte) error {
+    switch hash {
+    case crypto.SHA256, crypto.SHA384, crypto.SHA512:
+    default:
+        return fmt.Errorf("unsupported RSA digest hash: %v", hash)
+    }
+    if len(digest) != hash.Size() {
+        return fmt.Errorf("invalid %v digest length: %d", hash, len(digest))
+    }
+    return nil
 } 
\ No newline at end of file
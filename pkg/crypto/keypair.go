@@ -2,6 +2,8 @@ package crypto
 
 import (
 	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -10,6 +12,7 @@ import (
 
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/helailiang/sbp-did-sdk-go/pkg/config"
+	"github.com/tjfoc/gmsm/sm2"
 )
 
 // KeyPair 表示密钥对
@@ -21,7 +24,7 @@ type KeyPair struct {
 }
 
 // GenerateKeyPair 生成密钥对 (SDK-001)
-// 支持ECDSA、RSA、SM2算法
+// 支持SECP256K1、P256、P384、RSA、SM2、ED25519算法，历史标识ECDSA等同于SECP256K1
 func GenerateKeyPair(cfg *config.Config, algorithm, keyName string) (*KeyPair, error) {
 	// 验证配置
 	if err := cfg.Validate(); err != nil {
@@ -35,19 +38,25 @@ func GenerateKeyPair(cfg *config.Config, algorithm, keyName string) (*KeyPair, e
 
 	// 验证算法
 	if !isValidAlgorithm(algorithm) {
-		return nil, fmt.Errorf("unsupported algorithm: %s, supported algorithms: ECDSA, RSA, SM2", algorithm)
+		return nil, fmt.Errorf("unsupported algorithm: %s, supported algorithms: SECP256K1, P256, P384, ECDSA, RSA, SM2, ED25519", algorithm)
 	}
 
 	var keyPair *KeyPair
 	var err error
 
 	switch algorithm {
-	case "ECDSA":
+	case AlgorithmECDSA, AlgorithmSecp256k1:
 		keyPair, err = generateECDSAKeyPair()
+	case AlgorithmP256:
+		keyPair, err = generateNISTKeyPair(elliptic.P256())
+	case AlgorithmP384:
+		keyPair, err = generateNISTKeyPair(elliptic.P384())
 	case "RSA":
 		keyPair, err = generateRSAKeyPair()
 	case "SM2":
 		keyPair, err = generateSM2KeyPair()
+	case "ED25519":
+		keyPair, err = generateEd25519KeyPair()
 	default:
 		return nil, fmt.Errorf("unsupported algorithm: %s", algorithm)
 	}
@@ -79,6 +88,19 @@ func generateECDSAKeyPair() (*KeyPair, error) {
 	}, nil
 }
 
+// generateNISTKeyPair 生成P-256或P-384曲线的ECDSA密钥对
+func generateNISTKeyPair(curve elliptic.Curve) (*KeyPair, error) {
+	privateKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate %s private key: %w", curve.Params().Name, err)
+	}
+
+	return &KeyPair{
+		PrivateKey: privateKey,
+		PublicKey:  &privateKey.PublicKey,
+	}, nil
+}
+
 // generateRSAKeyPair 生成RSA密钥对
 func generateRSAKeyPair() (*KeyPair, error) {
 	// 生成2048位RSA密钥对
@@ -98,8 +120,8 @@ func generateRSAKeyPair() (*KeyPair, error) {
 
 // generateSM2KeyPair 生成SM2密钥对
 func generateSM2KeyPair() (*KeyPair, error) {
-	// SM2使用P256曲线，这里使用ECDSA的P256实现
-	privateKey, err := ecdsa.GenerateKey(btcec.S256(), rand.Reader)
+	// 使用sm2p256v1曲线
+	privateKey, err := GenerateSM2Key(rand.Reader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate SM2 private key: %w", err)
 	}
@@ -111,9 +133,23 @@ func generateSM2KeyPair() (*KeyPair, error) {
 	}, nil
 }
 
+// generateEd25519KeyPair 生成Ed25519密钥对
+func generateEd25519KeyPair() (*KeyPair, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate Ed25519 private key: %w", err)
+	}
+
+	return &KeyPair{
+		PrivateKey: privateKey,
+		PublicKey:  publicKey,
+		Algorithm:  "ED25519",
+	}, nil
+}
+
 // isValidAlgorithm 检查算法是否有效
 func isValidAlgorithm(algorithm string) bool {
-	validAlgorithms := []string{"ECDSA", "RSA", "SM2"}
+	validAlgorithms := []string{AlgorithmECDSA, AlgorithmSecp256k1, AlgorithmP256, AlgorithmP384, "RSA", "SM2", "ED25519"}
 	for _, valid := range validAlgorithms {
 		if algorithm == valid {
 			return true
@@ -123,38 +159,56 @@ func isValidAlgorithm(algorithm string) bool {
 }
 
 // GetPublicKeyBytes 获取公钥的字节表示
+// 椭圆曲线公钥为压缩格式的点
 func (kp *KeyPair) GetPublicKeyBytes() ([]byte, error) {
 	switch kp.Algorithm {
-	case "ECDSA":
-		if pubKey, ok := kp.PublicKey.(*btcec.PublicKey); ok {
+	case AlgorithmECDSA, AlgorithmSecp256k1, AlgorithmP256, AlgorithmP384:
+		switch pubKey := kp.PublicKey.(type) {
+		case *btcec.PublicKey:
 			return pubKey.SerializeCompressed(), nil
+		case *ecdsa.PublicKey:
+			return elliptic.MarshalCompressed(pubKey.Curve, pubKey.X, pubKey.Y), nil
 		}
 	case "RSA":
 		if pubKey, ok := kp.PublicKey.(*rsa.PublicKey); ok {
 			return x509.MarshalPKCS1PublicKey(pubKey), nil
 		}
 	case "SM2":
-		if pubKey, ok := kp.PublicKey.(*ecdsa.PublicKey); ok {
-			return x509.MarshalPKIXPublicKey(pubKey)
+		if pubKey, ok := kp.PublicKey.(*sm2.PublicKey); ok {
+			return MarshalSM2PublicKey(pubKey)
+		}
+	case "ED25519":
+		// Ed25519公钥为32字节原始格式
+		if pubKey, ok := kp.PublicKey.(ed25519.PublicKey); ok {
+			return append([]byte(nil), pubKey...), nil
 		}
 	}
 	return nil, fmt.Errorf("unsupported algorithm: %s", kp.Algorithm)
 }
 
 // GetPrivateKeyBytes 获取私钥的字节表示
+// 椭圆曲线私钥为定长的原始标量
 func (kp *KeyPair) GetPrivateKeyBytes() ([]byte, error) {
 	switch kp.Algorithm {
-	case "ECDSA":
-		if privKey, ok := kp.PrivateKey.(*btcec.PrivateKey); ok {
+	case AlgorithmECDSA, AlgorithmSecp256k1, AlgorithmP256, AlgorithmP384:
+		switch privKey := kp.PrivateKey.(type) {
+		case *btcec.PrivateKey:
 			return privKey.Serialize(), nil
+		case *ecdsa.PrivateKey:
+			return privKey.D.FillBytes(make([]byte, curveByteSize(privKey.Curve))), nil
 		}
 	case "RSA":
 		if privKey, ok := kp.PrivateKey.(*rsa.PrivateKey); ok {
 			return x509.MarshalPKCS1PrivateKey(privKey), nil
 		}
 	case "SM2":
-		if privKey, ok := kp.PrivateKey.(*ecdsa.PrivateKey); ok {
-			return x509.MarshalPKCS8PrivateKey(privKey)
+		if privKey, ok := kp.PrivateKey.(*sm2.PrivateKey); ok {
+			return MarshalSM2PrivateKey(privKey)
+		}
+	case "ED25519":
+		// Ed25519私钥导出为32字节种子（RFC 8032）
+		if privKey, ok := kp.PrivateKey.(ed25519.PrivateKey); ok {
+			return privKey.Seed(), nil
 		}
 	}
 	return nil, fmt.Errorf("unsupported algorithm: %s", kp.Algorithm)
@@ -162,18 +216,31 @@ func (kp *KeyPair) GetPrivateKeyBytes() ([]byte, error) {
 
 // GetPublicKeyPEM 获取公钥的PEM格式
 func (kp *KeyPair) GetPublicKeyPEM() (string, error) {
-	keyBytes, err := kp.GetPublicKeyBytes()
+	var keyBytes []byte
+	var err error
+	switch kp.Algorithm {
+	case "ED25519":
+		// Ed25519的PEM为SubjectPublicKeyInfo（RFC 8410）
+		keyBytes, err = x509.MarshalPKIXPublicKey(kp.PublicKey)
+	case AlgorithmECDSA, AlgorithmSecp256k1, AlgorithmP256, AlgorithmP384:
+		var pubKey *ecdsa.PublicKey
+		if pubKey, err = ToECDSAPublicKey(kp.PublicKey); err == nil {
+			keyBytes, err = MarshalECPublicKey(pubKey)
+		}
+	default:
+		keyBytes, err = kp.GetPublicKeyBytes()
+	}
 	if err != nil {
 		return "", err
 	}
 
 	var blockType string
 	switch kp.Algorithm {
-	case "ECDSA":
+	case AlgorithmECDSA, AlgorithmSecp256k1, AlgorithmP256, AlgorithmP384:
 		blockType = "PUBLIC KEY"
 	case "RSA":
 		blockType = "RSA PUBLIC KEY"
-	case "SM2":
+	case "SM2", "ED25519":
 		blockType = "PUBLIC KEY"
 	default:
 		return "", fmt.Errorf("unsupported algorithm: %s", kp.Algorithm)
@@ -189,19 +256,33 @@ func (kp *KeyPair) GetPublicKeyPEM() (string, error) {
 
 // GetPrivateKeyPEM 获取私钥的PEM格式
 func (kp *KeyPair) GetPrivateKeyPEM() (string, error) {
-	keyBytes, err := kp.GetPrivateKeyBytes()
+	var keyBytes []byte
+	var err error
+	switch kp.Algorithm {
+	case "ED25519":
+		// Ed25519的PEM为PKCS#8（RFC 8410）
+		keyBytes, err = x509.MarshalPKCS8PrivateKey(kp.PrivateKey)
+	case AlgorithmECDSA, AlgorithmSecp256k1, AlgorithmP256, AlgorithmP384:
+		// 椭圆曲线私钥PEM为SEC1 ECPrivateKey
+		var privKey *ecdsa.PrivateKey
+		if privKey, err = ToECDSAPrivateKey(kp.PrivateKey); err == nil {
+			keyBytes, err = MarshalECPrivateKey(privKey)
+		}
+	default:
+		keyBytes, err = kp.GetPrivateKeyBytes()
+	}
 	if err != nil {
 		return "", err
 	}
 
 	var blockType string
 	switch kp.Algorithm {
-	case "ECDSA":
+	case AlgorithmECDSA, AlgorithmSecp256k1, AlgorithmP256, AlgorithmP384:
 		blockType = "EC PRIVATE KEY"
 	case "RSA":
 		blockType = "RSA PRIVATE KEY"
-	case "SM2":
-		blockType = "EC PRIVATE KEY"
+	case "SM2", "ED25519":
+		blockType = "PRIVATE KEY"
 	default:
 		return "", fmt.Errorf("unsupported algorithm: %s", kp.Algorithm)
 	}
@@ -0,0 +1,557 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+// keyInput 解码后的密钥输入
+type keyInput struct {
+	der     []byte
+	pemType string
+	jwk     *jsonWebKey
+}
+
+// jsonWebKey 用于解析JWK（RFC 7517 / RFC 7518 / RFC 8037）
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	D   string `json:"d,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	P   string `json:"p,omitempty"`
+	Q   string `json:"q,omitempty"`
+}
+
+// ParsePrivateKey 解析私钥并返回*KeyPair，自动识别以下格式：
+// PEM（PKCS#1、PKCS#8、SEC1）、DER、十六进制字符串和JWK
+// algorithm为可选的算法提示，非空时须与解析出的密钥一致（ECDSA与SECP256K1兼容）；
+// 原始私钥字节（如GetPrivateKeyHex的输出）无法自动识别算法，必须提供algorithm
+func ParsePrivateKey(data []byte, algorithm string) (*KeyPair, error) {
+	in, err := decodeKeyInput(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var priv interface{}
+	switch {
+	case in.jwk != nil:
+		priv, err = parseJWKPrivateKey(in.jwk)
+	default:
+		priv, err = parsePrivateKeyDER(in.der, in.pemType)
+		if err != nil && in.pemType == "" && algorithm != "" {
+			priv, err = parseRawPrivateKey(in.der, algorithm)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newKeyPairFromPrivateKey(priv, algorithm)
+}
+
+// ParsePublicKey 解析公钥并返回仅包含公钥的*KeyPair，自动识别以下格式：
+// PEM（SPKI、PKCS#1）、DER、十六进制字符串和JWK（含私钥的JWK仅取公钥部分）
+// algorithm为可选的算法提示，原始公钥字节（压缩/未压缩点、Ed25519原始公钥）必须提供algorithm
+func ParsePublicKey(data []byte, algorithm string) (*KeyPair, error) {
+	in, err := decodeKeyInput(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var pub interface{}
+	switch {
+	case in.jwk != nil:
+		pub, err = parseJWKPublicKey(in.jwk)
+	default:
+		pub, err = parsePublicKeyDER(in.der, in.pemType)
+		if err != nil && in.pemType == "" && algorithm != "" {
+			pub, err = parseRawPublicKey(in.der, algorithm)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newKeyPairFromPublicKey(pub, algorithm)
+}
+
+// decodeKeyInput 识别PEM、JWK、十六进制或二进制输入
+func decodeKeyInput(data []byte) (*keyInput, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, errors.New("key data cannot be empty")
+	}
+
+	if bytes.HasPrefix(trimmed, []byte("-----BEGIN")) {
+		rest := trimmed
+		for {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				return nil, errors.New("no key found in PEM data")
+			}
+			// OpenSSL生成的EC私钥可能带有EC PARAMETERS块
+			if block.Type == "EC PARAMETERS" {
+				continue
+			}
+			if block.Type == "ENCRYPTED PRIVATE KEY" || strings.Contains(block.Headers["Proc-Type"], "ENCRYPTED") {
+				return nil, errors.New("encrypted PEM keys are not supported")
+			}
+			return &keyInput{der: block.Bytes, pemType: block.Type}, nil
+		}
+	}
+
+	if trimmed[0] == '{' {
+		var jwk jsonWebKey
+		if err := json.Unmarshal(trimmed, &jwk); err != nil {
+			return nil, fmt.Errorf("invalid JWK: %w", err)
+		}
+		return &keyInput{jwk: &jwk}, nil
+	}
+
+	if s := strings.TrimPrefix(strings.TrimPrefix(string(trimmed), "0x"), "0X"); isHexString(s) {
+		der, err := hex.DecodeString(s)
+		if err == nil {
+			return &keyInput{der: der}, nil
+		}
+	}
+
+	return &keyInput{der: data}, nil
+}
+
+// isHexString 判断是否为偶数长度的十六进制字符串
+func isHexString(s string) bool {
+	if len(s) == 0 || len(s)%2 != 0 {
+		return false
+	}
+	for _, c := range s {
+		if !((c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')) {
+			return false
+		}
+	}
+	return true
+}
+
+// parsePrivateKeyDER 按PEM类型解析DER私钥，pemType为空时依次尝试PKCS#8、PKCS#1、SEC1
+func parsePrivateKeyDER(der []byte, pemType string) (interface{}, error) {
+	switch pemType {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(der)
+	case "EC PRIVATE KEY":
+		return parseSEC1PrivateKey(der)
+	case "PRIVATE KEY":
+		return parsePKCS8PrivateKey(der)
+	case "":
+		if key, err := parsePKCS8PrivateKey(der); err == nil {
+			return key, nil
+		}
+		if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+			return key, nil
+		}
+		if key, err := parseSEC1PrivateKey(der); err == nil {
+			return key, nil
+		}
+		return nil, errors.New("unrecognized private key format")
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type: %s", pemType)
+	}
+}
+
+// parsePKCS8PrivateKey 解析PKCS#8私钥，SM2与secp256k1由本包解析
+func parsePKCS8PrivateKey(der []byte) (interface{}, error) {
+	var p8 ecPKCS8
+	if rest, err := asn1.Unmarshal(der, &p8); err == nil && len(rest) == 0 && p8.Algorithm.Algorithm.Equal(oidPublicKeyECDSA) {
+		switch {
+		case p8.Algorithm.Parameters.Equal(oidNamedCurveSM2):
+			return ParseSM2PrivateKey(der)
+		case p8.Algorithm.Parameters.Equal(oidNamedCurveSecp256k1):
+			return ParseECPrivateKey(p8.PrivateKey)
+		}
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PKCS#8 private key: %w", err)
+	}
+	if ecKey, ok := key.(*ecdsa.PrivateKey); ok {
+		if _, err := ECAlgorithmForCurve(ecKey.Curve); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// parseSEC1PrivateKey 解析SEC1 ECPrivateKey，支持SM2、secp256k1、P-256、P-384
+func parseSEC1PrivateKey(der []byte) (interface{}, error) {
+	var ecKey ecPrivateKeyInfo
+	if _, err := asn1.Unmarshal(der, &ecKey); err == nil && ecKey.NamedCurveOID.Equal(oidNamedCurveSM2) {
+		return ParseSM2PrivateKey(der)
+	}
+	return ParseECPrivateKey(der)
+}
+
+// parseRawPrivateKey 按算法解析原始私钥字节
+func parseRawPrivateKey(raw []byte, algorithm string) (interface{}, error) {
+	switch NormalizeECAlgorithm(algorithm) {
+	case AlgorithmSecp256k1, AlgorithmP256, AlgorithmP384:
+		curve, _ := ECCurve(algorithm)
+		if len(raw) != curveByteSize(curve) {
+			return nil, fmt.Errorf("invalid %s private key length: %d", algorithm, len(raw))
+		}
+		return newECPrivateKey(curve, raw)
+	case "SM2":
+		if len(raw) != 32 {
+			return nil, fmt.Errorf("invalid SM2 private key length: %d", len(raw))
+		}
+		return NewSM2PrivateKey(raw)
+	case "ED25519":
+		return parseEd25519PrivateKey(raw)
+	default:
+		return nil, fmt.Errorf("unrecognized private key format for algorithm: %s", algorithm)
+	}
+}
+
+// newECPrivateKey 由原始标量构造椭圆曲线私钥
+func newECPrivateKey(curve elliptic.Curve, d []byte) (*ecdsa.PrivateKey, error) {
+	k := new(big.Int).SetBytes(d)
+	if k.Sign() == 0 || k.Cmp(curve.Params().N) >= 0 {
+		return nil, errors.New("invalid EC private key scalar")
+	}
+	priv := &ecdsa.PrivateKey{D: k}
+	priv.Curve = curve
+	priv.X, priv.Y = curve.ScalarBaseMult(d)
+	return priv, nil
+}
+
+// parseEd25519PrivateKey 解析PKCS#8（DER）、32字节种子或64字节格式的Ed25519私钥
+func parseEd25519PrivateKey(der []byte) (ed25519.PrivateKey, error) {
+	switch len(der) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(der), nil
+	case ed25519.PrivateKeySize:
+		priv := ed25519.NewKeyFromSeed(der[:ed25519.SeedSize])
+		if !bytes.Equal(priv[ed25519.SeedSize:], der[ed25519.SeedSize:]) {
+			return nil, errors.New("Ed25519 private key does not match its public key")
+		}
+		return priv, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Ed25519 private key: %w", err)
+	}
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("not an Ed25519 private key: %T", key)
+	}
+	return priv, nil
+}
+
+// parsePublicKeyDER 按PEM类型解析DER公钥，pemType为空时依次尝试SPKI、PKCS#1
+func parsePublicKeyDER(der []byte, pemType string) (interface{}, error) {
+	switch pemType {
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(der)
+	case "PUBLIC KEY":
+		return parseSPKIPublicKey(der)
+	case "":
+		if key, err := parseSPKIPublicKey(der); err == nil {
+			return key, nil
+		}
+		if key, err := x509.ParsePKCS1PublicKey(der); err == nil {
+			return key, nil
+		}
+		return nil, errors.New("unrecognized public key format")
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type: %s", pemType)
+	}
+}
+
+// parseSPKIPublicKey 解析SubjectPublicKeyInfo，SM2与secp256k1由本包解析
+func parseSPKIPublicKey(der []byte) (interface{}, error) {
+	var spki ecSubjectPublicKeyInfo
+	if rest, err := asn1.Unmarshal(der, &spki); err == nil && len(rest) == 0 && spki.Algorithm.Algorithm.Equal(oidPublicKeyECDSA) {
+		switch {
+		case spki.Algorithm.Parameters.Equal(oidNamedCurveSM2):
+			return ParseSM2PublicKey(der)
+		case spki.Algorithm.Parameters.Equal(oidNamedCurveSecp256k1):
+			return ParseECPublicKey(der)
+		}
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	if ecKey, ok := key.(*ecdsa.PublicKey); ok {
+		if _, err := ECAlgorithmForCurve(ecKey.Curve); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// parseRawPublicKey 按算法解析原始公钥字节
+func parseRawPublicKey(raw []byte, algorithm string) (interface{}, error) {
+	switch NormalizeECAlgorithm(algorithm) {
+	case AlgorithmSecp256k1, AlgorithmP256, AlgorithmP384:
+		curve, _ := ECCurve(algorithm)
+		return ParseECPoint(curve, raw)
+	case "SM2":
+		return ParseSM2PublicKey(raw)
+	case "ED25519":
+		if len(raw) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 public key length: %d", len(raw))
+		}
+		return ed25519.PublicKey(append([]byte(nil), raw...)), nil
+	default:
+		return nil, fmt.Errorf("unrecognized public key format for algorithm: %s", algorithm)
+	}
+}
+
+// jwkCurve 返回JWK crv对应的椭圆曲线
+func jwkCurve(crv string) (elliptic.Curve, error) {
+	switch crv {
+	case "P-256":
+		return elliptic.P256(), nil
+	case "P-384":
+		return elliptic.P384(), nil
+	case "secp256k1":
+		return btcec.S256(), nil
+	case "SM2":
+		return SM2Curve(), nil
+	default:
+		return nil, fmt.Errorf("unsupported JWK curve: %s", crv)
+	}
+}
+
+// jwkBytes 解码JWK中的base64url字段
+func jwkBytes(name, value string) ([]byte, error) {
+	if value == "" {
+		return nil, fmt.Errorf("JWK is missing %q", name)
+	}
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWK %q: %w", name, err)
+	}
+	return b, nil
+}
+
+// parseJWKPublicKey 解析JWK中的公钥
+func parseJWKPublicKey(jwk *jsonWebKey) (interface{}, error) {
+	switch jwk.Kty {
+	case "EC":
+		curve, err := jwkCurve(jwk.Crv)
+		if err != nil {
+			return nil, err
+		}
+		x, err := jwkBytes("x", jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := jwkBytes("y", jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		size := curveByteSize(curve)
+		if len(x) != size || len(y) != size {
+			return nil, fmt.Errorf("invalid JWK coordinate length for %s", jwk.Crv)
+		}
+		point := append(append([]byte{4}, x...), y...)
+		if jwk.Crv == "SM2" {
+			return ParseSM2PublicKey(point)
+		}
+		return ParseECPoint(curve, point)
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported JWK curve: %s", jwk.Crv)
+		}
+		x, err := jwkBytes("x", jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 public key length: %d", len(x))
+		}
+		return ed25519.PublicKey(x), nil
+	case "RSA":
+		n, err := jwkBytes("n", jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := jwkBytes("e", jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		eInt := new(big.Int).SetBytes(e)
+		if !eInt.IsInt64() || eInt.Int64() < 3 || eInt.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA public exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(eInt.Int64())}, nil
+	default:
+		return nil, fmt.Errorf("unsupported JWK key type: %s", jwk.Kty)
+	}
+}
+
+// parseJWKPrivateKey 解析JWK中的私钥，并校验私钥与公钥一致
+func parseJWKPrivateKey(jwk *jsonWebKey) (interface{}, error) {
+	if jwk.D == "" {
+		return nil, errors.New("JWK does not contain a private key")
+	}
+	pub, err := parseJWKPublicKey(jwk)
+	if err != nil {
+		return nil, err
+	}
+	d, err := jwkBytes("d", jwk.D)
+	if err != nil {
+		return nil, err
+	}
+
+	switch p := pub.(type) {
+	case *ecdsa.PublicKey:
+		priv, err := newECPrivateKey(p.Curve, d)
+		if err != nil {
+			return nil, err
+		}
+		if priv.X.Cmp(p.X) != 0 || priv.Y.Cmp(p.Y) != 0 {
+			return nil, errors.New("JWK private key does not match its public key")
+		}
+		return priv, nil
+	case *sm2.PublicKey:
+		priv, err := NewSM2PrivateKey(d)
+		if err != nil {
+			return nil, err
+		}
+		if priv.X.Cmp(p.X) != 0 || priv.Y.Cmp(p.Y) != 0 {
+			return nil, errors.New("JWK private key does not match its public key")
+		}
+		return priv, nil
+	case ed25519.PublicKey:
+		if len(d) != ed25519.SeedSize {
+			return nil, fmt.Errorf("invalid Ed25519 private key length: %d", len(d))
+		}
+		priv := ed25519.NewKeyFromSeed(d)
+		if !bytes.Equal(priv.Public().(ed25519.PublicKey), p) {
+			return nil, errors.New("JWK private key does not match its public key")
+		}
+		return priv, nil
+	case *rsa.PublicKey:
+		pb, err := jwkBytes("p", jwk.P)
+		if err != nil {
+			return nil, err
+		}
+		qb, err := jwkBytes("q", jwk.Q)
+		if err != nil {
+			return nil, err
+		}
+		priv := &rsa.PrivateKey{
+			PublicKey: *p,
+			D:         new(big.Int).SetBytes(d),
+			Primes:    []*big.Int{new(big.Int).SetBytes(pb), new(big.Int).SetBytes(qb)},
+		}
+		if err := priv.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid RSA JWK: %w", err)
+		}
+		priv.Precompute()
+		return priv, nil
+	default:
+		return nil, fmt.Errorf("unsupported JWK key type: %s", jwk.Kty)
+	}
+}
+
+// keyAlgorithm 返回私钥或公钥对应的算法标识
+func keyAlgorithm(key interface{}) (string, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey, *rsa.PublicKey:
+		return "RSA", nil
+	case *sm2.PrivateKey, *sm2.PublicKey:
+		return "SM2", nil
+	case ed25519.PrivateKey, ed25519.PublicKey:
+		return "ED25519", nil
+	case *btcec.PrivateKey, *btcec.PublicKey:
+		return AlgorithmSecp256k1, nil
+	case *ecdsa.PrivateKey:
+		return ECAlgorithmForCurve(k.Curve)
+	case *ecdsa.PublicKey:
+		return ECAlgorithmForCurve(k.Curve)
+	default:
+		return "", fmt.Errorf("unsupported key type: %T", key)
+	}
+}
+
+// resolveAlgorithm 校验算法提示与密钥一致，提示为ECDSA时保留历史标识
+func resolveAlgorithm(detected, hint string) (string, error) {
+	if hint == "" {
+		return detected, nil
+	}
+	if NormalizeECAlgorithm(hint) != detected {
+		return "", fmt.Errorf("key algorithm %s does not match expected algorithm %s", detected, hint)
+	}
+	return hint, nil
+}
+
+// newKeyPairFromPrivateKey 由私钥构造*KeyPair，secp256k1密钥统一使用btcec类型
+func newKeyPairFromPrivateKey(priv interface{}, hint string) (*KeyPair, error) {
+	detected, err := keyAlgorithm(priv)
+	if err != nil {
+		return nil, err
+	}
+	algorithm, err := resolveAlgorithm(detected, hint)
+	if err != nil {
+		return nil, err
+	}
+
+	kp := &KeyPair{Algorithm: algorithm}
+	switch k := priv.(type) {
+	case *rsa.PrivateKey:
+		kp.PrivateKey, kp.PublicKey = k, &k.PublicKey
+	case *sm2.PrivateKey:
+		kp.PrivateKey, kp.PublicKey = k, &k.PublicKey
+	case ed25519.PrivateKey:
+		kp.PrivateKey, kp.PublicKey = k, k.Public()
+	case *ecdsa.PrivateKey:
+		if detected == AlgorithmSecp256k1 {
+			btcKey, _ := btcec.PrivKeyFromBytes(k.D.FillBytes(make([]byte, 32)))
+			kp.PrivateKey, kp.PublicKey = btcKey, btcKey.PubKey()
+		} else {
+			kp.PrivateKey, kp.PublicKey = k, &k.PublicKey
+		}
+	}
+	return kp, nil
+}
+
+// newKeyPairFromPublicKey 由公钥构造仅包含公钥的*KeyPair
+func newKeyPairFromPublicKey(pub interface{}, hint string) (*KeyPair, error) {
+	detected, err := keyAlgorithm(pub)
+	if err != nil {
+		return nil, err
+	}
+	algorithm, err := resolveAlgorithm(detected, hint)
+	if err != nil {
+		return nil, err
+	}
+
+	kp := &KeyPair{Algorithm: algorithm, PublicKey: pub}
+	if k, ok := pub.(*ecdsa.PublicKey); ok && detected == AlgorithmSecp256k1 {
+		btcKey, err := btcec.ParsePubKey(elliptic.Marshal(k.Curve, k.X, k.Y))
+		if err != nil {
+			return nil, fmt.Errorf("invalid secp256k1 public key: %w", err)
+		}
+		kp.PublicKey = btcKey
+	}
+	return kp, nil
+}
@@ -0,0 +1,735 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// scrypt参数
+const (
+	// StandardScryptN 默认scrypt N参数，派生一次约占用256MB内存
+	StandardScryptN = 1 << 18
+	// StandardScryptP 默认scrypt P参数
+	StandardScryptP = 1
+	// LightScryptN 轻量scrypt N参数，适用于测试及资源受限设备
+	LightScryptN = 1 << 12
+	// LightScryptP 轻量scrypt P参数
+	LightScryptP = 6
+
+	scryptR = 8
+	// maxScryptN 读取密钥文件时允许的最大N，防止恶意文件耗尽内存
+	maxScryptN = 1 << 22
+)
+
+// 文件Keystore格式
+const (
+	fileKeystoreVersion = 1
+	fileKDFScrypt       = "scrypt"
+	fileCipherAES256GCM = "aes-256-gcm"
+	fileKeySuffix       = ".json"
+	// fileKeystoreCheckName 口令校验文件，保存用口令加密的固定内容
+	fileKeystoreCheckName = ".keystore.json"
+	fileKeystoreLockName  = ".lock"
+	fileKeystoreCheckText = "sbp-did-sdk-go file keystore"
+)
+
+var (
+	// ErrKeystoreLocked Keystore处于锁定状态
+	ErrKeystoreLocked = errors.New("keystore is locked")
+	// ErrWrongPassword Keystore口令错误
+	ErrWrongPassword = errors.New("wrong keystore password")
+)
+
+// fileKeyJSON 密钥文件格式，参考以太坊keystore v3
+// 公钥与元数据明文保存，私钥以口令派生密钥加密，AAD绑定id、类型、公钥与用途
+type fileKeyJSON struct {
+	Version   int            `json:"version"`
+	ID        string         `json:"id"`
+	KeyType   KeyType        `json:"keyType"`
+	PublicKey string         `json:"publicKey,omitempty"`
+	Purpose   string         `json:"purpose,omitempty"`
+	CreatedAt time.Time      `json:"createdAt"`
+	Crypto    fileCryptoJSON `json:"crypto"`
+}
+
+// fileCheckJSON 口令校验文件格式
+type fileCheckJSON struct {
+	Version int            `json:"version"`
+	Crypto  fileCryptoJSON `json:"crypto"`
+}
+
+type fileCryptoJSON struct {
+	Cipher       string           `json:"cipher"`
+	CipherText   string           `json:"ciphertext"`
+	CipherParams fileCipherParams `json:"cipherparams"`
+	KDF          string           `json:"kdf"`
+	KDFParams    fileScryptParams `json:"kdfparams"`
+}
+
+type fileCipherParams struct {
+	Nonce string `json:"nonce"`
+}
+
+type fileScryptParams struct {
+	N     int    `json:"n"`
+	R     int    `json:"r"`
+	P     int    `json:"p"`
+	DKLen int    `json:"dklen"`
+	Salt  string `json:"salt"`
+}
+
+// FileKeyMetadata 密钥元数据，锁定状态下也可读取
+type FileKeyMetadata struct {
+	KeyID     string
+	KeyType   KeyType
+	Purpose   string
+	CreatedAt time.Time
+}
+
+// FileKeyManagerOption 文件Keystore配置选项
+type FileKeyManagerOption func(*fileKeyManagerOptions)
+
+type fileKeyManagerOptions struct {
+	scryptN int
+	scryptP int
+}
+
+// WithScryptParams 设置新写入密钥文件使用的scrypt参数N、P（r固定为8）
+// 已有文件按其自身记录的参数解密，修改口令时以新参数重新加密
+func WithScryptParams(n, p int) FileKeyManagerOption {
+	return func(o *fileKeyManagerOptions) {
+		o.scryptN = n
+		o.scryptP = p
+	}
+}
+
+// FileKeyManager 基于加密文件的持久化KeyManager和Crypto实现
+// 每个密钥保存为 <dir>/<keyID>.json，私钥以scrypt派生密钥经AES-256-GCM加密
+// 新建实例处于锁定状态，仅可Get、List与读取元数据；Unlock后解密的私钥缓存在内存中，Lock时丢弃
+// 写操作通过原子替换与目录锁文件保证多进程共享同一目录时的一致性
+type FileKeyManager struct {
+	dir  string
+	opts fileKeyManagerOptions
+
+	mu       sync.RWMutex
+	unlocked bool
+	password string
+	checkSum [sha256.Size]byte // Unlock时口令校验文件的摘要，用于发现其他进程修改口令
+	keys     *LocalKeyManager  // 已解密私钥缓存
+}
+
+// NewFileKeyManager 创建文件Keystore，目录不存在时自动创建
+func NewFileKeyManager(dir string, opts ...FileKeyManagerOption) (*FileKeyManager, error) {
+	o := fileKeyManagerOptions{scryptN: StandardScryptN, scryptP: StandardScryptP}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.scryptN <= 1 || o.scryptN&(o.scryptN-1) != 0 || o.scryptN > maxScryptN {
+		return nil, fmt.Errorf("invalid scrypt N: %d", o.scryptN)
+	}
+	if o.scryptP <= 0 {
+		return nil, fmt.Errorf("invalid scrypt P: %d", o.scryptP)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("create keystore directory: %w", err)
+	}
+	return &FileKeyManager{
+		dir:  dir,
+		opts: o,
+		keys: NewLocalKeyManager(),
+	}, nil
+}
+
+// Unlock 使用口令解锁Keystore
+// 目录中尚无口令校验文件时以该口令初始化Keystore
+func (f *FileKeyManager) Unlock(password string) error {
+	if password == "" {
+		return errors.New("keystore password must not be empty")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	release, err := lockFile(f.path(fileKeystoreLockName))
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	data, err := os.ReadFile(f.path(fileKeystoreCheckName))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		if data, err = f.writeCheck(password); err != nil {
+			return err
+		}
+	case err != nil:
+		return fmt.Errorf("read keystore: %w", err)
+	default:
+		if err = verifyCheck(data, password); err != nil {
+			return err
+		}
+	}
+
+	f.unlocked = true
+	f.password = password
+	f.checkSum = sha256.Sum256(data)
+	f.keys = NewLocalKeyManager()
+	return nil
+}
+
+// Lock 锁定Keystore，丢弃内存中的口令与已解密私钥
+func (f *FileKeyManager) Lock() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.unlocked = false
+	f.password = ""
+	f.keys = NewLocalKeyManager()
+}
+
+// Locked 判断Keystore是否处于锁定状态
+func (f *FileKeyManager) Locked() bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return !f.unlocked
+}
+
+// ChangePassword 修改Keystore口令，使用新口令重新加密全部密钥文件
+// 每个文件单独原子替换，口令校验文件最后更新；中途失败时以相同参数重试即可继续
+func (f *FileKeyManager) ChangePassword(oldPassword, newPassword string) error {
+	if newPassword == "" {
+		return errors.New("keystore password must not be empty")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	release, err := lockFile(f.path(fileKeystoreLockName))
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	data, err := os.ReadFile(f.path(fileKeystoreCheckName))
+	if errors.Is(err, os.ErrNotExist) {
+		return errors.New("keystore is not initialized")
+	}
+	if err != nil {
+		return fmt.Errorf("read keystore: %w", err)
+	}
+	if err = verifyCheck(data, oldPassword); err != nil {
+		return err
+	}
+
+	ids, err := f.List()
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		k, err := f.readKey(id)
+		if err != nil {
+			return err
+		}
+		secret, err := decryptFileSecret(k.Crypto, oldPassword, fileKeyAAD(k))
+		if errors.Is(err, ErrWrongPassword) {
+			// 上次修改中断时已使用新口令加密
+			if _, err2 := decryptFileSecret(k.Crypto, newPassword, fileKeyAAD(k)); err2 == nil {
+				continue
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("key %s: %w", id, err)
+		}
+		k.Crypto, err = f.encryptFileSecret(secret, newPassword, fileKeyAAD(k))
+		wipeBytes(secret)
+		if err != nil {
+			return err
+		}
+		if err = f.writeJSON(f.keyPath(id), k); err != nil {
+			return err
+		}
+	}
+
+	if data, err = f.writeCheck(newPassword); err != nil {
+		return err
+	}
+	if f.unlocked {
+		f.password = newPassword
+		f.checkSum = sha256.Sum256(data)
+	}
+	return nil
+}
+
+// Create 创建新密钥并加密写入文件，返回 keyID 和公钥
+func (f *FileKeyManager) Create(keyType KeyType, opts ...KeyOpts) (string, []byte, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if !f.unlocked {
+		return "", nil, ErrKeystoreLocked
+	}
+	keyID, pubKey, err := f.keys.Create(keyType, opts...)
+	if err != nil {
+		return "", nil, err
+	}
+	if err = f.persist(keyID, keyType, pubKey, keyPurpose(opts)); err != nil {
+		f.keys.Delete(keyID)
+		return "", nil, err
+	}
+	return keyID, pubKey, nil
+}
+
+// Get 获取公钥，锁定状态下可用
+func (f *FileKeyManager) Get(keyID string) ([]byte, error) {
+	k, err := f.readKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	if k.PublicKey == "" {
+		return nil, errors.New("symmetric key has no public key")
+	}
+	return hex.DecodeString(k.PublicKey)
+}
+
+// Metadata 获取密钥元数据，锁定状态下可用
+func (f *FileKeyManager) Metadata(keyID string) (*FileKeyMetadata, error) {
+	k, err := f.readKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return &FileKeyMetadata{
+		KeyID:     k.ID,
+		KeyType:   k.KeyType,
+		Purpose:   k.Purpose,
+		CreatedAt: k.CreatedAt,
+	}, nil
+}
+
+// ImportPrivateKey 导入私钥并加密写入文件，返回 keyID
+// 支持的格式与LocalKeyManager.ImportPrivateKey相同
+func (f *FileKeyManager) ImportPrivateKey(privKey []byte, keyType KeyType, opts ...KeyOpts) (string, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if !f.unlocked {
+		return "", ErrKeystoreLocked
+	}
+	keyID, err := f.keys.ImportPrivateKey(privKey, keyType, opts...)
+	if err != nil {
+		return "", err
+	}
+	var pubKey []byte
+	if keyType != AES256 {
+		if pubKey, err = f.keys.Get(keyID); err != nil {
+			f.keys.Delete(keyID)
+			return "", err
+		}
+	}
+	if err = f.persist(keyID, keyType, pubKey, keyPurpose(opts)); err != nil {
+		f.keys.Delete(keyID)
+		return "", err
+	}
+	return keyID, nil
+}
+
+// ExportPrivateKey 导出私钥
+func (f *FileKeyManager) ExportPrivateKey(keyID string) ([]byte, error) {
+	keys, err := f.load(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return keys.ExportPrivateKey(keyID)
+}
+
+// Delete 删除密钥文件
+func (f *FileKeyManager) Delete(keyID string) error {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if !f.unlocked {
+		return ErrKeystoreLocked
+	}
+	if !validFileKeyID(keyID) {
+		return fmt.Errorf("invalid key id: %q", keyID)
+	}
+
+	release, err := lockFile(f.path(fileKeystoreLockName))
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if err = os.Remove(f.keyPath(keyID)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return errors.New("key not found")
+		}
+		return fmt.Errorf("delete key file: %w", err)
+	}
+	f.keys.Delete(keyID)
+	return nil
+}
+
+// List 列出所有 keyID，锁定状态下可用
+func (f *FileKeyManager) List() ([]string, error) {
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return nil, fmt.Errorf("read keystore directory: %w", err)
+	}
+	ids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, fileKeySuffix) {
+			continue
+		}
+		if id := strings.TrimSuffix(name, fileKeySuffix); validFileKeyID(id) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Sign 使用指定密钥签名，签名约定同LocalKeyManager.Sign
+func (f *FileKeyManager) Sign(keyID string, data []byte) ([]byte, error) {
+	keys, err := f.load(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return keys.Sign(keyID, data)
+}
+
+// Verify 验证签名
+func (f *FileKeyManager) Verify(keyID string, data, signature []byte) (bool, error) {
+	keys, err := f.load(keyID)
+	if err != nil {
+		return false, err
+	}
+	return keys.Verify(keyID, data, signature)
+}
+
+// SignRSADigest 使用RSA密钥对hash算法计算的摘要进行PKCS#1 v1.5签名
+func (f *FileKeyManager) SignRSADigest(keyID string, hash crypto.Hash, digest []byte) ([]byte, error) {
+	keys, err := f.load(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return keys.SignRSADigest(keyID, hash, digest)
+}
+
+// VerifyRSADigest 验证SignRSADigest生成的签名
+func (f *FileKeyManager) VerifyRSADigest(keyID string, hash crypto.Hash, digest, signature []byte) (bool, error) {
+	keys, err := f.load(keyID)
+	if err != nil {
+		return false, err
+	}
+	return keys.VerifyRSADigest(keyID, hash, digest, signature)
+}
+
+// Encrypt 加密，信封格式同LocalKeyManager.Encrypt
+func (f *FileKeyManager) Encrypt(keyID string, plaintext []byte) ([]byte, error) {
+	keys, err := f.load(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return keys.Encrypt(keyID, plaintext)
+}
+
+// Decrypt 解密
+func (f *FileKeyManager) Decrypt(keyID string, ciphertext []byte) ([]byte, error) {
+	keys, err := f.load(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return keys.Decrypt(keyID, ciphertext)
+}
+
+// load 确保密钥已解密进缓存，返回缓存
+// 每次都检查文件是否存在，以感知其他进程删除的密钥
+func (f *FileKeyManager) load(keyID string) (*LocalKeyManager, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if !f.unlocked {
+		return nil, ErrKeystoreLocked
+	}
+	if !validFileKeyID(keyID) {
+		return nil, fmt.Errorf("invalid key id: %q", keyID)
+	}
+	if f.keys.has(keyID) {
+		if _, err := os.Stat(f.keyPath(keyID)); err != nil {
+			f.keys.Delete(keyID)
+			if errors.Is(err, os.ErrNotExist) {
+				return nil, errors.New("key not found")
+			}
+			return nil, err
+		}
+		return f.keys, nil
+	}
+
+	k, err := f.readKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := decryptFileSecret(k.Crypto, f.password, fileKeyAAD(k))
+	if err != nil {
+		return nil, err
+	}
+	defer wipeBytes(secret)
+	priv, err := parseLocalPrivateKey(secret, k.KeyType)
+	if err != nil {
+		return nil, fmt.Errorf("parse key %s: %w", keyID, err)
+	}
+	f.keys.put(keyID, k.KeyType, priv)
+	return f.keys, nil
+}
+
+// persist 将缓存中的私钥加密写入密钥文件，调用方需持有f.mu读锁
+func (f *FileKeyManager) persist(keyID string, keyType KeyType, pubKey []byte, purpose string) error {
+	secret, err := f.keys.ExportPrivateKey(keyID)
+	if err != nil {
+		return err
+	}
+	defer wipeBytes(secret)
+
+	k := &fileKeyJSON{
+		Version:   fileKeystoreVersion,
+		ID:        keyID,
+		KeyType:   keyType,
+		PublicKey: hex.EncodeToString(pubKey),
+		Purpose:   purpose,
+		CreatedAt: time.Now().UTC(),
+	}
+	if k.Crypto, err = f.encryptFileSecret(secret, f.password, fileKeyAAD(k)); err != nil {
+		return err
+	}
+
+	release, err := lockFile(f.path(fileKeystoreLockName))
+	if err != nil {
+		return err
+	}
+	defer release()
+	if err = f.checkPassword(); err != nil {
+		return err
+	}
+	return f.writeJSON(f.keyPath(keyID), k)
+}
+
+// checkPassword 确认口令未被其他进程修改，调用方需持有目录锁
+func (f *FileKeyManager) checkPassword() error {
+	data, err := os.ReadFile(f.path(fileKeystoreCheckName))
+	if err != nil {
+		return fmt.Errorf("read keystore: %w", err)
+	}
+	if sha256.Sum256(data) == f.checkSum {
+		return nil
+	}
+	return verifyCheck(data, f.password)
+}
+
+// writeCheck 以口令生成并写入口令校验文件，返回写入内容
+func (f *FileKeyManager) writeCheck(password string) ([]byte, error) {
+	c, err := f.encryptFileSecret([]byte(fileKeystoreCheckText), password, nil)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(&fileCheckJSON{Version: fileKeystoreVersion, Crypto: c})
+	if err != nil {
+		return nil, err
+	}
+	if err = writeFileAtomic(f.path(fileKeystoreCheckName), data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// verifyCheck 使用口令校验文件验证口令
+func verifyCheck(data []byte, password string) error {
+	var c fileCheckJSON
+	if err := json.Unmarshal(data, &c); err != nil {
+		return fmt.Errorf("invalid keystore check file: %w", err)
+	}
+	text, err := decryptFileSecret(c.Crypto, password, nil)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(text, []byte(fileKeystoreCheckText)) {
+		return errors.New("invalid keystore check file")
+	}
+	return nil
+}
+
+// readKey 读取并解析密钥文件
+func (f *FileKeyManager) readKey(keyID string) (*fileKeyJSON, error) {
+	if !validFileKeyID(keyID) {
+		return nil, fmt.Errorf("invalid key id: %q", keyID)
+	}
+	data, err := os.ReadFile(f.keyPath(keyID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errors.New("key not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read key file: %w", err)
+	}
+	var k fileKeyJSON
+	if err = json.Unmarshal(data, &k); err != nil {
+		return nil, fmt.Errorf("invalid key file %s: %w", keyID, err)
+	}
+	if k.Version != fileKeystoreVersion {
+		return nil, fmt.Errorf("unsupported key file version: %d", k.Version)
+	}
+	if k.ID != keyID {
+		return nil, fmt.Errorf("key file id mismatch: %s", k.ID)
+	}
+	return &k, nil
+}
+
+// writeJSON 序列化并原子写入文件
+func (f *FileKeyManager) writeJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+func (f *FileKeyManager) path(name string) string {
+	return filepath.Join(f.dir, name)
+}
+
+func (f *FileKeyManager) keyPath(keyID string) string {
+	return f.path(keyID + fileKeySuffix)
+}
+
+// encryptFileSecret 使用口令加密，每次生成新的salt与nonce
+func (f *FileKeyManager) encryptFileSecret(secret []byte, password string, aad []byte) (fileCryptoJSON, error) {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return fileCryptoJSON{}, err
+	}
+	params := fileScryptParams{
+		N:     f.opts.scryptN,
+		R:     scryptR,
+		P:     f.opts.scryptP,
+		DKLen: aes256KeySize,
+		Salt:  hex.EncodeToString(salt),
+	}
+	derived, err := scrypt.Key([]byte(password), salt, params.N, params.R, params.P, params.DKLen)
+	if err != nil {
+		return fileCryptoJSON{}, err
+	}
+	defer wipeBytes(derived)
+	aead, err := newAES256GCM(derived)
+	if err != nil {
+		return fileCryptoJSON{}, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return fileCryptoJSON{}, err
+	}
+	return fileCryptoJSON{
+		Cipher:       fileCipherAES256GCM,
+		CipherText:   hex.EncodeToString(aead.Seal(nil, nonce, secret, aad)),
+		CipherParams: fileCipherParams{Nonce: hex.EncodeToString(nonce)},
+		KDF:          fileKDFScrypt,
+		KDFParams:    params,
+	}, nil
+}
+
+// decryptFileSecret 使用口令解密，认证失败返回ErrWrongPassword
+func decryptFileSecret(c fileCryptoJSON, password string, aad []byte) ([]byte, error) {
+	if c.KDF != fileKDFScrypt {
+		return nil, fmt.Errorf("unsupported kdf: %s", c.KDF)
+	}
+	if c.Cipher != fileCipherAES256GCM {
+		return nil, fmt.Errorf("unsupported cipher: %s", c.Cipher)
+	}
+	p := c.KDFParams
+	if p.N <= 1 || p.N > maxScryptN || p.R != scryptR || p.P <= 0 || p.P > 64 || p.DKLen != aes256KeySize {
+		return nil, errors.New("invalid scrypt parameters")
+	}
+	salt, err := hex.DecodeString(p.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scrypt salt: %w", err)
+	}
+	nonce, err := hex.DecodeString(c.CipherParams.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nonce: %w", err)
+	}
+	ciphertext, err := hex.DecodeString(c.CipherText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ciphertext: %w", err)
+	}
+	derived, err := scrypt.Key([]byte(password), salt, p.N, p.R, p.P, p.DKLen)
+	if err != nil {
+		return nil, err
+	}
+	defer wipeBytes(derived)
+	aead, err := newAES256GCM(derived)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce length: %d", len(nonce))
+	}
+	plaintext, err := aead.Open(nil, nonce, ciphertext, aad)
+	if err != nil {
+		return nil, ErrWrongPassword
+	}
+	return plaintext, nil
+}
+
+// fileKeyAAD 密钥文件中需防篡改的明文字段
+func fileKeyAAD(k *fileKeyJSON) []byte {
+	return []byte(fmt.Sprintf("%d|%s|%s|%s|%s", k.Version, k.ID, k.KeyType, k.PublicKey, k.Purpose))
+}
+
+// validFileKeyID keyID仅允许字母、数字、'-'与'_'，避免路径穿越
+func validFileKeyID(keyID string) bool {
+	if keyID == "" || len(keyID) > 128 {
+		return false
+	}
+	for _, c := range keyID {
+		switch {
+		case c >= '0' && c <= '9', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '-', c == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// writeFileAtomic 写入同目录临时文件并同步后重命名替换目标文件
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	name := tmp.Name()
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(name, path)
+	}
+	if err != nil {
+		os.Remove(name)
+		return fmt.Errorf("write %s: %w", filepath.Base(path), err)
+	}
+	// 同步目录项，部分平台不支持对目录Sync，忽略错误
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
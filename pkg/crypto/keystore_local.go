@@ -1,20 +1,25 @@
 package crypto
 
 import (
+	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
-	"encoding/asn1"
 	"errors"
 	"fmt"
-	"math/big"
 	"sync"
 
+	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/google/uuid"
+	"github.com/tjfoc/gmsm/sm2"
 )
 
+// symmetricKey AES256对称主密钥
+type symmetricKey []byte
+
 // localKeyEntry 用于存储密钥及其类型
 type localKeyEntry struct {
 	keyType KeyType
@@ -42,19 +47,36 @@ func (l *LocalKeyManager) Create(keyType KeyType, opts ...KeyOpts) (string, []by
 	var pubBytes []byte
 	var err error
 	switch keyType {
-	case ECDSAP256:
-		priv, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case ECDSAP256, ECDSAP384, ECDSASecp256k1:
+		priv, err = ecdsa.GenerateKey(keyTypeCurve(keyType), rand.Reader)
 		if err != nil {
 			return "", nil, err
 		}
-		pubBytes, err = x509.MarshalPKIXPublicKey(&priv.(*ecdsa.PrivateKey).PublicKey)
+		pubBytes, err = MarshalECPublicKey(&priv.(*ecdsa.PrivateKey).PublicKey)
 	case RSA2048:
 		priv, err = rsa.GenerateKey(rand.Reader, 2048)
 		if err != nil {
 			return "", nil, err
 		}
 		pubBytes, err = x509.MarshalPKIXPublicKey(&priv.(*rsa.PrivateKey).PublicKey)
-	// 可扩展 Ed25519, SM2 ...
+	case SM2:
+		priv, err = GenerateSM2Key(rand.Reader)
+		if err != nil {
+			return "", nil, err
+		}
+		pubBytes, err = MarshalSM2PublicKey(&priv.(*sm2.PrivateKey).PublicKey)
+	case ED25519:
+		var pub ed25519.PublicKey
+		pub, priv, err = ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return "", nil, err
+		}
+		pubBytes, err = x509.MarshalPKIXPublicKey(pub)
+	case AES256:
+		// 对称密钥没有公钥，pubBytes为nil
+		key := make(symmetricKey, aes256KeySize)
+		_, err = rand.Read(key)
+		priv = key
 	default:
 		return "", nil, fmt.Errorf("unsupported key type: %s", keyType)
 	}
@@ -75,34 +97,72 @@ func (l *LocalKeyManager) Get(keyID string) ([]byte, error) {
 	}
 	switch k := entry.privKey.(type) {
 	case *ecdsa.PrivateKey:
-		return x509.MarshalPKIXPublicKey(&k.PublicKey)
+		return MarshalECPublicKey(&k.PublicKey)
 	case *rsa.PrivateKey:
 		return x509.MarshalPKIXPublicKey(&k.PublicKey)
+	case *sm2.PrivateKey:
+		return MarshalSM2PublicKey(&k.PublicKey)
+	case ed25519.PrivateKey:
+		return x509.MarshalPKIXPublicKey(k.Public())
+	case symmetricKey:
+		return nil, errors.New("symmetric key has no public key")
 	default:
 		return nil, errors.New("unsupported key type")
 	}
 }
 
 // ImportPrivateKey 导入私钥，返回 keyID
+// 支持ParsePrivateKey可识别的所有格式（PEM、DER、十六进制、JWK及原始私钥字节）
+// AES256密钥为32字节原始密钥
 func (l *LocalKeyManager) ImportPrivateKey(privKey []byte, keyType KeyType, opts ...KeyOpts) (string, error) {
+	priv, err := parseLocalPrivateKey(privKey, keyType)
+	if err != nil {
+		return "", err
+	}
+
 	l.mu.Lock()
 	defer l.mu.Unlock()
 	keyID := uuid.NewString()
-	var priv interface{}
-	var err error
-	switch keyType {
-	case ECDSAP256:
-		priv, err = x509.ParseECPrivateKey(privKey)
-	case RSA2048:
-		priv, err = x509.ParsePKCS1PrivateKey(privKey)
-	default:
-		return "", fmt.Errorf("unsupported key type: %s", keyType)
+	l.store[keyID] = &localKeyEntry{keyType: keyType, privKey: priv}
+	return keyID, nil
+}
+
+// put 以指定keyID保存已解析的私钥，供持久化后端复用本地签名与加解密实现
+func (l *LocalKeyManager) put(keyID string, keyType KeyType, priv interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.store[keyID] = &localKeyEntry{keyType: keyType, privKey: priv}
+}
+
+// has 判断密钥是否存在
+func (l *LocalKeyManager) has(keyID string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, ok := l.store[keyID]
+	return ok
+}
+
+// parseLocalPrivateKey 按密钥类型解析私钥，返回LocalKeyManager内部使用的私钥对象
+func parseLocalPrivateKey(privKey []byte, keyType KeyType) (interface{}, error) {
+	if keyType == AES256 {
+		if len(privKey) != aes256KeySize {
+			return nil, fmt.Errorf("invalid AES-256 key length: %d", len(privKey))
+		}
+		return append(symmetricKey(nil), privKey...), nil
 	}
+	algorithm := KeyTypeAlgorithm(keyType)
+	if algorithm == "" {
+		return nil, fmt.Errorf("unsupported key type: %s", keyType)
+	}
+	kp, err := ParsePrivateKey(privKey, algorithm)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	l.store[keyID] = &localKeyEntry{keyType: keyType, privKey: priv}
-	return keyID, nil
+	priv := kp.PrivateKey
+	if btcKey, ok := priv.(*btcec.PrivateKey); ok {
+		priv = btcKey.ToECDSA()
+	}
+	return priv, nil
 }
 
 // ExportPrivateKey 导出私钥
@@ -115,9 +175,15 @@ func (l *LocalKeyManager) ExportPrivateKey(keyID string) ([]byte, error) {
 	}
 	switch k := entry.privKey.(type) {
 	case *ecdsa.PrivateKey:
-		return x509.MarshalECPrivateKey(k)
+		return MarshalECPrivateKey(k)
 	case *rsa.PrivateKey:
 		return x509.MarshalPKCS1PrivateKey(k), nil
+	case *sm2.PrivateKey:
+		return MarshalSM2PrivateKey(k)
+	case ed25519.PrivateKey:
+		return x509.MarshalPKCS8PrivateKey(k)
+	case symmetricKey:
+		return append([]byte(nil), k...), nil
 	default:
 		return nil, errors.New("unsupported key type")
 	}
@@ -146,6 +212,7 @@ func (l *LocalKeyManager) List() ([]string, error) {
 }
 
 // Sign 使用指定 keyID 签名
+// RSA密钥传入SHA-256摘要，等同于SignRSADigest(keyID, crypto.SHA256, data)
 func (l *LocalKeyManager) Sign(keyID string, data []byte) ([]byte, error) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
@@ -156,13 +223,18 @@ func (l *LocalKeyManager) Sign(keyID string, data []byte) ([]byte, error) {
 	hash := data
 	switch k := entry.privKey.(type) {
 	case *ecdsa.PrivateKey:
-		r, s, err := ecdsa.Sign(rand.Reader, k, hash)
-		if err != nil {
+		return SignECDSA(rand.Reader, k, hash, SignatureFormatDER)
+	case *rsa.PrivateKey:
+		if err := checkRSADigest(crypto.SHA256, hash); err != nil {
 			return nil, err
 		}
-		return asn1.Marshal(struct{ R, S *big.Int }{r, s})
-	case *rsa.PrivateKey:
-		return rsa.SignPKCS1v15(rand.Reader, k, 0, hash)
+		return rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, hash)
+	case *sm2.PrivateKey:
+		// SM2对原始消息签名，摘要 SM3(ZA || M) 在签名内部计算
+		return SignSM2(rand.Reader, k, data, SM2DefaultUID)
+	case ed25519.PrivateKey:
+		// Ed25519对原始消息签名
+		return ed25519.Sign(k, data), nil
 	default:
 		return nil, errors.New("unsupported key type")
 	}
@@ -179,26 +251,119 @@ func (l *LocalKeyManager) Verify(keyID string, data, signature []byte) (bool, er
 	hash := data
 	switch k := entry.privKey.(type) {
 	case *ecdsa.PrivateKey:
-		pub := &k.PublicKey
-		var rs struct{ R, S *big.Int }
-		_, err := asn1.Unmarshal(signature, &rs)
-		if err != nil {
+		return VerifyECDSA(&k.PublicKey, hash, signature), nil
+	case *rsa.PrivateKey:
+		if err := checkRSADigest(crypto.SHA256, hash); err != nil {
 			return false, err
 		}
-		return ecdsa.Verify(pub, hash, rs.R, rs.S), nil
-	case *rsa.PrivateKey:
 		pub := &k.PublicKey
-		err := rsa.VerifyPKCS1v15(pub, 0, hash, signature)
+		err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, hash, signature)
 		return err == nil, nil
+	case *sm2.PrivateKey:
+		return VerifySM2(&k.PublicKey, data, SM2DefaultUID, signature), nil
+	case ed25519.PrivateKey:
+		if len(signature) != ed25519.SignatureSize {
+			return false, nil
+		}
+		return ed25519.Verify(k.Public().(ed25519.PublicKey), data, signature), nil
 	default:
 		return false, errors.New("unsupported key type")
 	}
 }
 
-// Encrypt/Decrypt 可按需实现
+// SignRSADigest 使用RSA密钥对hash算法计算的摘要进行PKCS#1 v1.5签名
+func (l *LocalKeyManager) SignRSADigest(keyID string, hash crypto.Hash, digest []byte) ([]byte, error) {
+	if err := checkRSADigest(hash, digest); err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry, ok := l.store[keyID]
+	if !ok {
+		return nil, errors.New("key not found")
+	}
+	k, ok := entry.privKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("key %s is not an RSA key", keyID)
+	}
+	return rsa.SignPKCS1v15(rand.Reader, k, hash, digest)
+}
+
+// VerifyRSADigest 验证SignRSADigest生成的签名
+func (l *LocalKeyManager) VerifyRSADigest(keyID string, hash crypto.Hash, digest, signature []byte) (bool, error) {
+	if err := checkRSADigest(hash, digest); err != nil {
+		return false, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry, ok := l.store[keyID]
+	if !ok {
+		return false, errors.New("key not found")
+	}
+	k, ok := entry.privKey.(*rsa.PrivateKey)
+	if !ok {
+		return false, fmt.Errorf("key %s is not an RSA key", keyID)
+	}
+	return rsa.VerifyPKCS1v15(&k.PublicKey, hash, digest, signature) == nil, nil
+}
+
+// keyTypeCurve 返回ECDSA密钥类型对应的曲线
+func keyTypeCurve(keyType KeyType) elliptic.Curve {
+	switch keyType {
+	case ECDSAP384:
+		return elliptic.P384()
+	case ECDSASecp256k1:
+		return btcec.S256()
+	default:
+		return elliptic.P256()
+	}
+}
+
+// Encrypt 使用指定 keyID 加密，密文为版本化信封
+// AES256使用AES-256-GCM，ECDSA密钥使用ECIES（secp256k1/P-256），RSA使用OAEP-SHA256，SM2使用SM2公钥加密；其他密钥不支持加密
 func (l *LocalKeyManager) Encrypt(keyID string, plaintext []byte) ([]byte, error) {
-	return nil, fmt.Errorf("not implemented")
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry, ok := l.store[keyID]
+	if !ok {
+		return nil, errors.New("key not found")
+	}
+	switch k := entry.privKey.(type) {
+	case symmetricKey:
+		return sealSymmetricEnvelope(k, SchemeAES256GCM, nil, plaintext)
+	case *ecdsa.PrivateKey:
+		return encryptWithECDSA(&k.PublicKey, plaintext)
+	case *rsa.PrivateKey:
+		return encryptWithRSA(&k.PublicKey, plaintext)
+	case *sm2.PrivateKey:
+		return encryptWithSM2(&k.PublicKey, plaintext)
+	default:
+		return nil, fmt.Errorf("encryption is not supported for key type %s", entry.keyType)
+	}
 }
+
+// Decrypt 使用指定 keyID 解密Encrypt生成的密文
 func (l *LocalKeyManager) Decrypt(keyID string, ciphertext []byte) ([]byte, error) {
-	return nil, fmt.Errorf("not implemented")
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry, ok := l.store[keyID]
+	if !ok {
+		return nil, errors.New("key not found")
+	}
+	switch k := entry.privKey.(type) {
+	case symmetricKey:
+		env, err := parseSymmetricEnvelope(ciphertext, SchemeAES256GCM)
+		if err != nil {
+			return nil, err
+		}
+		return env.open(k)
+	case *ecdsa.PrivateKey:
+		return decryptWithECDSA(k, ciphertext)
+	case *rsa.PrivateKey:
+		return decryptWithRSA(k, ciphertext)
+	case *sm2.PrivateKey:
+		return decryptWithSM2(k, ciphertext)
+	default:
+		return nil, fmt.Errorf("encryption is not supported for key type %s", entry.keyType)
+	}
 }
@@ -1,63 +1,150 @@
 package crypto
 
 import (
+	"crypto"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"fmt"
-	"github.com/huaweicloud/huaweicloud-sdk-go-v3/services/kms/v2"
-	"github.com/huaweicloud/huaweicloud-sdk-go-v3/services/kms/v2/model"
-	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/auth/basic"
 	"math/rand"
+	"strconv"
+	"sync"
+
 	"github.com/google/uuid"
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/auth/basic"
+	kms "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/kms/v2"
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/services/kms/v2/model"
 )
 
 // Aries/TrustBloc风格的华为KMS KeyManager和Crypto实现
 //
 
+// 华为KMS密钥规格
+const (
+	kmsSpecAES256    = "AES_256"
+	kmsSpecRSA2048   = "RSA_2048"
+	kmsSpecECP256    = "EC_P256"
+	kmsSpecECP384    = "EC_P384"
+	kmsSpecSecp256k1 = "SECP256K1"
+	kmsSpecSM2       = "SM2"
+)
+
+// 华为KMS密钥用途
+const (
+	kmsUsageSignVerify     = "SIGN_VERIFY"
+	kmsUsageEncryptDecrypt = "ENCRYPT_DECRYPT"
+)
+
+// kmsKeyStateEnabled 启用状态的密钥
+const kmsKeyStateEnabled = "2"
+
+// kmsDataKeyLength 数据密钥长度（字节），对应AES-256
+const kmsDataKeyLength = 32
+
+// kmsKeySpecs 密钥类型与KMS密钥规格的对应关系
+var kmsKeySpecs = map[KeyType]string{
+	AES256:         kmsSpecAES256,
+	RSA2048:        kmsSpecRSA2048,
+	ECDSAP256:      kmsSpecECP256,
+	ECDSAP384:      kmsSpecECP384,
+	ECDSASecp256k1: kmsSpecSecp256k1,
+	SM2:            kmsSpecSM2,
+}
+
+// kmsKeyInfo 缓存的KMS密钥规格与用途
+type kmsKeyInfo struct {
+	spec  string
+	usage string
+}
+
 // HuaweiKMSKeyManager 实现KeyManager和Crypto接口
 //
+// 签名密钥：RSA2048、ECDSAP256、ECDSAP384、ECDSASecp256k1、SM2（SIGN_VERIFY）
+// 加密密钥：AES256主密钥使用KMS数据密钥进行信封加密；RSA2048、SM2以WithPurpose(PurposeEncryption)
+// 创建时为ENCRYPT_DECRYPT密钥，由KMS直接加解密（仅适用于短数据）
 type HuaweiKMSKeyManager struct {
 	client    *kms.KmsClient
 	projectId string
+
+	mu   sync.Mutex
+	keys map[string]kmsKeyInfo // keyID -> kmsKeyInfo
 }
 
+// NewHuaweiKMSKeyManager 创建华为KMS KeyManager
+// endpoint形如 https://kms.cn-north-4.myhuaweicloud.com
 func NewHuaweiKMSKeyManager(endpoint, ak, sk, projectId string) (*HuaweiKMSKeyManager, error) {
-	auth := basic.NewCredentialsBuilder().
+	auth, err := basic.NewCredentialsBuilder().
 		WithAk(ak).
 		WithSk(sk).
 		WithProjectId(projectId).
-		Build()
-	client := kms.NewKmsClient(
-		kms.KmsClientBuilder().
-			WithEndpoint(endpoint).
-			WithCredential(auth).
-			Build(),
-	)
-	return &HuaweiKMSKeyManager{client: client, projectId: projectId}, nil
+		SafeBuild()
+	if err != nil {
+		return nil, fmt.Errorf("invalid KMS credentials: %w", err)
+	}
+	hcClient, err := kms.KmsClientBuilder().
+		WithEndpoint(endpoint).
+		WithCredential(auth).
+		SafeBuild()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create KMS client: %w", err)
+	}
+	return &HuaweiKMSKeyManager{
+		client:    kms.NewKmsClient(hcClient),
+		projectId: projectId,
+		keys:      make(map[string]kmsKeyInfo),
+	}, nil
 }
 
-// Create 创建新密钥，返回 keyID 和公钥
+// Create 创建新密钥，返回 keyID 和公钥（AES256密钥没有公钥，返回nil）
+// 可通过WithPurpose(PurposeEncryption)创建RSA2048、SM2加密密钥
 func (h *HuaweiKMSKeyManager) Create(keyType KeyType, opts ...KeyOpts) (string, []byte, error) {
-	var keySpec string
-	switch keyType {
-	case RSA2048:
-		keySpec = "RSA_2048"
-	// 可扩展 ECDSA, SM2 ...
-	default:
+	spec, ok := kmsKeySpecs[keyType]
+	if !ok {
 		return "", nil, fmt.Errorf("unsupported key type: %s", keyType)
 	}
-	keyAlias := "did-sdk-" + uuid.NewString()
-	req := &model.CreateKeyRequest{
-		Body: &model.CreateKeyRequestBody{
-			KeyAlias: keyAlias,
-			KeySpec:  keySpec,
-			KeyUsage: "SIGN_VERIFY",
-		},
+	usage := kmsUsageSignVerify
+	switch purpose := keyPurpose(opts); {
+	case keyType == AES256:
+		if purpose == PurposeSigning {
+			return "", nil, errors.New("AES256 keys cannot be used for signing")
+		}
+		usage = kmsUsageEncryptDecrypt
+	case purpose == PurposeEncryption:
+		if keyType != RSA2048 && keyType != SM2 {
+			return "", nil, fmt.Errorf("key type %s does not support encryption in KMS", keyType)
+		}
+		usage = kmsUsageEncryptDecrypt
 	}
-	resp, err := h.client.CreateKey(req)
-	if err != nil {
+
+	body := &model.CreateKeyRequestBody{
+		KeyAlias: "did-sdk-" + uuid.NewString(),
+		KeySpec:  &model.CreateKeyRequestBodyKeySpec{},
+		KeyUsage: &model.CreateKeyRequestBodyKeyUsage{},
+	}
+	// SECP256K1不在SDK枚举中，统一按JSON值设置
+	if err := setKMSEnum(body.KeySpec, spec); err != nil {
 		return "", nil, err
 	}
-	keyID := resp.KeyMetadata.KeyId
-	// 获取公钥
+	if err := setKMSEnum(body.KeyUsage, usage); err != nil {
+		return "", nil, err
+	}
+	resp, err := h.client.CreateKey(&model.CreateKeyRequest{Body: body})
+	if err != nil {
+		return "", nil, fmt.Errorf("KMS create key failed: %w", err)
+	}
+	if resp.KeyInfo == nil || resp.KeyInfo.KeyId == nil {
+		return "", nil, errors.New("KMS create key returned no key ID")
+	}
+	keyID := *resp.KeyInfo.KeyId
+	h.mu.Lock()
+	h.keys[keyID] = kmsKeyInfo{spec: spec, usage: usage}
+	h.mu.Unlock()
+
+	if keyType == AES256 {
+		return keyID, nil, nil
+	}
 	pub, err := h.Get(keyID)
 	if err != nil {
 		return "", nil, err
@@ -65,16 +152,33 @@ func (h *HuaweiKMSKeyManager) Create(keyType KeyType, opts ...KeyOpts) (string,
 	return keyID, pub, nil
 }
 
-// Get 获取公钥
+// Get 获取公钥（DER编码的SubjectPublicKeyInfo）
 func (h *HuaweiKMSKeyManager) Get(keyID string) ([]byte, error) {
-	req := &model.ShowPublicKeyRequest{
-		KeyId: keyID,
-	}
-	resp, err := h.client.ShowPublicKey(req)
+	info, err := h.keyInfo(keyID)
 	if err != nil {
 		return nil, err
 	}
-	return []byte(resp.PublicKey), nil
+	if info.spec == kmsSpecAES256 {
+		return nil, errors.New("symmetric key has no public key")
+	}
+	resp, err := h.client.ShowPublicKey(&model.ShowPublicKeyRequest{
+		Body: &model.OperateKeyRequestBody{KeyId: keyID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("KMS get public key failed: %w", err)
+	}
+	if resp.PublicKey == nil {
+		return nil, errors.New("KMS returned no public key")
+	}
+	// KMS返回PEM格式公钥，兼容纯base64 DER
+	if block, _ := pem.Decode([]byte(*resp.PublicKey)); block != nil {
+		return block.Bytes, nil
+	}
+	der, err := base64.StdEncoding.DecodeString(*resp.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid KMS public key encoding: %w", err)
+	}
+	return der, nil
 }
 
 // ImportPrivateKey KMS不支持导入私钥
@@ -87,30 +191,39 @@ func (h *HuaweiKMSKeyManager) ExportPrivateKey(keyID string) ([]byte, error) {
 	return nil, fmt.Errorf("Huawei KMS does not support exporting private keys")
 }
 
-// Delete 删除密钥
+// Delete 删除密钥（计划7天后删除）
 func (h *HuaweiKMSKeyManager) Delete(keyID string) error {
-	req := &model.ScheduleKeyDeletionRequest{
-		KeyId: keyID,
-		Body: &model.ScheduleKeyDeletionRequestBody{PendingDays: 7}, // 7天后删除
+	_, err := h.client.DeleteKey(&model.DeleteKeyRequest{
+		Body: &model.ScheduleKeyDeletionRequestBody{KeyId: keyID, PendingDays: "7"},
+	})
+	if err != nil {
+		return fmt.Errorf("KMS delete key failed: %w", err)
 	}
-	_, err := h.client.ScheduleKeyDeletion(req)
-	return err
+	h.mu.Lock()
+	delete(h.keys, keyID)
+	h.mu.Unlock()
+	return nil
 }
 
-// List 列举所有keyID（KMS不直接支持，需分页查询）
+// List 列举所有启用状态的keyID（分页查询），计划删除或已禁用的密钥不返回
 func (h *HuaweiKMSKeyManager) List() ([]string, error) {
 	var ids []string
+	limit := "100"
+	state := kmsKeyStateEnabled
 	marker := ""
 	for {
-		req := &model.ListKeysRequest{Marker: &marker, Limit: int32Ptr(100)}
-		resp, err := h.client.ListKeys(req)
+		body := &model.ListKeysRequestBody{Limit: &limit, KeyState: &state}
+		if marker != "" {
+			body.Marker = &marker
+		}
+		resp, err := h.client.ListKeys(&model.ListKeysRequest{Body: body})
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("KMS list keys failed: %w", err)
 		}
-		for _, k := range *resp.Keys {
-			ids = append(ids, k.KeyId)
+		if resp.Keys != nil {
+			ids = append(ids, *resp.Keys...)
 		}
-		if resp.NextMarker == nil || *resp.NextMarker == "" {
+		if resp.NextMarker == nil || *resp.NextMarker == "" || *resp.NextMarker == marker {
 			break
 		}
 		marker = *resp.NextMarker
@@ -118,49 +231,317 @@ func (h *HuaweiKMSKeyManager) List() ([]string, error) {
 	return ids, nil
 }
 
-func int32Ptr(i int32) *int32 { return &i }
-
 // Sign 使用指定keyID签名
+// ECDSA与RSA密钥传入消息摘要（DIGEST），SM2密钥传入原始消息（RAW，SM2DSA_SM3）；ECDSA/SM2签名为DER编码
+// RSA密钥传入SHA-256摘要，等同于SignRSADigest(keyID, crypto.SHA256, data)
 func (h *HuaweiKMSKeyManager) Sign(keyID string, data []byte) ([]byte, error) {
-	req := &model.SignRequest{
-		Body: &model.SignRequestBody{
-			KeyId:           keyID,
-			Message:         data,
-			SigningAlgorithm: "RSASSA_PKCS1_V1_5_SHA_256",
-			MessageType:     "DIGEST",
-		},
+	alg, msgType, err := h.signingAlgorithm(keyID)
+	if err != nil {
+		return nil, err
 	}
-	resp, err := h.client.Sign(req)
+	if hash, ok := kmsRSASigningHash(alg); ok {
+		return h.SignRSADigest(keyID, hash, data)
+	}
+	return h.sign(keyID, alg, msgType, data)
+}
+
+// SignRSADigest 使用RSA密钥对hash算法计算的摘要进行PKCS#1 v1.5签名（RSASSA_PKCS1_V1_5_SHA_*，DIGEST）
+func (h *HuaweiKMSKeyManager) SignRSADigest(keyID string, hash crypto.Hash, digest []byte) ([]byte, error) {
+	alg, err := h.rsaSigningAlgorithm(keyID, hash, digest)
 	if err != nil {
 		return nil, err
 	}
-	return []byte(resp.Signature), nil
+	return h.sign(keyID, alg, "DIGEST", digest)
+}
+
+// sign 以指定的签名算法与消息类型调用KMS签名
+func (h *HuaweiKMSKeyManager) sign(keyID, alg, msgType string, data []byte) ([]byte, error) {
+	body := &model.SignRequestBody{
+		KeyId:       keyID,
+		Message:     base64.StdEncoding.EncodeToString(data),
+		MessageType: &model.SignRequestBodyMessageType{},
+	}
+	if err := setKMSEnum(&body.SigningAlgorithm, alg); err != nil {
+		return nil, err
+	}
+	if err := setKMSEnum(body.MessageType, msgType); err != nil {
+		return nil, err
+	}
+	resp, err := h.client.Sign(&model.SignRequest{Body: body})
+	if err != nil {
+		return nil, fmt.Errorf("KMS sign failed: %w", err)
+	}
+	if resp.Signature == nil {
+		return nil, errors.New("KMS returned no signature")
+	}
+	sig, err := base64.StdEncoding.DecodeString(*resp.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid KMS signature encoding: %w", err)
+	}
+	return sig, nil
 }
 
-// Verify 使用指定keyID验签
+// Verify 使用指定keyID验签，data的约定与Sign相同
 func (h *HuaweiKMSKeyManager) Verify(keyID string, data, signature []byte) (bool, error) {
-	req := &model.VerifyRequest{
-		Body: &model.VerifyRequestBody{
-			KeyId:           keyID,
-			Message:         data,
-			Signature:       signature,
-			SigningAlgorithm: "RSASSA_PKCS1_V1_5_SHA_256",
-			MessageType:     "DIGEST",
-		},
+	alg, msgType, err := h.signingAlgorithm(keyID)
+	if err != nil {
+		return false, err
+	}
+	if hash, ok := kmsRSASigningHash(alg); ok {
+		return h.VerifyRSADigest(keyID, hash, data, signature)
 	}
-	resp, err := h.client.Verify(req)
+	return h.verify(keyID, alg, msgType, data, signature)
+}
+
+// VerifyRSADigest 验证SignRSADigest生成的签名
+func (h *HuaweiKMSKeyManager) VerifyRSADigest(keyID string, hash crypto.Hash, digest, signature []byte) (bool, error) {
+	alg, err := h.rsaSigningAlgorithm(keyID, hash, digest)
 	if err != nil {
 		return false, err
 	}
-	return *resp.Valid, nil
+	return h.verify(keyID, alg, "DIGEST", digest, signature)
+}
+
+// verify 以指定的签名算法与消息类型调用KMS验签
+func (h *HuaweiKMSKeyManager) verify(keyID, alg, msgType string, data, signature []byte) (bool, error) {
+	body := &model.VerifyRequestBody{
+		KeyId:       keyID,
+		Message:     base64.StdEncoding.EncodeToString(data),
+		Signature:   base64.StdEncoding.EncodeToString(signature),
+		MessageType: &model.VerifyRequestBodyMessageType{},
+	}
+	if err := setKMSEnum(&body.SigningAlgorithm, alg); err != nil {
+		return false, err
+	}
+	if err := setKMSEnum(body.MessageType, msgType); err != nil {
+		return false, err
+	}
+	resp, err := h.client.ValidateSignature(&model.ValidateSignatureRequest{Body: body})
+	if err != nil {
+		return false, fmt.Errorf("KMS verify failed: %w", err)
+	}
+	if resp.SignatureValid == nil {
+		return false, errors.New("KMS returned no verification result")
+	}
+	return strconv.ParseBool(*resp.SignatureValid)
 }
 
-// Encrypt/Decrypt KMS暂不支持
+// Encrypt 使用指定keyID加密
+// AES256主密钥：向KMS申请数据密钥，本地AES-256-GCM加密，输出SchemeKMSDataKey信封；
+// RSA2048/SM2加密密钥：由KMS直接加密（RSAES_OAEP_SHA_256 / SM2_ENCRYPT）
 func (h *HuaweiKMSKeyManager) Encrypt(keyID string, plaintext []byte) ([]byte, error) {
-	return nil, fmt.Errorf("not implemented")
+	info, err := h.keyInfo(keyID)
+	if err != nil {
+		return nil, err
+	}
+	if info.usage != kmsUsageEncryptDecrypt {
+		return nil, fmt.Errorf("key %s is not an encryption key", keyID)
+	}
+	if info.spec == kmsSpecAES256 {
+		return h.encryptWithDataKey(keyID, plaintext)
+	}
+	body := &model.EncryptDataRequestBody{
+		KeyId:               keyID,
+		PlainText:           base64.StdEncoding.EncodeToString(plaintext),
+		EncryptionAlgorithm: &model.EncryptDataRequestBodyEncryptionAlgorithm{},
+	}
+	if err := setKMSEnum(body.EncryptionAlgorithm, kmsEncryptionAlgorithm(info.spec)); err != nil {
+		return nil, err
+	}
+	resp, err := h.client.EncryptData(&model.EncryptDataRequest{Body: body})
+	if err != nil {
+		return nil, fmt.Errorf("KMS encrypt failed: %w", err)
+	}
+	if resp.CipherText == nil {
+		return nil, errors.New("KMS returned no ciphertext")
+	}
+	return base64.StdEncoding.DecodeString(*resp.CipherText)
 }
+
+// Decrypt 使用指定keyID解密Encrypt生成的密文
 func (h *HuaweiKMSKeyManager) Decrypt(keyID string, ciphertext []byte) ([]byte, error) {
-	return nil, fmt.Errorf("not implemented")
+	info, err := h.keyInfo(keyID)
+	if err != nil {
+		return nil, err
+	}
+	if info.usage != kmsUsageEncryptDecrypt {
+		return nil, fmt.Errorf("key %s is not an encryption key", keyID)
+	}
+	if info.spec == kmsSpecAES256 {
+		return h.decryptWithDataKey(keyID, ciphertext)
+	}
+	body := &model.DecryptDataRequestBody{
+		KeyId:               &keyID,
+		CipherText:          base64.StdEncoding.EncodeToString(ciphertext),
+		EncryptionAlgorithm: &model.DecryptDataRequestBodyEncryptionAlgorithm{},
+	}
+	if err := setKMSEnum(body.EncryptionAlgorithm, kmsEncryptionAlgorithm(info.spec)); err != nil {
+		return nil, err
+	}
+	resp, err := h.client.DecryptData(&model.DecryptDataRequest{Body: body})
+	if err != nil {
+		return nil, fmt.Errorf("KMS decrypt failed: %w", err)
+	}
+	if resp.PlainTextBase64 == nil {
+		return nil, errors.New("KMS returned no plaintext")
+	}
+	return base64.StdEncoding.DecodeString(*resp.PlainTextBase64)
+}
+
+// encryptWithDataKey 信封加密：数据密钥明文仅在本地使用，密文随信封保存
+func (h *HuaweiKMSKeyManager) encryptWithDataKey(keyID string, plaintext []byte) ([]byte, error) {
+	length := strconv.Itoa(kmsDataKeyLength * 8)
+	body := &model.CreateDatakeyRequestBody{
+		KeyId:         keyID,
+		KeySpec:       &model.CreateDatakeyRequestBodyKeySpec{},
+		DatakeyLength: &length,
+	}
+	if err := setKMSEnum(body.KeySpec, kmsSpecAES256); err != nil {
+		return nil, err
+	}
+	resp, err := h.client.CreateDatakey(&model.CreateDatakeyRequest{Body: body})
+	if err != nil {
+		return nil, fmt.Errorf("KMS create data key failed: %w", err)
+	}
+	if resp.PlainText == nil || resp.CipherText == nil {
+		return nil, errors.New("KMS returned an incomplete data key")
+	}
+	dataKey, err := hex.DecodeString(*resp.PlainText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid KMS data key encoding: %w", err)
+	}
+	defer wipeBytes(dataKey)
+	encryptedKey, err := hex.DecodeString(*resp.CipherText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid KMS data key encoding: %w", err)
+	}
+	return sealSymmetricEnvelope(dataKey, SchemeKMSDataKey, encryptedKey, plaintext)
+}
+
+// decryptWithDataKey 由KMS解密信封中的数据密钥后本地解密
+func (h *HuaweiKMSKeyManager) decryptWithDataKey(keyID string, ciphertext []byte) ([]byte, error) {
+	env, err := parseSymmetricEnvelope(ciphertext, SchemeKMSDataKey)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := h.client.DecryptDatakey(&model.DecryptDatakeyRequest{
+		Body: &model.DecryptDatakeyRequestBody{
+			KeyId:               keyID,
+			CipherText:          hex.EncodeToString(env.encryptedKey),
+			DatakeyCipherLength: strconv.Itoa(kmsDataKeyLength),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("KMS decrypt data key failed: %w", err)
+	}
+	if resp.DataKey == nil {
+		return nil, errors.New("KMS returned no data key")
+	}
+	dataKey, err := hex.DecodeString(*resp.DataKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid KMS data key encoding: %w", err)
+	}
+	defer wipeBytes(dataKey)
+	return env.open(dataKey)
+}
+
+// keyInfo 返回密钥规格与用途，未缓存时向KMS查询密钥详情
+func (h *HuaweiKMSKeyManager) keyInfo(keyID string) (kmsKeyInfo, error) {
+	h.mu.Lock()
+	info, ok := h.keys[keyID]
+	h.mu.Unlock()
+	if ok {
+		return info, nil
+	}
+	resp, err := h.client.ListKeyDetail(&model.ListKeyDetailRequest{
+		Body: &model.OperateKeyRequestBody{KeyId: keyID},
+	})
+	if err != nil {
+		return kmsKeyInfo{}, fmt.Errorf("KMS describe key failed: %w", err)
+	}
+	if resp.KeyInfo == nil || resp.KeyInfo.KeySpec == nil || resp.KeyInfo.KeyUsage == nil {
+		return kmsKeyInfo{}, fmt.Errorf("KMS returned no details for key %s", keyID)
+	}
+	info = kmsKeyInfo{spec: resp.KeyInfo.KeySpec.Value(), usage: resp.KeyInfo.KeyUsage.Value()}
+	h.mu.Lock()
+	h.keys[keyID] = info
+	h.mu.Unlock()
+	return info, nil
+}
+
+// signingAlgorithm 按密钥规格选择KMS签名算法与消息类型
+func (h *HuaweiKMSKeyManager) signingAlgorithm(keyID string) (string, string, error) {
+	info, err := h.keyInfo(keyID)
+	if err != nil {
+		return "", "", err
+	}
+	if info.usage != kmsUsageSignVerify {
+		return "", "", fmt.Errorf("key %s is not a signing key", keyID)
+	}
+	switch info.spec {
+	case kmsSpecECP256, kmsSpecSecp256k1:
+		return "ECDSA_SHA_256", "DIGEST", nil
+	case kmsSpecECP384:
+		return "ECDSA_SHA_384", "DIGEST", nil
+	case kmsSpecSM2:
+		return "SM2DSA_SM3", "RAW", nil
+	case kmsSpecRSA2048:
+		return kmsRSASigningAlgorithms[crypto.SHA256], "DIGEST", nil
+	default:
+		return "", "", fmt.Errorf("unsupported KMS key spec for signing: %s", info.spec)
+	}
+}
+
+// kmsRSASigningAlgorithms RSA签名摘要算法对应的KMS签名算法
+var kmsRSASigningAlgorithms = map[crypto.Hash]string{
+	crypto.SHA256: "RSASSA_PKCS1_V1_5_SHA_256",
+	crypto.SHA384: "RSASSA_PKCS1_V1_5_SHA_384",
+	crypto.SHA512: "RSASSA_PKCS1_V1_5_SHA_512",
+}
+
+// kmsRSASigningHash 返回KMS RSA签名算法对应的摘要算法，非RSA签名算法返回false
+func kmsRSASigningHash(alg string) (crypto.Hash, bool) {
+	for hash, name := range kmsRSASigningAlgorithms {
+		if name == alg {
+			return hash, true
+		}
+	}
+	return 0, false
+}
+
+// rsaSigningAlgorithm 检查密钥为RSA签名密钥且摘要与hash一致，返回KMS签名算法
+func (h *HuaweiKMSKeyManager) rsaSigningAlgorithm(keyID string, hash crypto.Hash, digest []byte) (string, error) {
+	if err := checkRSADigest(hash, digest); err != nil {
+		return "", err
+	}
+	info, err := h.keyInfo(keyID)
+	if err != nil {
+		return "", err
+	}
+	if info.usage != kmsUsageSignVerify || info.spec != kmsSpecRSA2048 {
+		return "", fmt.Errorf("key %s is not an RSA signing key", keyID)
+	}
+	return kmsRSASigningAlgorithms[hash], nil
+}
+
+// kmsEncryptionAlgorithm 非对称加密密钥使用的KMS加密算法
+func kmsEncryptionAlgorithm(spec string) string {
+	if spec == kmsSpecSM2 {
+		return "SM2_ENCRYPT"
+	}
+	return "RSAES_OAEP_SHA_256"
+}
+
+// setKMSEnum 设置SDK枚举值，SDK枚举只能通过JSON反序列化设置任意取值
+func setKMSEnum(enum json.Unmarshaler, value string) error {
+	return enum.UnmarshalJSON([]byte(strconv.Quote(value)))
+}
+
+// wipeBytes 清零内存中的密钥
+func wipeBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
 }
 
 // RandString 生成随机字符串（用于KeyAlias）
@@ -171,4 +552,4 @@ func RandString(n int) string {
 		b[i] = letters[rand.Intn(len(letters))]
 	}
 	return string(b)
-} 
\ No newline at end of file
+}
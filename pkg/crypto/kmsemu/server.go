@@ -0,0 +1,781 @@
+// Package kmsemu 提供基于httptest的内存版华为云KMS v2模拟服务
+// 用于在没有云凭证的环境（如CI）中测试crypto.HuaweiKMSKeyManager，密钥由进程内的LocalKeyManager保存
+package kmsemu
+
+import (
+	stdcrypto "crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/helailiang/sbp-did-sdk-go/pkg/crypto"
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+// 模拟服务默认接受的凭证
+const (
+	AccessKey = "emulator-ak"
+	SecretKey = "emulator-sk"
+	ProjectID = "emulator-project"
+)
+
+// 模拟服务返回的错误码
+const (
+	CodeBadRequest   = "KMS.0201" // 请求参数错误
+	CodeUnauthorized = "KMS.0202" // 认证失败
+	CodeKeyNotFound  = "KMS.0204" // 密钥不存在
+	CodeKeyState     = "KMS.0205" // 密钥状态不允许该操作
+	CodeUnsupported  = "KMS.0206" // 密钥规格或用途不支持该操作
+	CodeCrypto       = "KMS.0207" // 加解密或签名失败
+	CodeNotFound     = "KMS.0404" // 接口不存在
+)
+
+// 密钥状态
+const (
+	KeyStateEnabled         = "2"
+	KeyStateDisabled        = "3"
+	KeyStatePendingDeletion = "4"
+)
+
+// Fault 故障注入配置
+type Fault struct {
+	Action     string        // 生效的接口，如 "sign"、"create-key"，为空时对所有接口生效
+	Delay      time.Duration // 响应前的延迟
+	StatusCode int           // 返回的HTTP状态码，为0时不返回错误
+	ErrorCode  string        // 错误码，为空时使用 "KMS.0500"
+	Message    string        // 错误信息
+	Times      int           // 生效次数，<=0表示一直生效
+}
+
+// RecordedRequest 模拟服务收到的请求记录
+type RecordedRequest struct {
+	Method    string
+	Action    string
+	ProjectID string
+	Body      []byte
+}
+
+// KeyRecord 密钥状态
+type KeyRecord struct {
+	KeyID       string
+	KeyAlias    string
+	KeySpec     string
+	KeyUsage    string
+	KeyState    string
+	PendingDays string
+}
+
+// kmsError KMS错误响应
+type kmsError struct {
+	status int
+	code   string
+	msg    string
+}
+
+func newError(status int, code, format string, args ...interface{}) *kmsError {
+	return &kmsError{status: status, code: code, msg: fmt.Sprintf(format, args...)}
+}
+
+// emuKey 密钥记录及其在LocalKeyManager中的keyID
+type emuKey struct {
+	KeyRecord
+	localID string
+}
+
+// keySpecTypes KMS密钥规格与LocalKeyManager密钥类型的对应关系
+var keySpecTypes = map[string]crypto.KeyType{
+	"AES_256":   crypto.AES256,
+	"RSA_2048":  crypto.RSA2048,
+	"EC_P256":   crypto.ECDSAP256,
+	"EC_P384":   crypto.ECDSAP384,
+	"SECP256K1": crypto.ECDSASecp256k1,
+	"SM2":       crypto.SM2,
+}
+
+// Server 内存版KMS模拟服务
+// 实现 /v1.0/{project_id}/kms/ 下HuaweiKMSKeyManager使用的接口，所有状态保存在内存中，并发安全
+type Server struct {
+	*httptest.Server
+
+	// PageSize list-keys每页最多返回的密钥数，<=0时按请求的limit返回
+	PageSize int
+
+	mu       sync.Mutex
+	backend  *crypto.LocalKeyManager
+	keys     map[string]*emuKey
+	faults   []*Fault
+	requests []RecordedRequest
+	handlers map[string]func(body []byte) (interface{}, *kmsError)
+}
+
+// NewServer 创建并启动模拟服务，使用完毕后需调用Close
+func NewServer() *Server {
+	s := &Server{
+		backend: crypto.NewLocalKeyManager(),
+		keys:    make(map[string]*emuKey),
+	}
+	s.handlers = map[string]func(body []byte) (interface{}, *kmsError){
+		"create-key":            s.createKey,
+		"describe-key":          s.describeKey,
+		"get-publickey":         s.getPublicKey,
+		"sign":                  s.sign,
+		"verify":                s.verify,
+		"list-keys":             s.listKeys,
+		"schedule-key-deletion": s.scheduleKeyDeletion,
+		"create-datakey":        s.createDatakey,
+		"decrypt-datakey":       s.decryptDatakey,
+		"encrypt-data":          s.encryptData,
+		"decrypt-data":          s.decryptData,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// KeyManager 返回指向模拟服务的HuaweiKMSKeyManager
+func (s *Server) KeyManager() (*crypto.HuaweiKMSKeyManager, error) {
+	return crypto.NewHuaweiKMSKeyManager(s.URL, AccessKey, SecretKey, ProjectID)
+}
+
+// InjectFault 注入故障，按注入顺序匹配第一个生效的故障
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults 清除所有故障
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// Requests 返回收到的请求记录
+func (s *Server) Requests() []RecordedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]RecordedRequest(nil), s.requests...)
+}
+
+// Calls 返回指定接口收到的请求数
+func (s *Server) Calls(action string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, r := range s.requests {
+		if r.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// Key 返回密钥状态
+func (s *Server) Key(keyID string) (KeyRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k, ok := s.keys[keyID]
+	if !ok {
+		return KeyRecord{}, false
+	}
+	return k.KeyRecord, true
+}
+
+// SetKeyState 修改密钥状态，如禁用密钥
+func (s *Server) SetKeyState(keyID, state string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k, ok := s.keys[keyID]
+	if ok {
+		k.KeyState = state
+	}
+	return ok
+}
+
+// serveHTTP 处理所有请求
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	// 路径形如 /v1.0/{project_id}/kms/{action}
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 4 || parts[0] != "v1.0" || parts[2] != "kms" {
+		writeError(w, newError(http.StatusNotFound, CodeNotFound, "no such endpoint: %s", r.URL.Path))
+		return
+	}
+	projectID, action := parts[1], parts[3]
+
+	s.mu.Lock()
+	s.requests = append(s.requests, RecordedRequest{Method: r.Method, Action: action, ProjectID: projectID, Body: body})
+	fault := s.matchFault(action)
+	s.mu.Unlock()
+
+	if fault != nil {
+		if fault.Delay > 0 {
+			select {
+			case <-time.After(fault.Delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if fault.StatusCode != 0 && fault.StatusCode != http.StatusOK {
+			code := fault.ErrorCode
+			if code == "" {
+				code = "KMS.0500"
+			}
+			writeError(w, newError(fault.StatusCode, code, "%s", fault.Message))
+			return
+		}
+	}
+
+	handler, ok := s.handlers[action]
+	if !ok {
+		writeError(w, newError(http.StatusNotFound, CodeNotFound, "no such endpoint: %s", r.URL.Path))
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, newError(http.StatusMethodNotAllowed, CodeBadRequest, "method not allowed"))
+		return
+	}
+	// SDK使用AK/SK签名，Authorization头携带Access=AK
+	if projectID != ProjectID || !strings.Contains(r.Header.Get("Authorization"), "Access="+AccessKey) {
+		writeError(w, newError(http.StatusUnauthorized, CodeUnauthorized, "invalid credentials or project"))
+		return
+	}
+
+	s.mu.Lock()
+	data, kerr := handler(body)
+	s.mu.Unlock()
+	if kerr != nil {
+		writeError(w, kerr)
+		return
+	}
+	out, _ := json.Marshal(data)
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	w.Write(out)
+}
+
+// matchFault 查找生效的故障，调用方需持有锁
+func (s *Server) matchFault(action string) *Fault {
+	for i, f := range s.faults {
+		if f.Action != "" && f.Action != action {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+// writeError 写入KMS格式的错误响应
+func writeError(w http.ResponseWriter, e *kmsError) {
+	out, _ := json.Marshal(map[string]interface{}{
+		"error": map[string]string{"error_code": e.code, "error_msg": e.msg},
+	})
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	w.WriteHeader(e.status)
+	w.Write(out)
+}
+
+// decode 解析请求体
+func decode(body []byte, v interface{}) *kmsError {
+	if err := json.Unmarshal(body, v); err != nil {
+		return newError(http.StatusBadRequest, CodeBadRequest, "invalid request body: %v", err)
+	}
+	return nil
+}
+
+// lookup 查找密钥并检查状态与用途，调用方需持有锁；usage为空时不检查用途
+func (s *Server) lookup(keyID, usage string) (*emuKey, *kmsError) {
+	k, ok := s.keys[keyID]
+	if !ok {
+		return nil, newError(http.StatusBadRequest, CodeKeyNotFound, "key %s not found", keyID)
+	}
+	if k.KeyState != KeyStateEnabled {
+		return nil, newError(http.StatusBadRequest, CodeKeyState, "key %s is not enabled (state %s)", keyID, k.KeyState)
+	}
+	if usage != "" && k.KeyUsage != usage {
+		return nil, newError(http.StatusBadRequest, CodeUnsupported, "key %s usage is %s", keyID, k.KeyUsage)
+	}
+	return k, nil
+}
+
+// ========== 密钥管理 ========== //
+
+func (s *Server) createKey(body []byte) (interface{}, *kmsError) {
+	var req struct {
+		KeyAlias string `json:"key_alias"`
+		KeySpec  string `json:"key_spec"`
+		KeyUsage string `json:"key_usage"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	if req.KeyAlias == "" {
+		return nil, newError(http.StatusBadRequest, CodeBadRequest, "key_alias is required")
+	}
+	if req.KeySpec == "" {
+		req.KeySpec = "AES_256"
+	}
+	keyType, ok := keySpecTypes[req.KeySpec]
+	if !ok {
+		return nil, newError(http.StatusBadRequest, CodeBadRequest, "unsupported key_spec: %s", req.KeySpec)
+	}
+	if req.KeyUsage == "" {
+		req.KeyUsage = "ENCRYPT_DECRYPT"
+		if keyType != crypto.AES256 {
+			req.KeyUsage = "SIGN_VERIFY"
+		}
+	}
+	switch {
+	case req.KeyUsage != "ENCRYPT_DECRYPT" && req.KeyUsage != "SIGN_VERIFY":
+		return nil, newError(http.StatusBadRequest, CodeBadRequest, "unsupported key_usage: %s", req.KeyUsage)
+	case keyType == crypto.AES256 && req.KeyUsage != "ENCRYPT_DECRYPT":
+		return nil, newError(http.StatusBadRequest, CodeBadRequest, "AES_256 keys only support ENCRYPT_DECRYPT")
+	case req.KeyUsage == "ENCRYPT_DECRYPT" && keyType != crypto.AES256 && keyType != crypto.RSA2048 && keyType != crypto.SM2:
+		return nil, newError(http.StatusBadRequest, CodeBadRequest, "%s keys do not support ENCRYPT_DECRYPT", req.KeySpec)
+	}
+	for _, k := range s.keys {
+		if k.KeyAlias == req.KeyAlias && k.KeyState != KeyStatePendingDeletion {
+			return nil, newError(http.StatusBadRequest, CodeBadRequest, "key_alias %s already exists", req.KeyAlias)
+		}
+	}
+	localID, _, err := s.backend.Create(keyType)
+	if err != nil {
+		return nil, newError(http.StatusInternalServerError, CodeCrypto, "failed to create key: %v", err)
+	}
+	keyID := uuid.NewString()
+	s.keys[keyID] = &emuKey{
+		KeyRecord: KeyRecord{KeyID: keyID, KeyAlias: req.KeyAlias, KeySpec: req.KeySpec, KeyUsage: req.KeyUsage, KeyState: KeyStateEnabled},
+		localID:   localID,
+	}
+	return map[string]interface{}{"key_info": map[string]string{"key_id": keyID, "domain_id": "emulator-domain"}}, nil
+}
+
+func (s *Server) describeKey(body []byte) (interface{}, *kmsError) {
+	var req struct {
+		KeyID string `json:"key_id"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	k, ok := s.keys[req.KeyID]
+	if !ok {
+		return nil, newError(http.StatusBadRequest, CodeKeyNotFound, "key %s not found", req.KeyID)
+	}
+	return map[string]interface{}{"key_info": keyDetails(k)}, nil
+}
+
+func (s *Server) getPublicKey(body []byte) (interface{}, *kmsError) {
+	var req struct {
+		KeyID string `json:"key_id"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	k, kerr := s.lookup(req.KeyID, "")
+	if kerr != nil {
+		return nil, kerr
+	}
+	der, err := s.backend.Get(k.localID)
+	if err != nil {
+		return nil, newError(http.StatusBadRequest, CodeUnsupported, "key %s has no public key", req.KeyID)
+	}
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	return map[string]string{"key_id": req.KeyID, "public_key": string(pemKey)}, nil
+}
+
+func (s *Server) listKeys(body []byte) (interface{}, *kmsError) {
+	var req struct {
+		Limit    string `json:"limit"`
+		Marker   string `json:"marker"`
+		KeyState string `json:"key_state"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	limit := 1000
+	if req.Limit != "" {
+		n, err := strconv.Atoi(req.Limit)
+		if err != nil || n <= 0 {
+			return nil, newError(http.StatusBadRequest, CodeBadRequest, "invalid limit: %s", req.Limit)
+		}
+		limit = n
+	}
+	if s.PageSize > 0 && s.PageSize < limit {
+		limit = s.PageSize
+	}
+	var ids []string
+	for id, k := range s.keys {
+		if req.KeyState == "" || req.KeyState == k.KeyState {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	// marker为上一页最后一个keyID
+	start := sort.SearchStrings(ids, req.Marker)
+	if start < len(ids) && ids[start] == req.Marker {
+		start++
+	}
+	end := start + limit
+	truncated, next := "false", ""
+	if end < len(ids) {
+		truncated, next = "true", ids[end-1]
+	} else {
+		end = len(ids)
+	}
+	page := ids[start:end]
+	details := make([]map[string]string, 0, len(page))
+	for _, id := range page {
+		details = append(details, keyDetails(s.keys[id]))
+	}
+	return map[string]interface{}{
+		"keys":        page,
+		"key_details": details,
+		"next_marker": next,
+		"truncated":   truncated,
+		"total":       len(ids),
+	}, nil
+}
+
+func (s *Server) scheduleKeyDeletion(body []byte) (interface{}, *kmsError) {
+	var req struct {
+		KeyID       string `json:"key_id"`
+		PendingDays string `json:"pending_days"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	if days, err := strconv.Atoi(req.PendingDays); err != nil || days < 7 || days > 1096 {
+		return nil, newError(http.StatusBadRequest, CodeBadRequest, "pending_days must be between 7 and 1096")
+	}
+	k, ok := s.keys[req.KeyID]
+	if !ok {
+		return nil, newError(http.StatusBadRequest, CodeKeyNotFound, "key %s not found", req.KeyID)
+	}
+	if k.KeyState == KeyStatePendingDeletion {
+		return nil, newError(http.StatusBadRequest, CodeKeyState, "key %s is already pending deletion", req.KeyID)
+	}
+	k.KeyState = KeyStatePendingDeletion
+	k.PendingDays = req.PendingDays
+	return map[string]string{"key_id": req.KeyID, "key_state": k.KeyState}, nil
+}
+
+// keyDetails 生成describe-key/list-keys中的密钥详情
+func keyDetails(k *emuKey) map[string]string {
+	return map[string]string{
+		"key_id":    k.KeyID,
+		"key_alias": k.KeyAlias,
+		"key_spec":  k.KeySpec,
+		"key_usage": k.KeyUsage,
+		"key_state": k.KeyState,
+		"origin":    "kms",
+	}
+}
+
+// ========== 签名验签 ========== //
+
+// signRequest sign/verify的请求体
+type signRequest struct {
+	KeyID            string `json:"key_id"`
+	Message          string `json:"message"`
+	Signature        string `json:"signature"`
+	SigningAlgorithm string `json:"signing_algorithm"`
+	MessageType      string `json:"message_type"`
+}
+
+// checkSignRequest 检查签名算法与消息类型并返回密钥和消息，调用方需持有锁
+// 与KMS一致：ECDSA/RSA的DIGEST消息长度须与算法的摘要长度一致，SM2DSA_SM3仅支持RAW
+func (s *Server) checkSignRequest(req *signRequest) (*emuKey, []byte, *kmsError) {
+	k, kerr := s.lookup(req.KeyID, "SIGN_VERIFY")
+	if kerr != nil {
+		return nil, nil, kerr
+	}
+	msg, err := base64.StdEncoding.DecodeString(req.Message)
+	if err != nil || len(msg) == 0 {
+		return nil, nil, newError(http.StatusBadRequest, CodeBadRequest, "message must be non-empty base64")
+	}
+	digestSizes := map[string]int{
+		"ECDSA_SHA_256": 32, "ECDSA_SHA_384": 48,
+		"RSASSA_PKCS1_V1_5_SHA_256": 32, "RSASSA_PKCS1_V1_5_SHA_384": 48, "RSASSA_PKCS1_V1_5_SHA_512": 64,
+	}
+	var allowed bool
+	switch k.KeySpec {
+	case "EC_P256", "SECP256K1":
+		allowed = req.SigningAlgorithm == "ECDSA_SHA_256"
+	case "EC_P384":
+		allowed = req.SigningAlgorithm == "ECDSA_SHA_384"
+	case "RSA_2048":
+		allowed = strings.HasPrefix(req.SigningAlgorithm, "RSASSA_PKCS1_V1_5_")
+	case "SM2":
+		allowed = req.SigningAlgorithm == "SM2DSA_SM3"
+	}
+	if !allowed {
+		return nil, nil, newError(http.StatusBadRequest, CodeUnsupported, "signing_algorithm %s not supported for %s", req.SigningAlgorithm, k.KeySpec)
+	}
+	if k.KeySpec == "SM2" {
+		if req.MessageType != "RAW" {
+			return nil, nil, newError(http.StatusBadRequest, CodeUnsupported, "emulator supports SM2DSA_SM3 with RAW messages only")
+		}
+		return k, msg, nil
+	}
+	// 模拟服务要求ECDSA/RSA使用DIGEST消息类型
+	if req.MessageType != "DIGEST" || len(msg) != digestSizes[req.SigningAlgorithm] {
+		return nil, nil, newError(http.StatusBadRequest, CodeBadRequest, "message must be a %s digest", req.SigningAlgorithm)
+	}
+	return k, msg, nil
+}
+
+// rsaSigningHashes RSA签名算法对应的摘要算法，RSA签名经LocalKeyManager.SignRSADigest完成
+var rsaSigningHashes = map[string]stdcrypto.Hash{
+	"RSASSA_PKCS1_V1_5_SHA_256": stdcrypto.SHA256,
+	"RSASSA_PKCS1_V1_5_SHA_384": stdcrypto.SHA384,
+	"RSASSA_PKCS1_V1_5_SHA_512": stdcrypto.SHA512,
+}
+
+func (s *Server) sign(body []byte) (interface{}, *kmsError) {
+	var req signRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	k, msg, kerr := s.checkSignRequest(&req)
+	if kerr != nil {
+		return nil, kerr
+	}
+	var sig []byte
+	var err error
+	if hash, ok := rsaSigningHashes[req.SigningAlgorithm]; ok {
+		sig, err = s.backend.SignRSADigest(k.localID, hash, msg)
+	} else {
+		sig, err = s.backend.Sign(k.localID, msg)
+	}
+	if err != nil {
+		return nil, newError(http.StatusInternalServerError, CodeCrypto, "sign failed: %v", err)
+	}
+	return map[string]string{"key_id": req.KeyID, "signature": base64.StdEncoding.EncodeToString(sig)}, nil
+}
+
+func (s *Server) verify(body []byte) (interface{}, *kmsError) {
+	var req signRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	k, msg, kerr := s.checkSignRequest(&req)
+	if kerr != nil {
+		return nil, kerr
+	}
+	sig, err := base64.StdEncoding.DecodeString(req.Signature)
+	if err != nil {
+		return nil, newError(http.StatusBadRequest, CodeBadRequest, "signature must be base64")
+	}
+	var valid bool
+	if hash, ok := rsaSigningHashes[req.SigningAlgorithm]; ok {
+		valid, err = s.backend.VerifyRSADigest(k.localID, hash, msg, sig)
+	} else {
+		valid, err = s.backend.Verify(k.localID, msg, sig)
+	}
+	if err != nil {
+		return nil, newError(http.StatusInternalServerError, CodeCrypto, "verify failed: %v", err)
+	}
+	return map[string]string{"key_id": req.KeyID, "signature_valid": strconv.FormatBool(valid)}, nil
+}
+
+// ========== 加密解密 ========== //
+
+func (s *Server) createDatakey(body []byte) (interface{}, *kmsError) {
+	var req struct {
+		KeyID         string `json:"key_id"`
+		KeySpec       string `json:"key_spec"`
+		DatakeyLength string `json:"datakey_length"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	k, kerr := s.lookup(req.KeyID, "ENCRYPT_DECRYPT")
+	if kerr != nil {
+		return nil, kerr
+	}
+	if k.KeySpec != "AES_256" {
+		return nil, newError(http.StatusBadRequest, CodeUnsupported, "data keys require a symmetric key")
+	}
+	bits := map[string]int{"AES_256": 256, "AES_128": 128}[req.KeySpec]
+	if req.DatakeyLength != "" {
+		n, err := strconv.Atoi(req.DatakeyLength)
+		if err != nil || n <= 0 || n%8 != 0 || n > 8192 || (bits != 0 && n != bits) {
+			return nil, newError(http.StatusBadRequest, CodeBadRequest, "invalid datakey_length: %s", req.DatakeyLength)
+		}
+		bits = n
+	}
+	if bits == 0 {
+		return nil, newError(http.StatusBadRequest, CodeBadRequest, "key_spec or datakey_length is required")
+	}
+	dataKey := make([]byte, bits/8)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, newError(http.StatusInternalServerError, CodeCrypto, "failed to generate data key: %v", err)
+	}
+	ct, err := s.backend.Encrypt(k.localID, dataKey)
+	if err != nil {
+		return nil, newError(http.StatusInternalServerError, CodeCrypto, "failed to encrypt data key: %v", err)
+	}
+	return map[string]string{
+		"key_id":      req.KeyID,
+		"plain_text":  hex.EncodeToString(dataKey),
+		"cipher_text": hex.EncodeToString(ct),
+	}, nil
+}
+
+func (s *Server) decryptDatakey(body []byte) (interface{}, *kmsError) {
+	var req struct {
+		KeyID               string `json:"key_id"`
+		CipherText          string `json:"cipher_text"`
+		DatakeyCipherLength string `json:"datakey_cipher_length"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	k, kerr := s.lookup(req.KeyID, "ENCRYPT_DECRYPT")
+	if kerr != nil {
+		return nil, kerr
+	}
+	ct, err := hex.DecodeString(req.CipherText)
+	if err != nil {
+		return nil, newError(http.StatusBadRequest, CodeBadRequest, "cipher_text must be hex")
+	}
+	dataKey, err := s.backend.Decrypt(k.localID, ct)
+	if err != nil {
+		return nil, newError(http.StatusBadRequest, CodeCrypto, "failed to decrypt data key")
+	}
+	if strconv.Itoa(len(dataKey)) != req.DatakeyCipherLength {
+		return nil, newError(http.StatusBadRequest, CodeBadRequest, "datakey_cipher_length does not match")
+	}
+	return map[string]string{
+		"data_key":       hex.EncodeToString(dataKey),
+		"datakey_length": req.DatakeyCipherLength,
+	}, nil
+}
+
+// encryptionAlgorithms 非对称加密密钥支持的加密算法
+var encryptionAlgorithms = map[string]string{
+	"RSA_2048": "RSAES_OAEP_SHA_256",
+	"SM2":      "SM2_ENCRYPT",
+}
+
+// privateKey 返回非对称加密密钥的私钥，调用方需持有锁
+func (s *Server) privateKey(k *emuKey, algorithm string) (*crypto.KeyPair, *kmsError) {
+	if want, ok := encryptionAlgorithms[k.KeySpec]; !ok || algorithm != want {
+		return nil, newError(http.StatusBadRequest, CodeUnsupported, "encryption_algorithm %s not supported for %s", algorithm, k.KeySpec)
+	}
+	der, err := s.backend.ExportPrivateKey(k.localID)
+	if err != nil {
+		return nil, newError(http.StatusInternalServerError, CodeCrypto, "%v", err)
+	}
+	kp, err := crypto.ParsePrivateKey(der, "")
+	if err != nil {
+		return nil, newError(http.StatusInternalServerError, CodeCrypto, "%v", err)
+	}
+	return kp, nil
+}
+
+func (s *Server) encryptData(body []byte) (interface{}, *kmsError) {
+	var req struct {
+		KeyID               string `json:"key_id"`
+		PlainText           string `json:"plain_text"`
+		EncryptionAlgorithm string `json:"encryption_algorithm"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	k, kerr := s.lookup(req.KeyID, "ENCRYPT_DECRYPT")
+	if kerr != nil {
+		return nil, kerr
+	}
+	var ct []byte
+	if k.KeySpec == "AES_256" {
+		if req.EncryptionAlgorithm != "" && req.EncryptionAlgorithm != "SYMMETRIC_DEFAULT" {
+			return nil, newError(http.StatusBadRequest, CodeUnsupported, "encryption_algorithm %s not supported for AES_256", req.EncryptionAlgorithm)
+		}
+		var err error
+		if ct, err = s.backend.Encrypt(k.localID, []byte(req.PlainText)); err != nil {
+			return nil, newError(http.StatusInternalServerError, CodeCrypto, "%v", err)
+		}
+	} else {
+		// 非对称加密时plain_text为base64编码的明文
+		plain, err := base64.StdEncoding.DecodeString(req.PlainText)
+		if err != nil || len(plain) == 0 {
+			return nil, newError(http.StatusBadRequest, CodeBadRequest, "plain_text must be non-empty base64")
+		}
+		kp, kerr := s.privateKey(k, req.EncryptionAlgorithm)
+		if kerr != nil {
+			return nil, kerr
+		}
+		switch priv := kp.PrivateKey.(type) {
+		case *rsa.PrivateKey:
+			ct, err = rsa.EncryptOAEP(sha256.New(), rand.Reader, &priv.PublicKey, plain, nil)
+		case *sm2.PrivateKey:
+			ct, err = crypto.EncryptSM2(rand.Reader, &priv.PublicKey, plain)
+		}
+		if err != nil {
+			return nil, newError(http.StatusBadRequest, CodeCrypto, "encrypt failed: %v", err)
+		}
+	}
+	return map[string]string{"key_id": req.KeyID, "cipher_text": base64.StdEncoding.EncodeToString(ct)}, nil
+}
+
+func (s *Server) decryptData(body []byte) (interface{}, *kmsError) {
+	var req struct {
+		KeyID               string `json:"key_id"`
+		CipherText          string `json:"cipher_text"`
+		EncryptionAlgorithm string `json:"encryption_algorithm"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	k, kerr := s.lookup(req.KeyID, "ENCRYPT_DECRYPT")
+	if kerr != nil {
+		return nil, kerr
+	}
+	ct, err := base64.StdEncoding.DecodeString(req.CipherText)
+	if err != nil {
+		return nil, newError(http.StatusBadRequest, CodeBadRequest, "cipher_text must be base64")
+	}
+	var plain []byte
+	if k.KeySpec == "AES_256" {
+		plain, err = s.backend.Decrypt(k.localID, ct)
+	} else {
+		kp, kerr := s.privateKey(k, req.EncryptionAlgorithm)
+		if kerr != nil {
+			return nil, kerr
+		}
+		switch priv := kp.PrivateKey.(type) {
+		case *rsa.PrivateKey:
+			plain, err = rsa.DecryptOAEP(sha256.New(), rand.Reader, priv, ct, nil)
+		case *sm2.PrivateKey:
+			plain, err = crypto.DecryptSM2(priv, ct)
+		}
+	}
+	if err != nil {
+		return nil, newError(http.StatusBadRequest, CodeCrypto, "decrypt failed")
+	}
+	return map[string]string{
+		"key_id":            req.KeyID,
+		"plain_text":        string(plain),
+		"plain_text_base64": base64.StdEncoding.EncodeToString(plain),
+	}, nil
+}
@@ -0,0 +1,589 @@
+// Package kmtest 提供crypto.KeyManager与crypto.Crypto实现的一致性测试套件
+// 自定义密钥后端可在测试中调用 kmtest.Run，检查其行为与LocalKeyManager、HuaweiKMSKeyManager一致
+package kmtest
+
+import (
+	"bytes"
+	stdcrypto "crypto"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/helailiang/sbp-did-sdk-go/pkg/config"
+	"github.com/helailiang/sbp-did-sdk-go/pkg/crypto"
+)
+
+// Backend 同时实现KeyManager与Crypto的密钥后端
+type Backend interface {
+	crypto.KeyManager
+	crypto.Crypto
+}
+
+// Factory 创建一个空的待测后端，每个子测试调用一次，可通过t.Cleanup释放资源
+type Factory func(t *testing.T) Backend
+
+// AllKeyTypes crypto包定义的全部密钥类型
+var AllKeyTypes = []crypto.KeyType{
+	crypto.ED25519,
+	crypto.ECDSAP256,
+	crypto.ECDSAP384,
+	crypto.ECDSASecp256k1,
+	crypto.RSA2048,
+	crypto.SM2,
+	crypto.AES256,
+}
+
+// Option Run的可选配置
+type Option func(*options)
+
+type options struct {
+	keyTypes           []crypto.KeyType
+	encryptionKeyTypes []crypto.KeyType
+	exportable         bool
+	concurrency        int
+}
+
+// WithKeyTypes 设置后端支持的密钥类型，默认为AllKeyTypes
+func WithKeyTypes(keyTypes ...crypto.KeyType) Option {
+	return func(o *options) {
+		o.keyTypes = keyTypes
+	}
+}
+
+// WithEncryptionKeyTypes 设置支持Encrypt/Decrypt的密钥类型，其余类型的Encrypt须返回错误
+// 默认与LocalKeyManager一致：AES256、ECDSAP256、ECDSASecp256k1、RSA2048、SM2
+// 这些类型的密钥以crypto.WithPurpose(crypto.PurposeEncryption)创建
+func WithEncryptionKeyTypes(keyTypes ...crypto.KeyType) Option {
+	return func(o *options) {
+		o.encryptionKeyTypes = keyTypes
+	}
+}
+
+// WithoutExport 后端不支持导入导出私钥（如KMS、HSM），此时ImportPrivateKey与ExportPrivateKey须返回错误
+func WithoutExport() Option {
+	return func(o *options) {
+		o.exportable = false
+	}
+}
+
+// WithConcurrency 设置并发测试的goroutine数，默认为8
+func WithConcurrency(n int) Option {
+	return func(o *options) {
+		o.concurrency = n
+	}
+}
+
+// Run 运行一致性测试套件
+//
+// 约定与crypto.Crypto一致：ECDSA与RSA密钥的Sign/Verify传入消息摘要（P-384为SHA-384，其余为SHA-256），
+// SM2与Ed25519传入原始消息；签名须能用crypto.VerifySignature对原始消息验证。
+// 后端实现crypto.RSADigestSigner时，SignRSADigest的签名同样须能被验证。
+// Get返回的公钥须能被crypto.ParsePublicKey解析，AES256密钥没有公钥。
+func Run(t *testing.T, factory Factory, opts ...Option) {
+	o := &options{
+		keyTypes:           AllKeyTypes,
+		encryptionKeyTypes: []crypto.KeyType{crypto.AES256, crypto.ECDSAP256, crypto.ECDSASecp256k1, crypto.RSA2048, crypto.SM2},
+		exportable:         true,
+		concurrency:        8,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	s := &suite{factory: factory, options: o}
+	t.Run("Lifecycle", s.testLifecycle)
+	t.Run("SignVerify", s.testSignVerify)
+	t.Run("EncryptDecrypt", s.testEncryptDecrypt)
+	t.Run("ImportExport", s.testImportExport)
+	t.Run("UnknownKey", s.testUnknownKey)
+	t.Run("UnsupportedKeyType", s.testUnsupportedKeyType)
+	t.Run("Concurrency", s.testConcurrency)
+}
+
+type suite struct {
+	factory Factory
+	*options
+}
+
+// supports 后端是否支持该密钥类型
+func (s *suite) supports(keyType crypto.KeyType) bool {
+	return containsKeyType(s.keyTypes, keyType)
+}
+
+// encrypts 该密钥类型是否支持加密
+func (s *suite) encrypts(keyType crypto.KeyType) bool {
+	return s.supports(keyType) && containsKeyType(s.encryptionKeyTypes, keyType)
+}
+
+// signingKeyTypes 支持签名的密钥类型
+func (s *suite) signingKeyTypes() []crypto.KeyType {
+	var out []crypto.KeyType
+	for _, kt := range s.keyTypes {
+		if kt != crypto.AES256 {
+			out = append(out, kt)
+		}
+	}
+	return out
+}
+
+// create 创建密钥，加密类型以PurposeEncryption创建
+func (s *suite) create(t *testing.T, b Backend, keyType crypto.KeyType) (string, []byte) {
+	t.Helper()
+	var opts []crypto.KeyOpts
+	if keyType == crypto.AES256 {
+		opts = append(opts, crypto.WithPurpose(crypto.PurposeEncryption))
+	}
+	keyID, pub, err := b.Create(keyType, opts...)
+	if err != nil {
+		t.Fatalf("Create(%s) failed: %v", keyType, err)
+	}
+	if keyID == "" {
+		t.Fatalf("Create(%s) returned an empty key ID", keyType)
+	}
+	return keyID, pub
+}
+
+func (s *suite) testLifecycle(t *testing.T) {
+	for _, keyType := range s.keyTypes {
+		keyType := keyType
+		t.Run(string(keyType), func(t *testing.T) {
+			b := s.factory(t)
+			keyID, pub := s.create(t, b, keyType)
+			otherID, _ := s.create(t, b, keyType)
+			if otherID == keyID {
+				t.Fatalf("Create returned duplicate key ID %s", keyID)
+			}
+
+			if keyType == crypto.AES256 {
+				if pub != nil {
+					t.Fatal("Create(AES256) returned a public key")
+				}
+				if _, err := b.Get(keyID); err == nil {
+					t.Fatal("Get of a symmetric key should fail")
+				}
+			} else {
+				got, err := b.Get(keyID)
+				if err != nil {
+					t.Fatalf("Get failed: %v", err)
+				}
+				if !bytes.Equal(got, pub) {
+					t.Fatal("Get returned a different public key than Create")
+				}
+				kp, err := crypto.ParsePublicKey(got, "")
+				if err != nil {
+					t.Fatalf("public key does not parse: %v", err)
+				}
+				if kp.Algorithm != crypto.KeyTypeAlgorithm(keyType) {
+					t.Fatalf("public key algorithm = %s, want %s", kp.Algorithm, crypto.KeyTypeAlgorithm(keyType))
+				}
+			}
+
+			ids := mustList(t, b)
+			if !ids[keyID] || !ids[otherID] {
+				t.Fatalf("List does not contain created keys: %v", ids)
+			}
+			if err := b.Delete(keyID); err != nil {
+				t.Fatalf("Delete failed: %v", err)
+			}
+			if ids := mustList(t, b); ids[keyID] || !ids[otherID] {
+				t.Fatalf("List after Delete = %v", ids)
+			}
+			if keyType != crypto.AES256 {
+				if _, err := b.Get(keyID); err == nil {
+					t.Fatal("Get of a deleted key should fail")
+				}
+				if _, err := b.Sign(keyID, signingInput(keyType, []byte("deleted"))); err == nil {
+					t.Fatal("Sign with a deleted key should fail")
+				}
+			} else if _, err := b.Encrypt(keyID, []byte("deleted")); err == nil {
+				t.Fatal("Encrypt with a deleted key should fail")
+			}
+			if err := b.Delete(keyID); err == nil {
+				t.Fatal("deleting a key twice should fail")
+			}
+		})
+	}
+}
+
+func (s *suite) testSignVerify(t *testing.T) {
+	cfg := verifyConfig()
+	for _, keyType := range s.signingKeyTypes() {
+		keyType := keyType
+		t.Run(string(keyType), func(t *testing.T) {
+			b := s.factory(t)
+			keyID, pub := s.create(t, b, keyType)
+			otherID, _ := s.create(t, b, keyType)
+			msg := []byte("kmtest message for " + string(keyType))
+			input := signingInput(keyType, msg)
+
+			sig, err := b.Sign(keyID, input)
+			if err != nil {
+				t.Fatalf("Sign failed: %v", err)
+			}
+			if valid, err := b.Verify(keyID, input, sig); err != nil || !valid {
+				t.Fatalf("Verify = %v, %v", valid, err)
+			}
+
+			// 与普通验签函数交叉验证
+			kp, err := crypto.ParsePublicKey(pub, "")
+			if err != nil {
+				t.Fatalf("public key does not parse: %v", err)
+			}
+			res, err := crypto.VerifySignature(cfg, kp, msg, sig, crypto.KeyTypeAlgorithm(keyType))
+			if err != nil || !res.Valid {
+				t.Fatalf("crypto.VerifySignature rejected the backend signature: %v", err)
+			}
+
+			tamperedInput := append([]byte(nil), input...)
+			tamperedInput[0] ^= 0xff
+			if valid, err := b.Verify(keyID, tamperedInput, sig); err != nil || valid {
+				t.Fatalf("Verify of tampered data = %v, %v; want false, nil", valid, err)
+			}
+			tamperedSig := append([]byte(nil), sig...)
+			tamperedSig[len(tamperedSig)-1] ^= 0x01
+			if valid, _ := b.Verify(keyID, input, tamperedSig); valid {
+				t.Fatal("Verify accepted a tampered signature")
+			}
+			if valid, err := b.Verify(otherID, input, sig); err != nil || valid {
+				t.Fatalf("Verify with another key = %v, %v; want false, nil", valid, err)
+			}
+		})
+	}
+	if s.supports(crypto.AES256) {
+		t.Run(string(crypto.AES256), func(t *testing.T) {
+			b := s.factory(t)
+			keyID, _ := s.create(t, b, crypto.AES256)
+			if _, err := b.Sign(keyID, signingInput(crypto.ECDSAP256, []byte("symmetric"))); err == nil {
+				t.Fatal("Sign with a symmetric key should fail")
+			}
+		})
+	}
+	if s.supports(crypto.RSA2048) {
+		t.Run("RSADigest", s.testRSADigest)
+	}
+}
+
+// testRSADigest 检查RSA的Sign只接受SHA-256摘要，以及crypto.RSADigestSigner：
+// 签名为标准PKCS#1 v1.5签名，摘要长度须与哈希算法一致，SHA-256时与Sign的签名相同
+func (s *suite) testRSADigest(t *testing.T) {
+	b := s.factory(t)
+	keyID, pub := s.create(t, b, crypto.RSA2048)
+	msg := []byte("kmtest RSA digest message")
+	digest := sha256.Sum256(msg)
+	for _, size := range []int{20, 48, 64} {
+		if _, err := b.Sign(keyID, make([]byte, size)); err == nil {
+			t.Fatalf("Sign accepted a %d-byte RSA input", size)
+		}
+	}
+	signer, ok := b.(crypto.RSADigestSigner)
+	if !ok {
+		t.Skip("backend does not implement crypto.RSADigestSigner")
+	}
+
+	sig, err := signer.SignRSADigest(keyID, stdcrypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("SignRSADigest failed: %v", err)
+	}
+	kp, err := crypto.ParsePublicKey(pub, "")
+	if err != nil {
+		t.Fatalf("public key does not parse: %v", err)
+	}
+	res, err := crypto.VerifySignature(verifyConfig(), kp, msg, sig, crypto.KeyTypeAlgorithm(crypto.RSA2048))
+	if err != nil || !res.Valid {
+		t.Fatalf("crypto.VerifySignature rejected the SignRSADigest signature: %v", err)
+	}
+	if valid, err := signer.VerifyRSADigest(keyID, stdcrypto.SHA256, digest[:], sig); err != nil || !valid {
+		t.Fatalf("VerifyRSADigest = %v, %v", valid, err)
+	}
+	// PKCS#1 v1.5签名是确定性的
+	if viaSign, err := b.Sign(keyID, digest[:]); err != nil || !bytes.Equal(viaSign, sig) {
+		t.Fatalf("Sign of a SHA-256 digest differs from SignRSADigest: %v", err)
+	}
+	tampered := digest
+	tampered[0] ^= 0xff
+	if valid, err := signer.VerifyRSADigest(keyID, stdcrypto.SHA256, tampered[:], sig); err != nil || valid {
+		t.Fatalf("VerifyRSADigest of tampered digest = %v, %v; want false, nil", valid, err)
+	}
+
+	digest384 := sha512.Sum384(msg)
+	sig384, err := signer.SignRSADigest(keyID, stdcrypto.SHA384, digest384[:])
+	if err != nil {
+		t.Fatalf("SignRSADigest(SHA-384) failed: %v", err)
+	}
+	if valid, err := signer.VerifyRSADigest(keyID, stdcrypto.SHA384, digest384[:], sig384); err != nil || !valid {
+		t.Fatalf("VerifyRSADigest(SHA-384) = %v, %v", valid, err)
+	}
+	if valid, _ := signer.VerifyRSADigest(keyID, stdcrypto.SHA256, digest[:], sig384); valid {
+		t.Fatal("VerifyRSADigest accepted a signature made with another hash")
+	}
+
+	if _, err := signer.SignRSADigest(keyID, stdcrypto.SHA256, digest384[:]); err == nil {
+		t.Fatal("SignRSADigest accepted a digest whose length does not match the hash")
+	}
+	if _, err := signer.SignRSADigest(keyID, stdcrypto.MD5, digest[:16]); err == nil {
+		t.Fatal("SignRSADigest accepted an unsupported hash")
+	}
+}
+
+func (s *suite) testEncryptDecrypt(t *testing.T) {
+	for _, keyType := range s.keyTypes {
+		keyType := keyType
+		t.Run(string(keyType), func(t *testing.T) {
+			b := s.factory(t)
+			plaintext := []byte("kmtest plaintext for " + string(keyType))
+			if !s.encrypts(keyType) {
+				keyID, _ := s.create(t, b, keyType)
+				if _, err := b.Encrypt(keyID, plaintext); err == nil {
+					t.Fatalf("Encrypt with %s should fail", keyType)
+				}
+				return
+			}
+
+			keyID, _, err := b.Create(keyType, crypto.WithPurpose(crypto.PurposeEncryption))
+			if err != nil {
+				t.Fatalf("Create(%s, encryption) failed: %v", keyType, err)
+			}
+			otherID, _, err := b.Create(keyType, crypto.WithPurpose(crypto.PurposeEncryption))
+			if err != nil {
+				t.Fatalf("Create(%s, encryption) failed: %v", keyType, err)
+			}
+			ct, err := b.Encrypt(keyID, plaintext)
+			if err != nil {
+				t.Fatalf("Encrypt failed: %v", err)
+			}
+			if bytes.Contains(ct, plaintext) {
+				t.Fatal("ciphertext contains the plaintext")
+			}
+			got, err := b.Decrypt(keyID, ct)
+			if err != nil || !bytes.Equal(got, plaintext) {
+				t.Fatalf("Decrypt = %q, %v", got, err)
+			}
+			ct2, err := b.Encrypt(keyID, plaintext)
+			if err != nil {
+				t.Fatalf("Encrypt failed: %v", err)
+			}
+			if bytes.Equal(ct, ct2) {
+				t.Fatal("encryption is not randomized")
+			}
+
+			tampered := append([]byte(nil), ct...)
+			tampered[len(tampered)-1] ^= 0x01
+			if _, err := b.Decrypt(keyID, tampered); err == nil {
+				t.Fatal("Decrypt accepted a tampered ciphertext")
+			}
+			if _, err := b.Decrypt(keyID, ct[:len(ct)/2]); err == nil {
+				t.Fatal("Decrypt accepted a truncated ciphertext")
+			}
+			if got, err := b.Decrypt(otherID, ct); err == nil && bytes.Equal(got, plaintext) {
+				t.Fatal("ciphertext decrypted with another key")
+			}
+		})
+	}
+}
+
+func (s *suite) testImportExport(t *testing.T) {
+	for _, keyType := range s.keyTypes {
+		keyType := keyType
+		t.Run(string(keyType), func(t *testing.T) {
+			b := s.factory(t)
+			keyID, pub := s.create(t, b, keyType)
+			exported, err := b.ExportPrivateKey(keyID)
+			if !s.exportable {
+				if err == nil {
+					t.Fatal("ExportPrivateKey should not be supported")
+				}
+				if _, err := b.ImportPrivateKey([]byte("kmtest"), keyType); err == nil {
+					t.Fatal("ImportPrivateKey should not be supported")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExportPrivateKey failed: %v", err)
+			}
+			importedID, err := b.ImportPrivateKey(exported, keyType)
+			if err != nil {
+				t.Fatalf("ImportPrivateKey failed: %v", err)
+			}
+			if importedID == keyID {
+				t.Fatal("ImportPrivateKey reused the original key ID")
+			}
+			if _, err := b.ImportPrivateKey([]byte("not a key"), keyType); err == nil {
+				t.Fatal("ImportPrivateKey accepted garbage")
+			}
+
+			if keyType == crypto.AES256 {
+				ct, err := b.Encrypt(keyID, []byte("exported"))
+				if err != nil {
+					t.Fatalf("Encrypt failed: %v", err)
+				}
+				if got, err := b.Decrypt(importedID, ct); err != nil || string(got) != "exported" {
+					t.Fatalf("imported key cannot decrypt: %v", err)
+				}
+				return
+			}
+			if got, err := b.Get(importedID); err != nil || !bytes.Equal(got, pub) {
+				t.Fatalf("imported key has a different public key: %v", err)
+			}
+			input := signingInput(keyType, []byte("imported"))
+			sig, err := b.Sign(importedID, input)
+			if err != nil {
+				t.Fatalf("Sign with imported key failed: %v", err)
+			}
+			if valid, err := b.Verify(keyID, input, sig); err != nil || !valid {
+				t.Fatalf("original key does not verify the imported key's signature: %v", err)
+			}
+		})
+	}
+}
+
+func (s *suite) testUnknownKey(t *testing.T) {
+	b := s.factory(t)
+	const missing = "kmtest-missing-key"
+	input := signingInput(crypto.ECDSAP256, []byte("missing"))
+	if _, err := b.Get(missing); err == nil {
+		t.Error("Get of an unknown key should fail")
+	}
+	if _, err := b.Sign(missing, input); err == nil {
+		t.Error("Sign with an unknown key should fail")
+	}
+	if valid, err := b.Verify(missing, input, make([]byte, 64)); err == nil || valid {
+		t.Errorf("Verify with an unknown key = %v, %v; want false and an error", valid, err)
+	}
+	if _, err := b.Encrypt(missing, []byte("missing")); err == nil {
+		t.Error("Encrypt with an unknown key should fail")
+	}
+	if _, err := b.Decrypt(missing, make([]byte, 64)); err == nil {
+		t.Error("Decrypt with an unknown key should fail")
+	}
+	if _, err := b.ExportPrivateKey(missing); err == nil {
+		t.Error("ExportPrivateKey of an unknown key should fail")
+	}
+	if err := b.Delete(missing); err == nil {
+		t.Error("Delete of an unknown key should fail")
+	}
+}
+
+func (s *suite) testUnsupportedKeyType(t *testing.T) {
+	b := s.factory(t)
+	if _, _, err := b.Create(crypto.KeyType("KMTEST-UNKNOWN")); err == nil {
+		t.Error("Create with an unknown key type should fail")
+	}
+	if _, err := b.ImportPrivateKey([]byte("kmtest"), crypto.KeyType("KMTEST-UNKNOWN")); err == nil {
+		t.Error("ImportPrivateKey with an unknown key type should fail")
+	}
+}
+
+func (s *suite) testConcurrency(t *testing.T) {
+	keyTypes := s.signingKeyTypes()
+	if len(keyTypes) == 0 {
+		t.Skip("backend has no signing key types")
+	}
+	b := s.factory(t)
+	var wg sync.WaitGroup
+	errs := make(chan error, s.concurrency)
+	created := make(chan string, s.concurrency)
+	for i := 0; i < s.concurrency; i++ {
+		keyType := keyTypes[i%len(keyTypes)]
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := exerciseKey(b, keyType, i, created); err != nil {
+				errs <- fmt.Errorf("worker %d (%s): %w", i, keyType, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	close(created)
+	for err := range errs {
+		t.Error(err)
+	}
+	ids := mustList(t, b)
+	for id := range created {
+		if ids[id] {
+			t.Errorf("deleted key %s is still listed", id)
+		}
+	}
+}
+
+// exerciseKey 在一个goroutine中完成创建、签名验签、列举和删除
+func exerciseKey(b Backend, keyType crypto.KeyType, i int, created chan<- string) error {
+	keyID, _, err := b.Create(keyType)
+	if err != nil {
+		return fmt.Errorf("Create: %w", err)
+	}
+	created <- keyID
+	for j := 0; j < 3; j++ {
+		input := signingInput(keyType, []byte(fmt.Sprintf("worker %d message %d", i, j)))
+		sig, err := b.Sign(keyID, input)
+		if err != nil {
+			return fmt.Errorf("Sign: %w", err)
+		}
+		if valid, err := b.Verify(keyID, input, sig); err != nil || !valid {
+			return fmt.Errorf("Verify = %v, %v", valid, err)
+		}
+	}
+	ids, err := b.List()
+	if err != nil {
+		return fmt.Errorf("List: %w", err)
+	}
+	found := false
+	for _, id := range ids {
+		found = found || id == keyID
+	}
+	if !found {
+		return fmt.Errorf("List does not contain %s", keyID)
+	}
+	if err := b.Delete(keyID); err != nil {
+		return fmt.Errorf("Delete: %w", err)
+	}
+	return nil
+}
+
+// signingInput 按Crypto约定返回Sign的输入：ECDSA/RSA为摘要，SM2/Ed25519为原始消息
+func signingInput(keyType crypto.KeyType, msg []byte) []byte {
+	switch keyType {
+	case crypto.ECDSAP384:
+		sum := sha512.Sum384(msg)
+		return sum[:]
+	case crypto.ECDSAP256, crypto.ECDSASecp256k1, crypto.RSA2048:
+		sum := sha256.Sum256(msg)
+		return sum[:]
+	default:
+		return msg
+	}
+}
+
+// verifyConfig crypto.VerifySignature所需的配置，验签不访问这些地址
+func verifyConfig() *config.Config {
+	cfg := config.NewConfig()
+	cfg.HuaweiCloudEndpoint = "https://kms.example.com"
+	cfg.HuaweiCloudAccessKey = "kmtest"
+	cfg.HuaweiCloudSecretKey = "kmtest"
+	cfg.OpenAPIEndpoint = "https://openapi.example.com"
+	cfg.ProjectID = "kmtest"
+	return cfg
+}
+
+func mustList(t *testing.T, b Backend) map[string]bool {
+	t.Helper()
+	ids, err := b.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+func containsKeyType(keyTypes []crypto.KeyType, keyType crypto.KeyType) bool {
+	for _, kt := range keyTypes {
+		if kt == keyType {
+			return true
+		}
+	}
+	return false
+}
@@ -2,22 +2,25 @@ package crypto
 
 import (
 	"crypto"
-	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/hex"
 	"fmt"
-	"math/big"
 
 	"github.com/helailiang/sbp-did-sdk-go/pkg/config"
+	"github.com/tjfoc/gmsm/sm2"
 )
 
 // SignatureResult 签名结果
 type SignatureResult struct {
-	Signature string `json:"signature"`
-	Algorithm string `json:"algorithm"`
-	KeyID     string `json:"key_id,omitempty"`
+	Signature string          `json:"signature"`
+	Algorithm string          `json:"algorithm"`
+	Format    SignatureFormat `json:"format,omitempty"`
+	KeyID     string          `json:"key_id,omitempty"`
 }
 
 // VerificationResult 验证结果
@@ -28,7 +31,14 @@ type VerificationResult struct {
 }
 
 // Sign 签名数据 (SDK-020)
+// ECDSA类算法输出P1363定长格式 r || s
 func Sign(cfg *config.Config, privateKey interface{}, data []byte, algorithm string) (*SignatureResult, error) {
+	return SignWithFormat(cfg, privateKey, data, algorithm, "")
+}
+
+// SignWithFormat 按指定格式签名数据
+// format仅适用于ECDSA类算法，为空时使用SignatureFormatP1363；其他算法format须为空
+func SignWithFormat(cfg *config.Config, privateKey interface{}, data []byte, algorithm string, format SignatureFormat) (*SignatureResult, error) {
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
@@ -45,16 +55,25 @@ func Sign(cfg *config.Config, privateKey interface{}, data []byte, algorithm str
 		return nil, fmt.Errorf("data to sign cannot be empty")
 	}
 
+	if format != "" && !IsECAlgorithm(algorithm) {
+		return nil, fmt.Errorf("signature format is not supported for algorithm: %s", algorithm)
+	}
+
 	var signature []byte
 	var err error
 
 	switch algorithm {
-	case "ECDSA":
-		signature, err = signWithECDSA(privateKey, data)
+	case AlgorithmECDSA, AlgorithmSecp256k1, AlgorithmP256, AlgorithmP384:
+		if format == "" {
+			format = SignatureFormatP1363
+		}
+		signature, err = signWithECDSA(privateKey, data, algorithm, format)
 	case "RSA":
 		signature, err = signWithRSA(privateKey, data)
 	case "SM2":
 		signature, err = signWithSM2(privateKey, data)
+	case "ED25519":
+		signature, err = signWithEd25519(privateKey, data)
 	default:
 		return nil, fmt.Errorf("unsupported signature algorithm: %s", algorithm)
 	}
@@ -66,6 +85,7 @@ func Sign(cfg *config.Config, privateKey interface{}, data []byte, algorithm str
 	return &SignatureResult{
 		Signature: hex.EncodeToString(signature),
 		Algorithm: algorithm,
+		Format:    format,
 	}, nil
 }
 
@@ -95,12 +115,14 @@ func VerifySignature(cfg *config.Config, publicKey interface{}, data []byte, sig
 	var err error
 
 	switch algorithm {
-	case "ECDSA":
-		valid, err = verifyWithECDSA(publicKey, data, signature)
+	case AlgorithmECDSA, AlgorithmSecp256k1, AlgorithmP256, AlgorithmP384:
+		valid, err = verifyWithECDSA(publicKey, data, signature, algorithm)
 	case "RSA":
 		valid, err = verifyWithRSA(publicKey, data, signature)
 	case "SM2":
 		valid, err = verifyWithSM2(publicKey, data, signature)
+	case "ED25519":
+		valid, err = verifyWithEd25519(publicKey, data, signature)
 	default:
 		return nil, fmt.Errorf("unsupported verification algorithm: %s", algorithm)
 	}
@@ -121,59 +143,53 @@ func VerifySignature(cfg *config.Config, publicKey interface{}, data []byte, sig
 	return result, nil
 }
 
-func signWithECDSA(privateKey interface{}, data []byte) ([]byte, error) {
-	var privKey *ecdsa.PrivateKey
-	switch pk := privateKey.(type) {
-	case *ecdsa.PrivateKey:
-		privKey = pk
-	case *KeyPair:
-		if ecdsaKey, ok := pk.PrivateKey.(*ecdsa.PrivateKey); ok {
-			privKey = ecdsaKey
-		} else {
-			return nil, fmt.Errorf("invalid ECDSA private key type")
-		}
-	default:
-		return nil, fmt.Errorf("unsupported private key type for ECDSA signing")
-	}
-
-	hash := sha256.Sum256(data)
-	r, s, err := ecdsa.Sign(rand.Reader, privKey, hash[:])
+// signWithECDSA 使用ECDSA签名，输出指定格式的low-S签名
+// P-384使用SHA-384，secp256k1和P-256使用SHA-256；历史标识ECDSA不限定曲线
+func signWithECDSA(privateKey interface{}, data []byte, algorithm string, format SignatureFormat) ([]byte, error) {
+	privKey, err := ToECDSAPrivateKey(privateKey)
 	if err != nil {
-		return nil, fmt.Errorf("ECDSA signing failed: %w", err)
+		return nil, fmt.Errorf("invalid ECDSA private key: %w", err)
+	}
+	if err := checkECAlgorithm(privKey.Curve, algorithm); err != nil {
+		return nil, err
 	}
 
-	// 将r和s组合成签名
-	signature := append(r.Bytes(), s.Bytes()...)
-	return signature, nil
+	return SignECDSA(rand.Reader, privKey, ecdsaDigest(privKey.Curve, data), format)
 }
 
-func verifyWithECDSA(publicKey interface{}, data []byte, signature []byte) (bool, error) {
-	var pubKey *ecdsa.PublicKey
-	switch pk := publicKey.(type) {
-	case *ecdsa.PublicKey:
-		pubKey = pk
-	case *KeyPair:
-		if ecdsaKey, ok := pk.PublicKey.(*ecdsa.PublicKey); ok {
-			pubKey = ecdsaKey
-		} else {
-			return false, fmt.Errorf("invalid ECDSA public key type")
-		}
-	default:
-		return false, fmt.Errorf("unsupported public key type for ECDSA verification")
+// verifyWithECDSA 验证ECDSA签名，自动识别DER、P1363定长和可恢复格式
+func verifyWithECDSA(publicKey interface{}, data []byte, signature []byte, algorithm string) (bool, error) {
+	pubKey, err := ToECDSAPublicKey(publicKey)
+	if err != nil {
+		return false, fmt.Errorf("invalid ECDSA public key: %w", err)
+	}
+	if err := checkECAlgorithm(pubKey.Curve, algorithm); err != nil {
+		return false, err
 	}
 
-	hash := sha256.Sum256(data)
+	return VerifyECDSA(pubKey, ecdsaDigest(pubKey.Curve, data), signature), nil
+}
 
-	// 从签名中提取r和s
-	if len(signature) < 64 {
-		return false, fmt.Errorf("invalid signature length")
+// checkECAlgorithm 校验密钥曲线与签名算法一致，历史标识ECDSA接受任意支持的曲线
+func checkECAlgorithm(curve elliptic.Curve, algorithm string) error {
+	keyAlg, err := ECAlgorithmForCurve(curve)
+	if err != nil {
+		return err
 	}
+	if algorithm != AlgorithmECDSA && keyAlg != NormalizeECAlgorithm(algorithm) {
+		return fmt.Errorf("key curve %s does not match algorithm %s", curve.Params().Name, algorithm)
+	}
+	return nil
+}
 
-	r := new(big.Int).SetBytes(signature[:32])
-	s := new(big.Int).SetBytes(signature[32:64])
-
-	valid := ecdsa.Verify(pubKey, hash[:], r, s)
-	return valid, nil
+// ecdsaDigest 按曲线选择摘要算法
+func ecdsaDigest(curve elliptic.Curve, data []byte) []byte {
+	if curveByteSize(curve) > 32 {
+		hash := sha512.Sum384(data)
+		return hash[:]
+	}
+	hash := sha256.Sum256(data)
+	return hash[:]
 }
 
 func signWithRSA(privateKey interface{}, data []byte) ([]byte, error) {
@@ -224,16 +240,97 @@ func verifyWithRSA(publicKey interface{}, data []byte, signature []byte) (bool,
 	return true, nil
 }
 
+// signWithSM2 使用SM3withSM2签名，用户标识为SM2DefaultUID，签名为DER编码
 func signWithSM2(privateKey interface{}, data []byte) ([]byte, error) {
-	// SM2签名实现，这里简化处理
-	// 实际应该使用SM2算法
-	return signWithECDSA(privateKey, data)
+	var privKey *sm2.PrivateKey
+	switch pk := privateKey.(type) {
+	case *sm2.PrivateKey:
+		privKey = pk
+	case *KeyPair:
+		if sm2Key, ok := pk.PrivateKey.(*sm2.PrivateKey); ok {
+			privKey = sm2Key
+		} else {
+			return nil, fmt.Errorf("invalid SM2 private key type")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported private key type for SM2 signing")
+	}
+
+	signature, err := SignSM2(rand.Reader, privKey, data, SM2DefaultUID)
+	if err != nil {
+		return nil, fmt.Errorf("SM2 signing failed: %w", err)
+	}
+	return signature, nil
 }
 
+// verifyWithSM2 验证SM3withSM2签名，用户标识为SM2DefaultUID
 func verifyWithSM2(publicKey interface{}, data []byte, signature []byte) (bool, error) {
-	// SM2验证实现，这里简化处理
-	// 实际应该使用SM2算法
-	return verifyWithECDSA(publicKey, data, signature)
+	var pubKey *sm2.PublicKey
+	switch pk := publicKey.(type) {
+	case *sm2.PublicKey:
+		pubKey = pk
+	case *sm2.PrivateKey:
+		pubKey = &pk.PublicKey
+	case *KeyPair:
+		if sm2Key, ok := pk.PublicKey.(*sm2.PublicKey); ok {
+			pubKey = sm2Key
+		} else {
+			return false, fmt.Errorf("invalid SM2 public key type")
+		}
+	default:
+		return false, fmt.Errorf("unsupported public key type for SM2 verification")
+	}
+
+	return VerifySM2(pubKey, data, SM2DefaultUID, signature), nil
+}
+
+// signWithEd25519 使用Ed25519签名（RFC 8032），对原始消息签名，不做预哈希
+func signWithEd25519(privateKey interface{}, data []byte) ([]byte, error) {
+	var privKey ed25519.PrivateKey
+	switch pk := privateKey.(type) {
+	case ed25519.PrivateKey:
+		privKey = pk
+	case *KeyPair:
+		if edKey, ok := pk.PrivateKey.(ed25519.PrivateKey); ok {
+			privKey = edKey
+		} else {
+			return nil, fmt.Errorf("invalid Ed25519 private key type")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported private key type for Ed25519 signing")
+	}
+
+	if len(privKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid Ed25519 private key length: %d", len(privKey))
+	}
+	return ed25519.Sign(privKey, data), nil
+}
+
+// verifyWithEd25519 验证Ed25519签名
+func verifyWithEd25519(publicKey interface{}, data []byte, signature []byte) (bool, error) {
+	var pubKey ed25519.PublicKey
+	switch pk := publicKey.(type) {
+	case ed25519.PublicKey:
+		pubKey = pk
+	case ed25519.PrivateKey:
+		pubKey = pk.Public().(ed25519.PublicKey)
+	case *KeyPair:
+		if edKey, ok := pk.PublicKey.(ed25519.PublicKey); ok {
+			pubKey = edKey
+		} else {
+			return false, fmt.Errorf("invalid Ed25519 public key type")
+		}
+	default:
+		return false, fmt.Errorf("unsupported public key type for Ed25519 verification")
+	}
+
+	if len(pubKey) != ed25519.PublicKeySize {
+		return false, fmt.Errorf("invalid Ed25519 public key length: %d", len(pubKey))
+	}
+	if len(signature) != ed25519.SignatureSize {
+		return false, nil
+	}
+	return ed25519.Verify(pubKey, data, signature), nil
 }
 
 func SignFromHex(cfg *config.Config, privateKey interface{}, dataHex string, algorithm string) (*SignatureResult, error) {
@@ -0,0 +1,278 @@
+package crypto
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/helailiang/sbp-did-sdk-go/pkg/utils"
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+// SM2实现遵循GM/T 0003-2012（GB/T 32918）：曲线为sm2p256v1，
+// 签名对 SM3(ZA || M) 进行，ZA由用户标识与公钥计算；签名编码为DER SEQUENCE{r, s}（GM/T 0009）
+
+// SM2DefaultUID GM/T 0009规定的默认用户标识
+var SM2DefaultUID = []byte("1234567812345678")
+
+var (
+	// oidPublicKeyECDSA id-ecPublicKey，SM2公钥与ECDSA共用
+	oidPublicKeyECDSA = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	// oidNamedCurveSM2 sm2p256v1曲线
+	oidNamedCurveSM2 = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 301}
+)
+
+var sm2One = big.NewInt(1)
+
+// SM2Curve 返回sm2p256v1曲线
+func SM2Curve() elliptic.Curve {
+	return sm2.P256Sm2()
+}
+
+// GenerateSM2Key 生成SM2私钥，random为nil时使用crypto/rand
+func GenerateSM2Key(random io.Reader) (*sm2.PrivateKey, error) {
+	if random == nil {
+		random = rand.Reader
+	}
+	return sm2.GenerateKey(random)
+}
+
+// SM2ZA 计算用户杂凑值 ZA = SM3(ENTLA || ID || a || b || xG || yG || xA || yA)
+// uid为空时使用SM2DefaultUID
+func SM2ZA(pub *sm2.PublicKey, uid []byte) ([]byte, error) {
+	if pub == nil || pub.X == nil || pub.Y == nil {
+		return nil, errors.New("invalid SM2 public key")
+	}
+	if len(uid) == 0 {
+		uid = SM2DefaultUID
+	}
+	if len(uid) >= 8192 {
+		return nil, errors.New("SM2 user id too long")
+	}
+	params := SM2Curve().Params()
+	// sm2p256v1 的 a = p - 3
+	a := new(big.Int).Sub(params.P, big.NewInt(3))
+	entla := uint16(len(uid) * 8)
+
+	h := utils.NewSM3()
+	h.Write([]byte{byte(entla >> 8), byte(entla)})
+	h.Write(uid)
+	for _, v := range []*big.Int{a, params.B, params.Gx, params.Gy, pub.X, pub.Y} {
+		h.Write(v.FillBytes(make([]byte, 32)))
+	}
+	return h.Sum(nil), nil
+}
+
+// sm2Digest 计算待签名摘要 e = SM3(ZA || M)
+func sm2Digest(pub *sm2.PublicKey, uid, msg []byte) (*big.Int, error) {
+	za, err := SM2ZA(pub, uid)
+	if err != nil {
+		return nil, err
+	}
+	h := utils.NewSM3()
+	h.Write(za)
+	h.Write(msg)
+	return new(big.Int).SetBytes(h.Sum(nil)), nil
+}
+
+// sm2RandScalar 生成[1, n-1]范围内的随机数
+func sm2RandScalar(random io.Reader) (*big.Int, error) {
+	params := SM2Curve().Params()
+	b := make([]byte, params.BitSize/8+8)
+	if _, err := io.ReadFull(random, b); err != nil {
+		return nil, err
+	}
+	k := new(big.Int).SetBytes(b)
+	n := new(big.Int).Sub(params.N, sm2One)
+	k.Mod(k, n)
+	return k.Add(k, sm2One), nil
+}
+
+// SignSM2 使用SM3withSM2对消息签名，返回DER编码的签名
+// random为nil时使用crypto/rand，uid为空时使用SM2DefaultUID
+func SignSM2(random io.Reader, priv *sm2.PrivateKey, msg, uid []byte) ([]byte, error) {
+	if priv == nil || priv.D == nil {
+		return nil, errors.New("invalid SM2 private key")
+	}
+	if random == nil {
+		random = rand.Reader
+	}
+	e, err := sm2Digest(&priv.PublicKey, uid, msg)
+	if err != nil {
+		return nil, err
+	}
+	curve := SM2Curve()
+	n := curve.Params().N
+	// (1 + d)^-1
+	dInv := new(big.Int).Add(priv.D, sm2One)
+	dInv.ModInverse(dInv, n)
+	for {
+		k, err := sm2RandScalar(random)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate SM2 nonce: %w", err)
+		}
+		x1, _ := curve.ScalarBaseMult(k.Bytes())
+		r := new(big.Int).Add(e, x1)
+		r.Mod(r, n)
+		if r.Sign() == 0 || new(big.Int).Add(r, k).Cmp(n) == 0 {
+			continue
+		}
+		s := new(big.Int).Mul(r, priv.D)
+		s.Sub(k, s)
+		s.Mul(s, dInv)
+		s.Mod(s, n)
+		if s.Sign() == 0 {
+			continue
+		}
+		return asn1.Marshal(sm2Signature{R: r, S: s})
+	}
+}
+
+// sm2Signature SM2签名的ASN.1结构
+type sm2Signature struct {
+	R, S *big.Int
+}
+
+// VerifySM2 验证DER编码的SM3withSM2签名，uid为空时使用SM2DefaultUID
+func VerifySM2(pub *sm2.PublicKey, msg, uid, signature []byte) bool {
+	var sig sm2Signature
+	rest, err := asn1.Unmarshal(signature, &sig)
+	if err != nil || len(rest) != 0 || sig.R == nil || sig.S == nil {
+		return false
+	}
+	curve := SM2Curve()
+	n := curve.Params().N
+	if sig.R.Cmp(sm2One) < 0 || sig.R.Cmp(n) >= 0 || sig.S.Cmp(sm2One) < 0 || sig.S.Cmp(n) >= 0 {
+		return false
+	}
+	e, err := sm2Digest(pub, uid, msg)
+	if err != nil {
+		return false
+	}
+	t := new(big.Int).Add(sig.R, sig.S)
+	t.Mod(t, n)
+	if t.Sign() == 0 {
+		return false
+	}
+	x1, y1 := curve.ScalarBaseMult(sig.S.Bytes())
+	x2, y2 := curve.ScalarMult(pub.X, pub.Y, t.Bytes())
+	x, _ := curve.Add(x1, y1, x2, y2)
+	x.Add(x, e)
+	x.Mod(x, n)
+	return x.Cmp(sig.R) == 0
+}
+
+// ========== 密钥编码 ========== //
+
+// 以下ASN.1结构由SM2与secp256k1共用（标准库x509不支持这两条曲线）
+
+type ecAlgorithmIdentifier struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters asn1.ObjectIdentifier
+}
+
+type ecSubjectPublicKeyInfo struct {
+	Algorithm ecAlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+type ecPKCS8 struct {
+	Version    int
+	Algorithm  ecAlgorithmIdentifier
+	PrivateKey []byte
+}
+
+// ecPrivateKeyInfo SEC1 ECPrivateKey结构
+type ecPrivateKeyInfo struct {
+	Version       int
+	PrivateKey    []byte
+	NamedCurveOID asn1.ObjectIdentifier `asn1:"optional,explicit,tag:0"`
+	PublicKey     asn1.BitString        `asn1:"optional,explicit,tag:1"`
+}
+
+// MarshalSM2PublicKey 将SM2公钥编码为SubjectPublicKeyInfo（DER）
+func MarshalSM2PublicKey(pub *sm2.PublicKey) ([]byte, error) {
+	if pub == nil || pub.X == nil || pub.Y == nil {
+		return nil, errors.New("invalid SM2 public key")
+	}
+	point := elliptic.Marshal(SM2Curve(), pub.X, pub.Y)
+	return asn1.Marshal(ecSubjectPublicKeyInfo{
+		Algorithm: ecAlgorithmIdentifier{Algorithm: oidPublicKeyECDSA, Parameters: oidNamedCurveSM2},
+		PublicKey: asn1.BitString{Bytes: point, BitLength: len(point) * 8},
+	})
+}
+
+// ParseSM2PublicKey 解析SubjectPublicKeyInfo（DER）或未压缩点格式的SM2公钥
+func ParseSM2PublicKey(der []byte) (*sm2.PublicKey, error) {
+	point := der
+	var spki ecSubjectPublicKeyInfo
+	if rest, err := asn1.Unmarshal(der, &spki); err == nil && len(rest) == 0 {
+		if !spki.Algorithm.Parameters.Equal(oidNamedCurveSM2) {
+			return nil, fmt.Errorf("not an SM2 public key, curve oid: %v", spki.Algorithm.Parameters)
+		}
+		point = spki.PublicKey.RightAlign()
+	}
+	curve := SM2Curve()
+	x, y := elliptic.Unmarshal(curve, point)
+	if x == nil {
+		return nil, errors.New("invalid SM2 public key point")
+	}
+	return &sm2.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// MarshalSM2PrivateKey 将SM2私钥编码为PKCS#8（DER）
+func MarshalSM2PrivateKey(priv *sm2.PrivateKey) ([]byte, error) {
+	if priv == nil || priv.D == nil {
+		return nil, errors.New("invalid SM2 private key")
+	}
+	point := elliptic.Marshal(SM2Curve(), priv.X, priv.Y)
+	ecKey, err := asn1.Marshal(ecPrivateKeyInfo{
+		Version:    1,
+		PrivateKey: priv.D.FillBytes(make([]byte, 32)),
+		PublicKey:  asn1.BitString{Bytes: point, BitLength: len(point) * 8},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(ecPKCS8{
+		Algorithm:  ecAlgorithmIdentifier{Algorithm: oidPublicKeyECDSA, Parameters: oidNamedCurveSM2},
+		PrivateKey: ecKey,
+	})
+}
+
+// ParseSM2PrivateKey 解析PKCS#8或SEC1（DER）格式的SM2私钥
+func ParseSM2PrivateKey(der []byte) (*sm2.PrivateKey, error) {
+	ecDER := der
+	var p8 ecPKCS8
+	if rest, err := asn1.Unmarshal(der, &p8); err == nil && len(rest) == 0 {
+		if !p8.Algorithm.Parameters.Equal(oidNamedCurveSM2) {
+			return nil, fmt.Errorf("not an SM2 private key, curve oid: %v", p8.Algorithm.Parameters)
+		}
+		ecDER = p8.PrivateKey
+	}
+	var ecKey ecPrivateKeyInfo
+	if _, err := asn1.Unmarshal(ecDER, &ecKey); err != nil {
+		return nil, fmt.Errorf("failed to parse SM2 private key: %w", err)
+	}
+	if len(ecKey.NamedCurveOID) > 0 && !ecKey.NamedCurveOID.Equal(oidNamedCurveSM2) {
+		return nil, fmt.Errorf("not an SM2 private key, curve oid: %v", ecKey.NamedCurveOID)
+	}
+	return NewSM2PrivateKey(ecKey.PrivateKey)
+}
+
+// NewSM2PrivateKey 由32字节私钥标量构造SM2私钥
+func NewSM2PrivateKey(d []byte) (*sm2.PrivateKey, error) {
+	curve := SM2Curve()
+	k := new(big.Int).SetBytes(d)
+	if k.Sign() == 0 || k.Cmp(new(big.Int).Sub(curve.Params().N, sm2One)) >= 0 {
+		return nil, errors.New("invalid SM2 private key scalar")
+	}
+	priv := &sm2.PrivateKey{D: k}
+	priv.Curve = curve
+	priv.X, priv.Y = curve.ScalarBaseMult(d)
+	return priv, nil
+}
@@ -1,14 +1,35 @@
 package did
 
 import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/helailiang/sbp-did-sdk-go/pkg/config"
 	"github.com/helailiang/sbp-did-sdk-go/pkg/crypto"
+	"github.com/helailiang/sbp-did-sdk-go/pkg/utils"
 )
 
+// 验证方法类型
+const (
+	TypeEcdsaSecp256k1VerificationKey2019 = "EcdsaSecp256k1VerificationKey2019"
+	TypeEd25519VerificationKey2020        = "Ed25519VerificationKey2020"
+	TypeJsonWebKey2020                    = "JsonWebKey2020"
+)
+
+// Ed25519Context Ed25519VerificationKey2020 所需的JSON-LD上下文
+const Ed25519Context = "https://w3id.org/security/suites/ed25519-2020/v1"
+
+// ed25519MulticodecPrefix multicodec ed25519-pub（0xed）的varint编码
+var ed25519MulticodecPrefix = []byte{0xed, 0x01}
+
 // DIDDocument 表示DID文档结构（兼容TrustBloc/W3C）
 type DIDDocument struct {
 	Context            []string             `json:"@context"`
@@ -30,11 +51,14 @@ type DIDDocument struct {
 
 // VerificationMethod 表示验证方法（兼容TrustBloc/W3C）
 type VerificationMethod struct {
-	ID              string      `json:"id"`
-	Type            string      `json:"type"`
-	Controller      string      `json:"controller"`
-	PublicKeyBase58 string      `json:"publicKeyBase58,omitempty"`
-	PublicKeyJwk    interface{} `json:"publicKeyJwk,omitempty"`
+	ID                 string                 `json:"id"`
+	Type               string                 `json:"type"`
+	Controller         string                 `json:"controller"`
+	PublicKeyBase58    string                 `json:"publicKeyBase58,omitempty"`
+	PublicKeyMultibase string                 `json:"publicKeyMultibase,omitempty"`
+	PublicKeyHex       string                 `json:"publicKeyHex,omitempty"`
+	PublicKeyJwk       interface{}            `json:"publicKeyJwk,omitempty"`
+	CustomFields       map[string]interface{} `json:"-"`
 }
 
 // PublicKeyJwk 表示JWK格式的公钥
@@ -106,6 +130,9 @@ func AssembleDIDDocument(cfg *config.Config, publicKey interface{}, algorithm, d
 	}
 
 	doc.VerificationMethod = []VerificationMethod{*verificationMethod}
+	if verificationMethod.Type == TypeEd25519VerificationKey2020 {
+		doc.Context = append(doc.Context, Ed25519Context)
+	}
 
 	// 添加认证方法引用
 	doc.Authentication = []string{verificationMethod.ID}
@@ -172,6 +199,45 @@ func (doc *DIDDocument) RemoveKey(keyID string) {
 	doc.CapabilityDelegation = removeStringFromSlice(doc.CapabilityDelegation, keyID)
 }
 
+// FindVerificationMethod 按密钥标识查找验证方法
+// kid可以是完整ID（did:...#keys-1）、片段（#keys-1 或 keys-1）或publicKeyJwk的kid；
+// kid为空且文档只有一个验证方法时返回该验证方法
+func (doc *DIDDocument) FindVerificationMethod(kid string) (*VerificationMethod, error) {
+	if kid == "" {
+		if len(doc.VerificationMethod) == 1 {
+			return &doc.VerificationMethod[0], nil
+		}
+		return nil, fmt.Errorf("key id is required to select a verification method of %s", doc.ID)
+	}
+	for i := range doc.VerificationMethod {
+		if MatchVerificationMethodID(&doc.VerificationMethod[i], kid) {
+			return &doc.VerificationMethod[i], nil
+		}
+	}
+	return nil, fmt.Errorf("verification method %s not found in %s", kid, doc.ID)
+}
+
+// MatchVerificationMethodID 判断kid是否指向该验证方法（完整ID、片段或publicKeyJwk的kid）
+func MatchVerificationMethodID(vm *VerificationMethod, kid string) bool {
+	if kid == "" {
+		return false
+	}
+	if vm.ID == kid {
+		return true
+	}
+	if i := strings.LastIndex(vm.ID, "#"); i >= 0 {
+		if fragment := vm.ID[i:]; fragment == kid || fragment[1:] == kid {
+			return true
+		}
+	}
+	if vm.PublicKeyJwk != nil {
+		if jwk, err := vm.GetPublicKeyJwk(); err == nil && jwk.Kid == kid {
+			return true
+		}
+	}
+	return false
+}
+
 // removeStringFromSlice 工具函数：从字符串切片中移除指定元素
 func removeStringFromSlice(slice []string, target string) []string {
 	result := []string{}
@@ -188,6 +254,24 @@ func createVerificationMethod(publicKey interface{}, algorithm, didIdentifier st
 	// 生成验证方法ID
 	vmID := fmt.Sprintf("%s#keys-1", didIdentifier)
 
+	// Ed25519使用publicKeyMultibase
+	if algorithm == "ED25519" {
+		pub, err := ed25519PublicKeyFrom(publicKey)
+		if err != nil {
+			return nil, err
+		}
+		return NewEd25519VerificationMethod(vmID, didIdentifier, pub, TypeEd25519VerificationKey2020)
+	}
+
+	// 椭圆曲线密钥按实际曲线确定验证方法类型
+	if crypto.IsECAlgorithm(algorithm) {
+		pub, err := ecPublicKeyFrom(publicKey, algorithm)
+		if err != nil {
+			return nil, err
+		}
+		return NewECVerificationMethod(vmID, didIdentifier, pub)
+	}
+
 	// 获取公钥的不同格式
 	var publicKeyHex string
 	var publicKeyJwk *PublicKeyJwk
@@ -199,17 +283,15 @@ func createVerificationMethod(publicKey interface{}, algorithm, didIdentifier st
 		if err != nil {
 			return nil, fmt.Errorf("failed to get public key hex: %w", err)
 		}
-		publicKeyJwk, err = createPublicKeyJwk(pk)
+		publicKeyJwk, err = NewPublicKeyJwk(pk)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create public key JWK: %w", err)
 		}
 	case string:
 		publicKeyHex = pk
-		// 对于字符串格式，尝试创建JWK
 		publicKeyJwk, err = createPublicKeyJwkFromHex(pk, algorithm)
 		if err != nil {
-			// 如果失败，设置为nil
-			publicKeyJwk = nil
+			return nil, fmt.Errorf("failed to create public key JWK: %w", err)
 		}
 	default:
 		return nil, fmt.Errorf("unsupported public key type: %T", publicKey)
@@ -218,14 +300,12 @@ func createVerificationMethod(publicKey interface{}, algorithm, didIdentifier st
 	// 确定验证方法类型
 	var vmType string
 	switch algorithm {
-	case "ECDSA":
-		vmType = "EcdsaSecp256k1VerificationKey2019"
 	case "RSA":
 		vmType = "RsaVerificationKey2018"
 	case "SM2":
-		vmType = "EcdsaSecp256k1VerificationKey2019" // SM2使用相同的类型
+		vmType = TypeJsonWebKey2020
 	default:
-		vmType = "EcdsaSecp256k1VerificationKey2019"
+		return nil, fmt.Errorf("unsupported algorithm: %s", algorithm)
 	}
 
 	vm := &VerificationMethod{
@@ -244,76 +324,148 @@ func createVerificationMethod(publicKey interface{}, algorithm, didIdentifier st
 	return vm, nil
 }
 
-// createPublicKeyJwk 从密钥对创建JWK格式的公钥
-func createPublicKeyJwk(keyPair *crypto.KeyPair) (*PublicKeyJwk, error) {
-	switch keyPair.Algorithm {
-	case "ECDSA":
-		return createECDSAJwk(keyPair)
-	case "RSA":
-		return createRSAJwk(keyPair)
-	case "SM2":
-		return createSM2Jwk(keyPair)
-	default:
-		return nil, fmt.Errorf("unsupported algorithm for JWK: %s", keyPair.Algorithm)
+// NewECVerificationMethod 创建椭圆曲线验证方法
+// secp256k1使用EcdsaSecp256k1VerificationKey2019，P-256/P-384使用JsonWebKey2020，均携带publicKeyJwk
+func NewECVerificationMethod(vmID, controller string, pub *ecdsa.PublicKey) (*VerificationMethod, error) {
+	jwk, err := NewPublicKeyJwk(pub)
+	if err != nil {
+		return nil, err
 	}
+	vmType := TypeJsonWebKey2020
+	if jwk.Crv == "secp256k1" {
+		vmType = TypeEcdsaSecp256k1VerificationKey2019
+	}
+	return &VerificationMethod{
+		ID:           vmID,
+		Type:         vmType,
+		Controller:   controller,
+		PublicKeyHex: hex.EncodeToString(elliptic.MarshalCompressed(pub.Curve, pub.X, pub.Y)),
+		PublicKeyJwk: jwk,
+		CustomFields: make(map[string]interface{}),
+	}, nil
 }
 
-// createPublicKeyJwkFromHex 从十六进制字符串创建JWK
-func createPublicKeyJwkFromHex(publicKeyHex, algorithm string) (*PublicKeyJwk, error) {
-	// 这里简化实现，实际应该根据算法解析公钥
-	switch algorithm {
-	case "ECDSA":
-		return &PublicKeyJwk{
-			Kty: "EC",
-			Crv: "secp256k1",
-			X:   publicKeyHex[:64], // 简化的实现
-			Y:   publicKeyHex[64:], // 简化的实现
-		}, nil
-	case "RSA":
-		return &PublicKeyJwk{
-			Kty: "RSA",
-			N:   publicKeyHex, // 简化的实现
-			E:   "AQAB",
-		}, nil
-	case "SM2":
-		return &PublicKeyJwk{
-			Kty: "EC",
-			Crv: "secp256k1", // SM2使用相同的曲线表示
-			X:   publicKeyHex[:64],
-			Y:   publicKeyHex[64:],
-		}, nil
-	default:
-		return nil, fmt.Errorf("unsupported algorithm: %s", algorithm)
+// ecPublicKeyFrom 从密钥对、公钥或十六进制字符串获取椭圆曲线公钥，并校验曲线与算法一致
+func ecPublicKeyFrom(publicKey interface{}, algorithm string) (*ecdsa.PublicKey, error) {
+	var pub *ecdsa.PublicKey
+	var err error
+	if hexKey, ok := publicKey.(string); ok {
+		curve, err := crypto.ECCurve(algorithm)
+		if err != nil {
+			return nil, err
+		}
+		point, err := hex.DecodeString(hexKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode hex public key: %w", err)
+		}
+		return crypto.ParseECPoint(curve, point)
 	}
+	if pub, err = crypto.ToECDSAPublicKey(publicKey); err != nil {
+		return nil, err
+	}
+	keyAlg, err := crypto.ECAlgorithmForCurve(pub.Curve)
+	if err != nil {
+		return nil, err
+	}
+	// 历史标识ECDSA以密钥实际曲线为准
+	if algorithm != crypto.AlgorithmECDSA && keyAlg != algorithm {
+		return nil, fmt.Errorf("key curve %s does not match algorithm %s", pub.Curve.Params().Name, algorithm)
+	}
+	return pub, nil
 }
 
-// createECDSAJwk 创建ECDSA的JWK
-func createECDSAJwk(keyPair *crypto.KeyPair) (*PublicKeyJwk, error) {
-	// 这里需要根据实际的ECDSA公钥实现
-	// 简化实现
+// newECJwk 创建椭圆曲线公钥的JWK（RFC 7518）
+func newECJwk(pub *ecdsa.PublicKey) (*PublicKeyJwk, error) {
+	crv, err := crypto.JWKCurveName(pub.Curve)
+	if err != nil {
+		return nil, err
+	}
+	size := (pub.Curve.Params().BitSize + 7) / 8
 	return &PublicKeyJwk{
 		Kty: "EC",
-		Crv: "secp256k1",
-		X:   "sample-x-coordinate",
-		Y:   "sample-y-coordinate",
+		Crv: crv,
+		X:   base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, size))),
+		Y:   base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, size))),
 	}, nil
 }
 
-// createRSAJwk 创建RSA的JWK
-func createRSAJwk(keyPair *crypto.KeyPair) (*PublicKeyJwk, error) {
-	// 这里需要根据实际的RSA公钥实现
-	// 简化实现
-	return &PublicKeyJwk{
-		Kty: "RSA",
-		N:   "sample-modulus",
-		E:   "AQAB",
-	}, nil
+// NewEd25519VerificationMethod 创建Ed25519验证方法
+// vmType为Ed25519VerificationKey2020时使用publicKeyMultibase，
+// 为JsonWebKey2020时同时携带publicKeyMultibase和OKP格式的publicKeyJwk（RFC 8037）
+func NewEd25519VerificationMethod(vmID, controller string, pub ed25519.PublicKey, vmType string) (*VerificationMethod, error) {
+	if len(pub) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid Ed25519 public key length: %d", len(pub))
+	}
+	vm := &VerificationMethod{
+		ID:                 vmID,
+		Type:               vmType,
+		Controller:         controller,
+		PublicKeyMultibase: Ed25519PublicKeyMultibase(pub),
+		CustomFields:       make(map[string]interface{}),
+	}
+	switch vmType {
+	case TypeEd25519VerificationKey2020:
+	case TypeJsonWebKey2020:
+		jwk, err := NewPublicKeyJwk(pub)
+		if err != nil {
+			return nil, err
+		}
+		vm.PublicKeyJwk = jwk
+	default:
+		return nil, fmt.Errorf("unsupported verification method type for Ed25519: %s", vmType)
+	}
+	return vm, nil
+}
+
+// Ed25519PublicKeyMultibase 将Ed25519公钥编码为multibase（base58btc，multicodec 0xed01）
+func Ed25519PublicKeyMultibase(pub ed25519.PublicKey) string {
+	return utils.EncodeMultibase(append(append([]byte(nil), ed25519MulticodecPrefix...), pub...))
+}
+
+// Ed25519PublicKeyFromMultibase 解析publicKeyMultibase格式的Ed25519公钥
+func Ed25519PublicKeyFromMultibase(s string) (ed25519.PublicKey, error) {
+	data, err := utils.DecodeMultibase(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != len(ed25519MulticodecPrefix)+ed25519.PublicKeySize ||
+		data[0] != ed25519MulticodecPrefix[0] || data[1] != ed25519MulticodecPrefix[1] {
+		return nil, fmt.Errorf("not an Ed25519 multibase public key")
+	}
+	return ed25519.PublicKey(data[len(ed25519MulticodecPrefix):]), nil
+}
+
+// ed25519PublicKeyFrom 从密钥对、公钥或十六进制字符串获取Ed25519公钥
+func ed25519PublicKeyFrom(publicKey interface{}) (ed25519.PublicKey, error) {
+	switch pk := publicKey.(type) {
+	case *crypto.KeyPair:
+		if pub, ok := pk.PublicKey.(ed25519.PublicKey); ok {
+			return pub, nil
+		}
+		return nil, fmt.Errorf("invalid Ed25519 public key type: %T", pk.PublicKey)
+	case ed25519.PublicKey:
+		return pk, nil
+	case string:
+		pub, err := hex.DecodeString(pk)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode hex public key: %w", err)
+		}
+		if len(pub) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 public key length: %d", len(pub))
+		}
+		return ed25519.PublicKey(pub), nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type: %T", publicKey)
+	}
 }
 
-// createSM2Jwk 创建SM2的JWK
-func createSM2Jwk(keyPair *crypto.KeyPair) (*PublicKeyJwk, error) {
-	// SM2使用与ECDSA相同的JWK格式
-	return createECDSAJwk(keyPair)
+// createPublicKeyJwkFromHex 从十六进制字符串创建JWK
+func createPublicKeyJwkFromHex(publicKeyHex, algorithm string) (*PublicKeyJwk, error) {
+	kp, err := crypto.ParsePublicKey([]byte(publicKeyHex), algorithm)
+	if err != nil {
+		return nil, err
+	}
+	return NewPublicKeyJwk(kp)
 }
 
 // ToJSON 将DID文档转换为JSON
@@ -377,27 +529,62 @@ func ValidateDIDDocument(doc *DIDDocument) error {
 // 参数：didIdentifier、keyID、algorithm、keyManager
 // 返回：VerificationMethod，error
 func NewVerificationMethodFromKeyManager(didIdentifier, keyID, algorithm string, keyManager crypto.KeyManager) (*VerificationMethod, error) {
-	pubKey, err := keyManager.GetPublicKey(keyID)
+	pubKey, err := keyManager.Get(keyID)
 	if err != nil {
 		return nil, err
 	}
-	// 组装JWK（这里只做简单示例，实际应根据算法和公钥类型填充x/y/n/e等字段）
-	var jwk interface{}
-	switch algorithm {
-	case "ECDSA":
-		jwk = map[string]interface{}{"kty": "EC", "crv": "secp256k1", "x": "x", "y": "y"} // TODO: 真实x/y
-	case "RSA":
-		jwk = map[string]interface{}{"kty": "RSA", "n": "n", "e": "AQAB"} // TODO: 真实n
-	case "SM2":
-		jwk = map[string]interface{}{"kty": "EC", "crv": "secp256k1", "x": "x", "y": "y"}
-	default:
-		jwk = nil
+	if algorithm == "ED25519" {
+		pub, err := x509.ParsePKIXPublicKey(pubKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse Ed25519 public key: %w", err)
+		}
+		edPub, ok := pub.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("key %s is not an Ed25519 key", keyID)
+		}
+		return NewEd25519VerificationMethod(didIdentifier+"#"+keyID, didIdentifier, edPub, TypeEd25519VerificationKey2020)
+	}
+	if crypto.IsECAlgorithm(algorithm) {
+		ecPub, err := crypto.ParseECPublicKey(pubKey)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := ecPublicKeyFrom(ecPub, algorithm); err != nil {
+			return nil, err
+		}
+		return NewECVerificationMethod(didIdentifier+"#"+keyID, didIdentifier, ecPub)
+	}
+	if algorithm == "SM2" {
+		sm2Pub, err := crypto.ParseSM2PublicKey(pubKey)
+		if err != nil {
+			return nil, err
+		}
+		jwk, err := NewPublicKeyJwk(sm2Pub)
+		if err != nil {
+			return nil, err
+		}
+		return &VerificationMethod{
+			ID:           didIdentifier + "#" + keyID,
+			Type:         TypeJsonWebKey2020,
+			Controller:   didIdentifier,
+			PublicKeyJwk: jwk,
+		}, nil
+	}
+	if algorithm != "RSA" {
+		return nil, fmt.Errorf("unsupported algorithm: %s", algorithm)
+	}
+	rsaPub, err := crypto.ParsePublicKey(pubKey, "RSA")
+	if err != nil {
+		return nil, err
+	}
+	jwk, err := NewPublicKeyJwk(rsaPub)
+	if err != nil {
+		return nil, err
 	}
 	return &VerificationMethod{
 		ID:           didIdentifier + "#" + keyID,
-		Type:         map[string]string{"ECDSA": "EcdsaSecp256k1VerificationKey2019", "RSA": "RsaVerificationKey2018", "SM2": "EcdsaSecp256k1VerificationKey2019"}[algorithm],
+		Type:         "RsaVerificationKey2018",
 		Controller:   didIdentifier,
 		PublicKeyJwk: jwk,
 	}, nil
 }
- 
\ No newline at end of file
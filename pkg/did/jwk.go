@@ -0,0 +1,195 @@
+package did
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/helailiang/sbp-did-sdk-go/pkg/crypto"
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+// NewPublicKeyJwk 将公钥转换为JWK（RFC 7517 / RFC 7518 / RFC 8037），kid为RFC 7638指纹
+// 支持*crypto.KeyPair、*ecdsa.PublicKey、*btcec.PublicKey、*rsa.PublicKey、*sm2.PublicKey和ed25519.PublicKey
+func NewPublicKeyJwk(publicKey interface{}) (*PublicKeyJwk, error) {
+	var jwk *PublicKeyJwk
+	switch pk := publicKey.(type) {
+	case *crypto.KeyPair:
+		if pk == nil {
+			return nil, errors.New("key pair cannot be nil")
+		}
+		return NewPublicKeyJwk(pk.PublicKey)
+	case ed25519.PublicKey:
+		if len(pk) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 public key length: %d", len(pk))
+		}
+		jwk = &PublicKeyJwk{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(pk),
+		}
+	case *rsa.PublicKey:
+		if pk == nil || pk.N == nil || pk.E <= 0 {
+			return nil, errors.New("invalid RSA public key")
+		}
+		jwk = &PublicKeyJwk{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(pk.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pk.E)).Bytes()),
+		}
+	case *sm2.PublicKey:
+		if pk == nil || pk.X == nil || pk.Y == nil {
+			return nil, errors.New("invalid SM2 public key")
+		}
+		jwk = &PublicKeyJwk{
+			Kty: "EC",
+			Crv: "SM2",
+			X:   base64.RawURLEncoding.EncodeToString(pk.X.FillBytes(make([]byte, 32))),
+			Y:   base64.RawURLEncoding.EncodeToString(pk.Y.FillBytes(make([]byte, 32))),
+		}
+	default:
+		pub, err := crypto.ToECDSAPublicKey(publicKey)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported public key type for JWK: %T", publicKey)
+		}
+		if jwk, err = newECJwk(pub); err != nil {
+			return nil, err
+		}
+	}
+
+	kid, err := jwk.Thumbprint()
+	if err != nil {
+		return nil, err
+	}
+	jwk.Kid = kid
+	return jwk, nil
+}
+
+// ParsePublicKeyJwk 解析JSON格式的公钥JWK，包含私钥成员"d"时返回错误
+func ParsePublicKeyJwk(data []byte) (*PublicKeyJwk, error) {
+	var raw struct {
+		PublicKeyJwk
+		D string `json:"d,omitempty"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid JWK: %w", err)
+	}
+	if raw.D != "" {
+		return nil, errors.New("JWK contains private key material")
+	}
+	jwk := raw.PublicKeyJwk
+	if _, err := jwk.KeyPair(); err != nil {
+		return nil, err
+	}
+	return &jwk, nil
+}
+
+// Thumbprint 计算JWK的RFC 7638指纹（SHA-256，base64url编码）
+// 仅使用各密钥类型的必需成员，并按字典序排列
+func (jwk *PublicKeyJwk) Thumbprint() (string, error) {
+	var members interface{}
+	switch jwk.Kty {
+	case "EC":
+		if jwk.Crv == "" || jwk.X == "" || jwk.Y == "" {
+			return "", errors.New("EC JWK requires crv, x and y")
+		}
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{jwk.Crv, jwk.Kty, jwk.X, jwk.Y}
+	case "OKP":
+		if jwk.Crv == "" || jwk.X == "" {
+			return "", errors.New("OKP JWK requires crv and x")
+		}
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	case "RSA":
+		if jwk.E == "" || jwk.N == "" {
+			return "", errors.New("RSA JWK requires e and n")
+		}
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	default:
+		return "", fmt.Errorf("unsupported JWK key type: %s", jwk.Kty)
+	}
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// KeyPair 将JWK转换为仅含公钥的*crypto.KeyPair，可直接用于crypto.VerifySignature
+func (jwk *PublicKeyJwk) KeyPair() (*crypto.KeyPair, error) {
+	data, err := json.Marshal(jwk)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.ParsePublicKey(data, "")
+}
+
+// PublicKey 将JWK转换为Go公钥
+// 返回*ecdsa.PublicKey（P-256、P-384）、*btcec.PublicKey（secp256k1）、*sm2.PublicKey、*rsa.PublicKey或ed25519.PublicKey
+func (jwk *PublicKeyJwk) PublicKey() (interface{}, error) {
+	kp, err := jwk.KeyPair()
+	if err != nil {
+		return nil, err
+	}
+	return kp.PublicKey, nil
+}
+
+// GetPublicKeyJwk 返回验证方法的publicKeyJwk
+// 从JSON反序列化得到的map形式也会被转换为*PublicKeyJwk
+func (vm *VerificationMethod) GetPublicKeyJwk() (*PublicKeyJwk, error) {
+	switch jwk := vm.PublicKeyJwk.(type) {
+	case nil:
+		return nil, fmt.Errorf("verification method %s has no publicKeyJwk", vm.ID)
+	case *PublicKeyJwk:
+		return jwk, nil
+	case PublicKeyJwk:
+		return &jwk, nil
+	default:
+		data, err := json.Marshal(jwk)
+		if err != nil {
+			return nil, fmt.Errorf("invalid publicKeyJwk: %w", err)
+		}
+		return ParsePublicKeyJwk(data)
+	}
+}
+
+// PublicKey 从验证方法中提取公钥，依次尝试publicKeyJwk、Ed25519 publicKeyMultibase，
+// 以及EcdsaSecp256k1VerificationKey2019的publicKeyHex
+func (vm *VerificationMethod) PublicKey() (*crypto.KeyPair, error) {
+	if vm.PublicKeyJwk != nil {
+		jwk, err := vm.GetPublicKeyJwk()
+		if err != nil {
+			return nil, err
+		}
+		return jwk.KeyPair()
+	}
+	if vm.PublicKeyMultibase != "" {
+		pub, err := Ed25519PublicKeyFromMultibase(vm.PublicKeyMultibase)
+		if err != nil {
+			return nil, err
+		}
+		return &crypto.KeyPair{PublicKey: pub, Algorithm: "ED25519"}, nil
+	}
+	if vm.PublicKeyHex != "" && vm.Type == TypeEcdsaSecp256k1VerificationKey2019 {
+		return crypto.ParsePublicKey([]byte(vm.PublicKeyHex), crypto.AlgorithmSecp256k1)
+	}
+	return nil, fmt.Errorf("unsupported public key encoding in verification method %s", vm.ID)
+}
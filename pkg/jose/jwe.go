@@ -0,0 +1,512 @@
+package jose
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/helailiang/sbp-did-sdk-go/pkg/crypto"
+	"github.com/helailiang/sbp-did-sdk-go/pkg/did"
+)
+
+// JWE密钥管理与内容加密算法（RFC 7518）
+const (
+	AlgECDHESA256KW = "ECDH-ES+A256KW"
+	AlgRSAOAEP256   = "RSA-OAEP-256"
+	EncA256GCM      = "A256GCM"
+)
+
+// cekSize A256GCM内容加密密钥长度
+const cekSize = 32
+
+// ErrNoMatchingRecipient JWE中没有与解密密钥匹配的接收者
+var ErrNoMatchingRecipient = errors.New("no matching JWE recipient")
+
+// JWE 通用JSON序列化的JWE（RFC 7516 7.2.1）
+type JWE struct {
+	Protected  string         `json:"protected"`
+	Recipients []JWERecipient `json:"recipients"`
+	AAD        string         `json:"aad,omitempty"`
+	IV         string         `json:"iv"`
+	Ciphertext string         `json:"ciphertext"`
+	Tag        string         `json:"tag"`
+}
+
+// JWERecipient JWE接收者
+type JWERecipient struct {
+	Header       *RecipientHeader `json:"header,omitempty"`
+	EncryptedKey string           `json:"encrypted_key,omitempty"`
+}
+
+// RecipientHeader 接收者的非保护头部
+type RecipientHeader struct {
+	Alg string            `json:"alg"`
+	Kid string            `json:"kid,omitempty"`
+	Epk *did.PublicKeyJwk `json:"epk,omitempty"`
+}
+
+// jweProtectedHeader JWE受保护头部
+type jweProtectedHeader struct {
+	Enc string `json:"enc"`
+	Typ string `json:"typ,omitempty"`
+	Cty string `json:"cty,omitempty"`
+}
+
+// RecipientKey 加密接收者：kid写入接收者头部，公钥为EC（P-256、P-384、secp256k1）或RSA
+type RecipientKey struct {
+	Kid       string
+	PublicKey *crypto.KeyPair
+}
+
+// EncryptOption Encrypt的可选配置
+type EncryptOption func(*encryptOptions)
+
+type encryptOptions struct {
+	aad []byte
+	typ string
+	cty string
+}
+
+// WithAAD 设置附加认证数据，写入JWE的aad成员
+func WithAAD(aad []byte) EncryptOption {
+	return func(o *encryptOptions) {
+		o.aad = aad
+	}
+}
+
+// WithJWEType 设置受保护头部的typ
+func WithJWEType(typ string) EncryptOption {
+	return func(o *encryptOptions) {
+		o.typ = typ
+	}
+}
+
+// WithJWEContentType 设置受保护头部的cty
+func WithJWEContentType(cty string) EncryptOption {
+	return func(o *encryptOptions) {
+		o.cty = cty
+	}
+}
+
+// KeyAgreementAlgorithm 返回公钥对应的JWE密钥管理算法
+func KeyAgreementAlgorithm(kp *crypto.KeyPair) (string, error) {
+	if kp == nil {
+		return "", errors.New("key pair cannot be nil")
+	}
+	if _, ok := kp.PublicKey.(*rsa.PublicKey); ok {
+		return AlgRSAOAEP256, nil
+	}
+	pub, err := crypto.ToECDSAPublicKey(kp.PublicKey)
+	if err != nil {
+		return "", fmt.Errorf("unsupported key type for JWE: %T", kp.PublicKey)
+	}
+	if _, err := crypto.ECAlgorithmForCurve(pub.Curve); err != nil {
+		return "", err
+	}
+	return AlgECDHESA256KW, nil
+}
+
+// Encrypt 使用A256GCM加密明文，并为每个接收者封装内容加密密钥
+func Encrypt(plaintext []byte, recipients []RecipientKey, opts ...EncryptOption) (*JWE, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("at least one JWE recipient is required")
+	}
+	o := &encryptOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	cek := make([]byte, cekSize)
+	if _, err := io.ReadFull(rand.Reader, cek); err != nil {
+		return nil, err
+	}
+	jwe := &JWE{}
+	for _, r := range recipients {
+		header, encryptedKey, err := wrapKey(r, cek)
+		if err != nil {
+			return nil, fmt.Errorf("recipient %s: %w", r.Kid, err)
+		}
+		jwe.Recipients = append(jwe.Recipients, JWERecipient{
+			Header:       header,
+			EncryptedKey: base64.RawURLEncoding.EncodeToString(encryptedKey),
+		})
+	}
+
+	protected, err := json.Marshal(jweProtectedHeader{Enc: EncA256GCM, Typ: o.typ, Cty: o.cty})
+	if err != nil {
+		return nil, err
+	}
+	jwe.Protected = base64.RawURLEncoding.EncodeToString(protected)
+	if o.aad != nil {
+		jwe.AAD = base64.RawURLEncoding.EncodeToString(o.aad)
+	}
+
+	gcm, err := newGCM(cek)
+	if err != nil {
+		return nil, err
+	}
+	iv := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+	sealed := gcm.Seal(nil, iv, plaintext, jwe.additionalData())
+	tagStart := len(sealed) - gcm.Overhead()
+	jwe.IV = base64.RawURLEncoding.EncodeToString(iv)
+	jwe.Ciphertext = base64.RawURLEncoding.EncodeToString(sealed[:tagStart])
+	jwe.Tag = base64.RawURLEncoding.EncodeToString(sealed[tagStart:])
+	return jwe, nil
+}
+
+// EncryptForDIDs 加密给一个或多个DID，接收者为各DID文档keyAgreement中的全部密钥
+func EncryptForDIDs(plaintext []byte, docs []*did.DIDDocument, opts ...EncryptOption) (*JWE, error) {
+	var recipients []RecipientKey
+	for _, doc := range docs {
+		keys, err := RecipientsFromDIDDocument(doc)
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, keys...)
+	}
+	return Encrypt(plaintext, recipients, opts...)
+}
+
+// RecipientsFromDIDDocument 解析DID文档keyAgreement引用的验证方法，kid为验证方法的完整ID
+func RecipientsFromDIDDocument(doc *did.DIDDocument) ([]RecipientKey, error) {
+	if doc == nil {
+		return nil, errors.New("DID document cannot be nil")
+	}
+	if len(doc.KeyAgreement) == 0 {
+		return nil, fmt.Errorf("DID %s has no keyAgreement keys", doc.ID)
+	}
+	recipients := make([]RecipientKey, 0, len(doc.KeyAgreement))
+	for _, ref := range doc.KeyAgreement {
+		vm, err := doc.FindVerificationMethod(ref)
+		if err != nil {
+			return nil, err
+		}
+		pub, err := vm.PublicKey()
+		if err != nil {
+			return nil, fmt.Errorf("keyAgreement %s: %w", ref, err)
+		}
+		recipients = append(recipients, RecipientKey{Kid: vm.ID, PublicKey: pub})
+	}
+	return recipients, nil
+}
+
+// ParseJWE 解析通用JSON序列化的JWE，也接受扁平JSON序列化（RFC 7516 7.2.2）
+func ParseJWE(data []byte) (*JWE, error) {
+	var raw struct {
+		JWE
+		Header       *RecipientHeader `json:"header,omitempty"`
+		EncryptedKey string           `json:"encrypted_key,omitempty"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid JWE: %w", err)
+	}
+	jwe := raw.JWE
+	if len(jwe.Recipients) == 0 && raw.Header != nil {
+		jwe.Recipients = []JWERecipient{{Header: raw.Header, EncryptedKey: raw.EncryptedKey}}
+	}
+	if len(jwe.Recipients) == 0 {
+		return nil, errors.New("JWE has no recipients")
+	}
+	if _, err := jwe.protectedHeader(); err != nil {
+		return nil, err
+	}
+	return &jwe, nil
+}
+
+// Serialize 输出通用JSON序列化
+func (j *JWE) Serialize() ([]byte, error) {
+	return json.Marshal(j)
+}
+
+// Decrypt 使用解密器解出明文；解密器带kid时只尝试kid匹配的接收者
+func (j *JWE) Decrypt(d Decrypter) ([]byte, error) {
+	if d == nil {
+		return nil, errors.New("decrypter cannot be nil")
+	}
+	header, err := j.protectedHeader()
+	if err != nil {
+		return nil, err
+	}
+	if header.Enc != EncA256GCM {
+		return nil, fmt.Errorf("unsupported JWE content encryption: %s", header.Enc)
+	}
+
+	var cek []byte
+	for _, r := range j.Recipients {
+		if r.Header == nil || !d.Match(r.Header) {
+			continue
+		}
+		encryptedKey, err := rawURLEncoding.DecodeString(r.EncryptedKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWE encrypted key: %w", err)
+		}
+		if cek, err = d.UnwrapKey(r.Header, encryptedKey); err == nil {
+			break
+		}
+	}
+	if len(cek) != cekSize {
+		return nil, ErrNoMatchingRecipient
+	}
+
+	iv, err := rawURLEncoding.DecodeString(j.IV)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWE iv: %w", err)
+	}
+	ciphertext, err := rawURLEncoding.DecodeString(j.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWE ciphertext: %w", err)
+	}
+	tag, err := rawURLEncoding.DecodeString(j.Tag)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWE tag: %w", err)
+	}
+	gcm, err := newGCM(cek)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != gcm.NonceSize() || len(tag) != gcm.Overhead() {
+		return nil, errors.New("invalid JWE iv or tag length")
+	}
+	plaintext, err := gcm.Open(nil, iv, append(ciphertext, tag...), j.additionalData())
+	if err != nil {
+		return nil, errors.New("JWE decryption failed: authentication error")
+	}
+	return plaintext, nil
+}
+
+// protectedHeader 解码受保护头部
+func (j *JWE) protectedHeader() (*jweProtectedHeader, error) {
+	data, err := rawURLEncoding.DecodeString(j.Protected)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWE protected header encoding: %w", err)
+	}
+	var header jweProtectedHeader
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("invalid JWE protected header: %w", err)
+	}
+	return &header, nil
+}
+
+// additionalData 计算AEAD附加数据 ASCII(protected) [|| '.' || ASCII(aad)]
+func (j *JWE) additionalData() []byte {
+	if j.AAD == "" {
+		return []byte(j.Protected)
+	}
+	return []byte(j.Protected + "." + j.AAD)
+}
+
+// Decrypter JWE接收者一侧的密钥解封装
+type Decrypter interface {
+	// Match 判断接收者头部是否指向该解密密钥
+	Match(header *RecipientHeader) bool
+	// UnwrapKey 解出内容加密密钥
+	UnwrapKey(header *RecipientHeader, encryptedKey []byte) ([]byte, error)
+}
+
+// keyPairDecrypter 使用内存私钥解封装
+type keyPairDecrypter struct {
+	kp  *crypto.KeyPair
+	kid string
+	alg string
+}
+
+// NewKeyPairDecrypter 创建使用内存私钥的解密器
+// kid非空时只匹配kid一致（或片段一致）的接收者，为空时尝试所有算法匹配的接收者
+func NewKeyPairDecrypter(kp *crypto.KeyPair, kid string) (Decrypter, error) {
+	alg, err := KeyAgreementAlgorithm(kp)
+	if err != nil {
+		return nil, err
+	}
+	if kp.PrivateKey == nil {
+		return nil, errors.New("key pair has no private key")
+	}
+	return &keyPairDecrypter{kp: kp, kid: kid, alg: alg}, nil
+}
+
+// NewKeyManagerDecrypter 从KeyManager导出私钥创建解密器，需要后端支持ExportPrivateKey
+func NewKeyManagerDecrypter(keyManager crypto.KeyManager, keyID, kid string) (Decrypter, error) {
+	privKey, err := keyManager.ExportPrivateKey(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export private key: %w", err)
+	}
+	kp, err := crypto.ParsePrivateKey(privKey, "")
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyPairDecrypter(kp, kid)
+}
+
+func (d *keyPairDecrypter) Match(header *RecipientHeader) bool {
+	if header.Alg != d.alg {
+		return false
+	}
+	if d.kid == "" || header.Kid == "" {
+		return true
+	}
+	return did.MatchVerificationMethodID(&did.VerificationMethod{ID: header.Kid}, d.kid) ||
+		did.MatchVerificationMethodID(&did.VerificationMethod{ID: d.kid}, header.Kid)
+}
+
+func (d *keyPairDecrypter) UnwrapKey(header *RecipientHeader, encryptedKey []byte) ([]byte, error) {
+	switch header.Alg {
+	case AlgRSAOAEP256:
+		priv, ok := d.kp.PrivateKey.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("invalid RSA private key type: %T", d.kp.PrivateKey)
+		}
+		return rsa.DecryptOAEP(sha256.New(), nil, priv, encryptedKey, nil)
+	case AlgECDHESA256KW:
+		priv, err := crypto.ToECDSAPrivateKey(d.kp)
+		if err != nil {
+			return nil, err
+		}
+		if header.Epk == nil {
+			return nil, errors.New("JWE recipient is missing epk")
+		}
+		epk, err := header.Epk.KeyPair()
+		if err != nil {
+			return nil, fmt.Errorf("invalid epk: %w", err)
+		}
+		epub, err := crypto.ToECDSAPublicKey(epk)
+		if err != nil {
+			return nil, err
+		}
+		if epub.Curve.Params().Name != priv.Curve.Params().Name {
+			return nil, errors.New("epk curve does not match recipient key")
+		}
+		kek := deriveKEK(priv.Curve, priv.D.Bytes(), epub)
+		return aesKeyUnwrap(kek, encryptedKey)
+	default:
+		return nil, fmt.Errorf("unsupported JWE algorithm: %s", header.Alg)
+	}
+}
+
+// wrapKey 为接收者封装内容加密密钥
+func wrapKey(r RecipientKey, cek []byte) (*RecipientHeader, []byte, error) {
+	alg, err := KeyAgreementAlgorithm(r.PublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	header := &RecipientHeader{Alg: alg, Kid: r.Kid}
+	if alg == AlgRSAOAEP256 {
+		encryptedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, r.PublicKey.PublicKey.(*rsa.PublicKey), cek, nil)
+		return header, encryptedKey, err
+	}
+
+	pub, _ := crypto.ToECDSAPublicKey(r.PublicKey)
+	ephemeral, err := ecdsa.GenerateKey(pub.Curve, rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	if header.Epk, err = did.NewPublicKeyJwk(&ephemeral.PublicKey); err != nil {
+		return nil, nil, err
+	}
+	header.Epk.Kid = ""
+	kek := deriveKEK(pub.Curve, ephemeral.D.Bytes(), pub)
+	encryptedKey, err := aesKeyWrap(kek, cek)
+	return header, encryptedKey, err
+}
+
+// deriveKEK ECDH后使用Concat KDF（NIST SP 800-56A，SHA-256）派生A256KW密钥（RFC 7518 4.6.2）
+// apu、apv为空
+func deriveKEK(curve elliptic.Curve, d []byte, pub *ecdsa.PublicKey) []byte {
+	x, _ := curve.ScalarMult(pub.X, pub.Y, d)
+	z := x.FillBytes(make([]byte, (curve.Params().BitSize+7)/8))
+
+	h := sha256.New()
+	_ = binary.Write(h, binary.BigEndian, uint32(1))
+	h.Write(z)
+	writeLengthPrefixed(h, []byte(AlgECDHESA256KW))
+	writeLengthPrefixed(h, nil)
+	writeLengthPrefixed(h, nil)
+	_ = binary.Write(h, binary.BigEndian, uint32(cekSize*8))
+	return h.Sum(nil)
+}
+
+// writeLengthPrefixed 写入32位大端长度前缀的数据
+func writeLengthPrefixed(w io.Writer, data []byte) {
+	_ = binary.Write(w, binary.BigEndian, uint32(len(data)))
+	_, _ = w.Write(data)
+}
+
+// kwDefaultIV RFC 3394 默认初始值
+var kwDefaultIV = []byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6}
+
+// aesKeyWrap AES密钥封装（RFC 3394）
+func aesKeyWrap(kek, key []byte) ([]byte, error) {
+	if len(key)%8 != 0 || len(key) < 16 {
+		return nil, errors.New("key wrap input must be a multiple of 8 bytes and at least 16 bytes")
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	n := len(key) / 8
+	out := make([]byte, 8+len(key))
+	copy(out, kwDefaultIV)
+	copy(out[8:], key)
+	buf := make([]byte, 16)
+	for j := 0; j < 6; j++ {
+		for i := 1; i <= n; i++ {
+			copy(buf, out[:8])
+			copy(buf[8:], out[8*i:8*i+8])
+			block.Encrypt(buf, buf)
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(out[:8], binary.BigEndian.Uint64(buf[:8])^t)
+			copy(out[8*i:], buf[8:])
+		}
+	}
+	return out, nil
+}
+
+// aesKeyUnwrap AES密钥解封装（RFC 3394），校验完整性
+func aesKeyUnwrap(kek, wrapped []byte) ([]byte, error) {
+	if len(wrapped)%8 != 0 || len(wrapped) < 24 {
+		return nil, errors.New("invalid wrapped key length")
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	n := len(wrapped)/8 - 1
+	out := make([]byte, len(wrapped))
+	copy(out, wrapped)
+	buf := make([]byte, 16)
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(buf[:8], binary.BigEndian.Uint64(out[:8])^t)
+			copy(buf[8:], out[8*i:8*i+8])
+			block.Decrypt(buf, buf)
+			copy(out[:8], buf[:8])
+			copy(out[8*i:], buf[8:])
+		}
+	}
+	if subtle.ConstantTimeCompare(out[:8], kwDefaultIV) != 1 {
+		return nil, errors.New("key unwrap integrity check failed")
+	}
+	return out[8:], nil
+}
+
+// newGCM 创建AES-GCM
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,261 @@
+package jose
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/helailiang/sbp-did-sdk-go/pkg/crypto"
+	"github.com/helailiang/sbp-did-sdk-go/pkg/did"
+)
+
+// ErrInvalidSignature JWS签名验证失败
+var ErrInvalidSignature = errors.New("invalid JWS signature")
+
+// rawURLEncoding 解析时使用严格模式，拒绝非规范的base64url编码
+var rawURLEncoding = base64.RawURLEncoding.Strict()
+
+// headerB64 RFC 7797 的b64头部参数
+const headerB64 = "b64"
+
+// Header JWS受保护头部（RFC 7515 4.1 / RFC 7797 3）
+type Header struct {
+	Alg  string   `json:"alg"`
+	Kid  string   `json:"kid,omitempty"`
+	Typ  string   `json:"typ,omitempty"`
+	Cty  string   `json:"cty,omitempty"`
+	B64  *bool    `json:"b64,omitempty"`
+	Crit []string `json:"crit,omitempty"`
+}
+
+// Unencoded 是否为RFC 7797未编码载荷（b64为false）
+func (h *Header) Unencoded() bool {
+	return h.B64 != nil && !*h.B64
+}
+
+// JWS 紧凑序列化JWS的解析结果
+type JWS struct {
+	Header Header
+	// Payload 载荷；分离式JWS在验证前为nil
+	Payload   []byte
+	Signature []byte
+
+	rawHeader  string
+	rawPayload string
+}
+
+// Detached 载荷是否未包含在紧凑序列化中
+func (j *JWS) Detached() bool {
+	return j.rawPayload == ""
+}
+
+// signingInput 计算签名输入 ASCII(BASE64URL(header)) || '.' || 载荷段
+func signingInput(rawHeader string, header *Header, payload []byte) []byte {
+	if header.Unencoded() {
+		return append([]byte(rawHeader+"."), payload...)
+	}
+	return []byte(rawHeader + "." + base64.RawURLEncoding.EncodeToString(payload))
+}
+
+// SignOption Sign的可选配置
+type SignOption func(*signOptions)
+
+type signOptions struct {
+	typ       string
+	cty       string
+	detached  bool
+	unencoded bool
+}
+
+// WithType 设置头部typ，如 "JWT"、"vc+ld+json"
+func WithType(typ string) SignOption {
+	return func(o *signOptions) {
+		o.typ = typ
+	}
+}
+
+// WithContentType 设置头部cty
+func WithContentType(cty string) SignOption {
+	return func(o *signOptions) {
+		o.cty = cty
+	}
+}
+
+// WithDetachedPayload 生成分离式JWS，载荷段为空（RFC 7515 附录F）
+func WithDetachedPayload() SignOption {
+	return func(o *signOptions) {
+		o.detached = true
+	}
+}
+
+// WithUnencodedPayload 使用RFC 7797未编码载荷（b64:false, crit:["b64"]），输出总是分离式
+// 用于JsonWebSignature2020等证明中的proof.jws
+func WithUnencodedPayload() SignOption {
+	return func(o *signOptions) {
+		o.unencoded = true
+		o.detached = true
+	}
+}
+
+// Sign 对载荷签名并返回紧凑序列化的JWS
+func Sign(signer Signer, payload []byte, opts ...SignOption) (string, error) {
+	if signer == nil {
+		return "", errors.New("signer cannot be nil")
+	}
+	o := &signOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	header := Header{
+		Alg: signer.Algorithm(),
+		Kid: signer.KeyID(),
+		Typ: o.typ,
+		Cty: o.cty,
+	}
+	if o.unencoded {
+		b64 := false
+		header.B64 = &b64
+		header.Crit = []string{headerB64}
+	}
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	rawHeader := base64.RawURLEncoding.EncodeToString(headerJSON)
+	sig, err := signer.Sign(signingInput(rawHeader, &header, payload))
+	if err != nil {
+		return "", fmt.Errorf("JWS signing failed: %w", err)
+	}
+	rawPayload := ""
+	if !o.detached {
+		rawPayload = base64.RawURLEncoding.EncodeToString(payload)
+	}
+	return rawHeader + "." + rawPayload + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// Parse 解析紧凑序列化的JWS（不验证签名）
+func Parse(compact string) (*JWS, error) {
+	parts := strings.Split(compact, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("invalid compact JWS: expected 3 parts")
+	}
+	headerJSON, err := rawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWS header encoding: %w", err)
+	}
+	j := &JWS{rawHeader: parts[0], rawPayload: parts[1]}
+	if err := json.Unmarshal(headerJSON, &j.Header); err != nil {
+		return nil, fmt.Errorf("invalid JWS header: %w", err)
+	}
+	if j.Header.Alg == "" || j.Header.Alg == "none" {
+		return nil, fmt.Errorf("unsupported JWS algorithm: %q", j.Header.Alg)
+	}
+	if err := checkCrit(&j.Header); err != nil {
+		return nil, err
+	}
+	if j.rawPayload != "" {
+		if j.Header.Unencoded() {
+			j.Payload = []byte(j.rawPayload)
+		} else if j.Payload, err = rawURLEncoding.DecodeString(j.rawPayload); err != nil {
+			return nil, fmt.Errorf("invalid JWS payload encoding: %w", err)
+		}
+	}
+	if j.Signature, err = rawURLEncoding.DecodeString(parts[2]); err != nil {
+		return nil, fmt.Errorf("invalid JWS signature encoding: %w", err)
+	}
+	return j, nil
+}
+
+// checkCrit 检查crit头部：仅支持b64，且使用b64时必须列入crit（RFC 7797 6）
+func checkCrit(h *Header) error {
+	listed := false
+	for _, name := range h.Crit {
+		if name != headerB64 {
+			return fmt.Errorf("unsupported critical JWS header: %s", name)
+		}
+		listed = true
+	}
+	if h.B64 != nil && !listed {
+		return errors.New("JWS header b64 must be listed in crit")
+	}
+	return nil
+}
+
+// Verify 使用公钥验证紧凑序列化的JWS
+// detachedPayload用于分离式JWS，非分离式JWS须传nil
+func Verify(compact string, pub *crypto.KeyPair, detachedPayload []byte) (*JWS, error) {
+	j, err := Parse(compact)
+	if err != nil {
+		return nil, err
+	}
+	if err := j.verify(pub, detachedPayload); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// VerifyWithVerificationMethod 使用DID验证方法中的公钥验证JWS
+// 头部带kid时须与验证方法ID（或其片段、JWK的kid）一致
+func VerifyWithVerificationMethod(compact string, vm *did.VerificationMethod, detachedPayload []byte) (*JWS, error) {
+	if vm == nil {
+		return nil, errors.New("verification method cannot be nil")
+	}
+	j, err := Parse(compact)
+	if err != nil {
+		return nil, err
+	}
+	if j.Header.Kid != "" && !did.MatchVerificationMethodID(vm, j.Header.Kid) {
+		return nil, fmt.Errorf("JWS kid %s does not match verification method %s", j.Header.Kid, vm.ID)
+	}
+	pub, err := vm.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := j.verify(pub, detachedPayload); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// VerifyWithDIDDocument 按头部kid在DID文档中查找验证方法并验证JWS
+func VerifyWithDIDDocument(compact string, doc *did.DIDDocument, detachedPayload []byte) (*JWS, error) {
+	if doc == nil {
+		return nil, errors.New("DID document cannot be nil")
+	}
+	j, err := Parse(compact)
+	if err != nil {
+		return nil, err
+	}
+	vm, err := doc.FindVerificationMethod(j.Header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := vm.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := j.verify(pub, detachedPayload); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// verify 验证签名，分离式JWS验证通过后填充Payload
+func (j *JWS) verify(pub *crypto.KeyPair, detachedPayload []byte) error {
+	payload := j.Payload
+	if j.Detached() {
+		if detachedPayload == nil {
+			return errors.New("detached JWS requires the payload")
+		}
+		payload = detachedPayload
+	} else if detachedPayload != nil {
+		return errors.New("JWS is not detached")
+	}
+	if err := verifySignature(j.Header.Alg, pub, signingInput(j.rawHeader, &j.Header, payload), j.Signature); err != nil {
+		return err
+	}
+	j.Payload = payload
+	return nil
+}
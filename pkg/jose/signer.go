@@ -0,0 +1,265 @@
+package jose
+
+import (
+	stdcrypto "crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/helailiang/sbp-did-sdk-go/pkg/crypto"
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+// JWS签名算法（RFC 7518 / RFC 8037 / RFC 8812）
+// SM2为私有算法：SM3withSM2，默认用户标识，签名为64字节 r || s
+const (
+	AlgES256K = "ES256K"
+	AlgES256  = "ES256"
+	AlgES384  = "ES384"
+	AlgRS256  = "RS256"
+	AlgEdDSA  = "EdDSA"
+	AlgSM2    = "SM2"
+)
+
+// Signer JWS签名器
+type Signer interface {
+	// Algorithm 返回JWS头部的alg
+	Algorithm() string
+	// KeyID 返回JWS头部的kid，为空时不写入
+	KeyID() string
+	// Sign 对JWS签名输入签名，返回JWS格式的签名值（ECDSA/SM2为定长 r || s）
+	Sign(signingInput []byte) ([]byte, error)
+}
+
+// AlgorithmForKey 返回公钥对应的JWS算法
+func AlgorithmForKey(kp *crypto.KeyPair) (string, error) {
+	if kp == nil {
+		return "", errors.New("key pair cannot be nil")
+	}
+	switch pub := kp.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return AlgRS256, nil
+	case *sm2.PublicKey:
+		return AlgSM2, nil
+	case ed25519.PublicKey:
+		return AlgEdDSA, nil
+	default:
+		ecPub, err := crypto.ToECDSAPublicKey(pub)
+		if err != nil {
+			return "", fmt.Errorf("unsupported key type for JWS: %T", kp.PublicKey)
+		}
+		alg, err := crypto.ECAlgorithmForCurve(ecPub.Curve)
+		if err != nil {
+			return "", err
+		}
+		switch alg {
+		case crypto.AlgorithmSecp256k1:
+			return AlgES256K, nil
+		case crypto.AlgorithmP256:
+			return AlgES256, nil
+		default:
+			return AlgES384, nil
+		}
+	}
+}
+
+// digest 计算ES*/RS256使用的摘要
+func digest(alg string, data []byte) []byte {
+	if alg == AlgES384 {
+		sum := sha512.Sum384(data)
+		return sum[:]
+	}
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
+
+// cryptoSigner 通过KeyManager/Crypto后端签名，私钥不离开后端
+type cryptoSigner struct {
+	backend crypto.Crypto
+	keyID   string
+	kid     string
+	alg     string
+	pub     *crypto.KeyPair
+}
+
+// NewSigner 创建使用KeyManager/Crypto后端（如LocalKeyManager、HuaweiKMSKeyManager）的签名器
+// 算法由keyManager.Get(keyID)返回的公钥确定；kid为写入JWS头部的密钥标识，通常为验证方法ID
+// 后端约定：ECDSA对摘要签名，SM2与Ed25519对原始消息签名；RS256须后端实现crypto.RSADigestSigner
+func NewSigner(keyManager crypto.KeyManager, backend crypto.Crypto, keyID, kid string) (Signer, error) {
+	if keyManager == nil || backend == nil {
+		return nil, errors.New("key manager and crypto backend are required")
+	}
+	pubKey, err := keyManager.Get(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public key: %w", err)
+	}
+	pub, err := crypto.ParsePublicKey(pubKey, "")
+	if err != nil {
+		return nil, err
+	}
+	alg, err := AlgorithmForKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := backend.(crypto.RSADigestSigner); alg == AlgRS256 && !ok {
+		return nil, fmt.Errorf("crypto backend %T does not support RS256: crypto.RSADigestSigner is required", backend)
+	}
+	return &cryptoSigner{backend: backend, keyID: keyID, kid: kid, alg: alg, pub: pub}, nil
+}
+
+func (s *cryptoSigner) Algorithm() string { return s.alg }
+
+func (s *cryptoSigner) KeyID() string { return s.kid }
+
+func (s *cryptoSigner) Sign(signingInput []byte) ([]byte, error) {
+	switch s.alg {
+	case AlgES256K, AlgES256, AlgES384:
+		sig, err := s.backend.Sign(s.keyID, digest(s.alg, signingInput))
+		if err != nil {
+			return nil, err
+		}
+		ecPub, _ := crypto.ToECDSAPublicKey(s.pub)
+		r, ss, _, err := crypto.ParseECDSASignature(ecPub.Curve, sig)
+		if err != nil {
+			return nil, err
+		}
+		return fixedSignature(r, ss, (ecPub.Curve.Params().BitSize+7)/8), nil
+	case AlgRS256:
+		return s.backend.(crypto.RSADigestSigner).SignRSADigest(s.keyID, stdcrypto.SHA256, digest(s.alg, signingInput))
+	case AlgSM2:
+		sig, err := s.backend.Sign(s.keyID, signingInput)
+		if err != nil {
+			return nil, err
+		}
+		return sm2DERToFixed(sig)
+	default:
+		return s.backend.Sign(s.keyID, signingInput)
+	}
+}
+
+// keyPairSigner 使用内存中的*crypto.KeyPair签名
+type keyPairSigner struct {
+	kp  *crypto.KeyPair
+	kid string
+	alg string
+}
+
+// NewKeyPairSigner 创建使用内存密钥对的签名器
+func NewKeyPairSigner(kp *crypto.KeyPair, kid string) (Signer, error) {
+	alg, err := AlgorithmForKey(kp)
+	if err != nil {
+		return nil, err
+	}
+	if kp.PrivateKey == nil {
+		return nil, errors.New("key pair has no private key")
+	}
+	return &keyPairSigner{kp: kp, kid: kid, alg: alg}, nil
+}
+
+func (s *keyPairSigner) Algorithm() string { return s.alg }
+
+func (s *keyPairSigner) KeyID() string { return s.kid }
+
+func (s *keyPairSigner) Sign(signingInput []byte) ([]byte, error) {
+	switch s.alg {
+	case AlgES256K, AlgES256, AlgES384:
+		priv, err := crypto.ToECDSAPrivateKey(s.kp)
+		if err != nil {
+			return nil, err
+		}
+		return crypto.SignECDSA(rand.Reader, priv, digest(s.alg, signingInput), crypto.SignatureFormatP1363)
+	case AlgRS256:
+		priv, ok := s.kp.PrivateKey.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("invalid RSA private key type: %T", s.kp.PrivateKey)
+		}
+		return rsa.SignPKCS1v15(rand.Reader, priv, stdcrypto.SHA256, digest(s.alg, signingInput))
+	case AlgSM2:
+		priv, ok := s.kp.PrivateKey.(*sm2.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("invalid SM2 private key type: %T", s.kp.PrivateKey)
+		}
+		sig, err := crypto.SignSM2(rand.Reader, priv, signingInput, crypto.SM2DefaultUID)
+		if err != nil {
+			return nil, err
+		}
+		return sm2DERToFixed(sig)
+	default:
+		priv, ok := s.kp.PrivateKey.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("invalid Ed25519 private key type: %T", s.kp.PrivateKey)
+		}
+		return ed25519.Sign(priv, signingInput), nil
+	}
+}
+
+// verifySignature 按alg验证JWS签名，alg须与公钥类型一致
+func verifySignature(alg string, pub *crypto.KeyPair, signingInput, signature []byte) error {
+	keyAlg, err := AlgorithmForKey(pub)
+	if err != nil {
+		return err
+	}
+	if keyAlg != alg {
+		return fmt.Errorf("JWS algorithm %s does not match key algorithm %s", alg, keyAlg)
+	}
+	valid := false
+	switch alg {
+	case AlgES256K, AlgES256, AlgES384:
+		ecPub, _ := crypto.ToECDSAPublicKey(pub)
+		// JWS只接受定长 r || s 编码，转换为DER后验证以避免按其他格式解释
+		if size := (ecPub.Curve.Params().BitSize + 7) / 8; len(signature) == 2*size {
+			valid = crypto.VerifyECDSA(ecPub, digest(alg, signingInput), fixedToDER(signature))
+		}
+	case AlgRS256:
+		valid = rsa.VerifyPKCS1v15(pub.PublicKey.(*rsa.PublicKey), stdcrypto.SHA256, digest(alg, signingInput), signature) == nil
+	case AlgSM2:
+		if len(signature) == 64 {
+			valid = crypto.VerifySM2(pub.PublicKey.(*sm2.PublicKey), signingInput, crypto.SM2DefaultUID, fixedToDER(signature))
+		}
+	case AlgEdDSA:
+		valid = len(signature) == ed25519.SignatureSize && ed25519.Verify(pub.PublicKey.(ed25519.PublicKey), signingInput, signature)
+	}
+	if !valid {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// rsSignature ECDSA/SM2签名的ASN.1结构
+type rsSignature struct {
+	R, S *big.Int
+}
+
+// sm2DERToFixed 将DER编码的SM2签名转换为64字节 r || s
+func sm2DERToFixed(der []byte) ([]byte, error) {
+	var sig rsSignature
+	rest, err := asn1.Unmarshal(der, &sig)
+	if err != nil || len(rest) != 0 || sig.R == nil || sig.S == nil {
+		return nil, errors.New("invalid SM2 signature encoding")
+	}
+	return fixedSignature(sig.R, sig.S, 32), nil
+}
+
+// fixedSignature 将(r, s)编码为定长 r || s
+func fixedSignature(r, s *big.Int, size int) []byte {
+	out := make([]byte, 2*size)
+	r.FillBytes(out[:size])
+	s.FillBytes(out[size:])
+	return out
+}
+
+// fixedToDER 将定长 r || s 签名转换为DER编码
+func fixedToDER(signature []byte) []byte {
+	size := len(signature) / 2
+	der, _ := asn1.Marshal(rsSignature{
+		R: new(big.Int).SetBytes(signature[:size]),
+		S: new(big.Int).SetBytes(signature[size:]),
+	})
+	return der
+}
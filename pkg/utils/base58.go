@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"errors"
+	"math/big"
+)
+
+// base58btc字母表（Bitcoin），用于publicKeyBase58和multibase（前缀'z'）
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var base58Index = func() [256]int {
+	var idx [256]int
+	for i := range idx {
+		idx[i] = -1
+	}
+	for i := 0; i < len(base58Alphabet); i++ {
+		idx[base58Alphabet[i]] = i
+	}
+	return idx
+}()
+
+var bigRadix58 = big.NewInt(58)
+
+// EncodeBase58 使用base58btc字母表编码，前导0x00编码为'1'
+func EncodeBase58(data []byte) string {
+	zeros := 0
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+	x := new(big.Int).SetBytes(data)
+	mod := new(big.Int)
+	out := make([]byte, 0, len(data)*138/100+1)
+	for x.Sign() > 0 {
+		x.DivMod(x, bigRadix58, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	for i := 0; i < zeros; i++ {
+		out = append(out, base58Alphabet[0])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+// DecodeBase58 解码base58btc字符串
+func DecodeBase58(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+	x := new(big.Int)
+	for i := 0; i < len(s); i++ {
+		v := base58Index[s[i]]
+		if v < 0 {
+			return nil, errors.New("invalid base58 character")
+		}
+		x.Mul(x, bigRadix58)
+		x.Add(x, big.NewInt(int64(v)))
+	}
+	return append(make([]byte, zeros), x.Bytes()...), nil
+}
+
+// EncodeMultibase 以base58btc编码并添加multibase前缀'z'
+func EncodeMultibase(data []byte) string {
+	return "z" + EncodeBase58(data)
+}
+
+// DecodeMultibase 解码multibase字符串，目前仅支持base58btc（前缀'z'）
+func DecodeMultibase(s string) ([]byte, error) {
+	if len(s) == 0 || s[0] != 'z' {
+		return nil, errors.New("unsupported multibase encoding, only base58btc ('z') is supported")
+	}
+	return DecodeBase58(s[1:])
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"hash"
+	"io"
 )
 
 // HashAlgorithm 哈希算法类型
@@ -22,20 +23,38 @@ func CalculateHash(data []byte, algorithm HashAlgorithm) (string, error) {
 		return "", fmt.Errorf("data cannot be empty")
 	}
 
-	var h hash.Hash
+	h, err := NewHash(algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	h.Write(data)
+	hashBytes := h.Sum(nil)
+	return hex.EncodeToString(hashBytes), nil
+}
+
+// NewHash 创建指定算法的hash.Hash，可多次Write以流式计算大数据的哈希
+func NewHash(algorithm HashAlgorithm) (hash.Hash, error) {
 	switch algorithm {
 	case SHA256:
-		h = sha256.New()
+		return sha256.New(), nil
 	case SM3:
-		// 注意：这里使用SHA256作为SM3的替代，实际应该使用SM3实现
-		h = sha256.New()
+		return NewSM3(), nil
 	default:
-		return "", fmt.Errorf("unsupported hash algorithm: %s", algorithm)
+		return nil, fmt.Errorf("unsupported hash algorithm: %s", algorithm)
 	}
+}
 
-	h.Write(data)
-	hashBytes := h.Sum(nil)
-	return hex.EncodeToString(hashBytes), nil
+// CalculateHashFromReader 流式读取r并计算哈希值，适用于大文件等无法一次性载入内存的数据
+func CalculateHashFromReader(r io.Reader, algorithm HashAlgorithm) (string, error) {
+	h, err := NewHash(algorithm)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(h, r); err != nil {
+		return "", fmt.Errorf("failed to read data: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
 }
 
 // CalculateHashFromString 从字符串计算哈希值
@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+// SM3实现遵循GB/T 32905-2016，输出256位摘要
+
+// SM3Size SM3摘要长度（字节）
+const SM3Size = 32
+
+// SM3BlockSize SM3分组长度（字节）
+const SM3BlockSize = 64
+
+var sm3IV = [8]uint32{
+	0x7380166f, 0x4914b2b9, 0x172442d7, 0xda8a0600,
+	0xa96f30bc, 0x163138aa, 0xe38dee4d, 0xb0fb0e4e,
+}
+
+// sm3Digest 实现hash.Hash，可分多次Write以流式处理大数据
+type sm3Digest struct {
+	h   [8]uint32
+	x   [SM3BlockSize]byte
+	nx  int
+	len uint64
+}
+
+// NewSM3 创建SM3哈希
+func NewSM3() hash.Hash {
+	d := new(sm3Digest)
+	d.Reset()
+	return d
+}
+
+// SM3Sum 计算数据的SM3摘要
+func SM3Sum(data []byte) [SM3Size]byte {
+	d := new(sm3Digest)
+	d.Reset()
+	d.Write(data)
+	var out [SM3Size]byte
+	d.checkSum(out[:0])
+	return out
+}
+
+func (d *sm3Digest) Reset() {
+	d.h = sm3IV
+	d.nx = 0
+	d.len = 0
+}
+
+func (d *sm3Digest) Size() int { return SM3Size }
+
+func (d *sm3Digest) BlockSize() int { return SM3BlockSize }
+
+func (d *sm3Digest) Write(p []byte) (int, error) {
+	n := len(p)
+	d.len += uint64(n)
+	if d.nx > 0 {
+		c := copy(d.x[d.nx:], p)
+		d.nx += c
+		p = p[c:]
+		if d.nx == SM3BlockSize {
+			d.block(d.x[:])
+			d.nx = 0
+		}
+	}
+	if len(p) >= SM3BlockSize {
+		m := len(p) &^ (SM3BlockSize - 1)
+		d.block(p[:m])
+		p = p[m:]
+	}
+	if len(p) > 0 {
+		d.nx = copy(d.x[:], p)
+	}
+	return n, nil
+}
+
+// Sum 追加当前摘要到b，不影响后续Write
+func (d *sm3Digest) Sum(b []byte) []byte {
+	cp := *d
+	return cp.checkSum(b)
+}
+
+// checkSum 填充并输出摘要
+func (d *sm3Digest) checkSum(b []byte) []byte {
+	bitLen := d.len << 3
+	var pad [SM3BlockSize + 8]byte
+	pad[0] = 0x80
+	padLen := 56 - int(d.len%64)
+	if padLen <= 0 {
+		padLen += 64
+	}
+	binary.BigEndian.PutUint64(pad[padLen:], bitLen)
+	d.Write(pad[:padLen+8])
+
+	var out [SM3Size]byte
+	for i, v := range d.h {
+		binary.BigEndian.PutUint32(out[i*4:], v)
+	}
+	return append(b, out[:]...)
+}
+
+// block 压缩函数，p的长度为分组长度的整数倍
+func (d *sm3Digest) block(p []byte) {
+	var w [68]uint32
+	var w1 [64]uint32
+	for len(p) >= SM3BlockSize {
+		for i := 0; i < 16; i++ {
+			w[i] = binary.BigEndian.Uint32(p[i*4:])
+		}
+		for j := 16; j < 68; j++ {
+			w[j] = sm3P1(w[j-16]^w[j-9]^bits.RotateLeft32(w[j-3], 15)) ^ bits.RotateLeft32(w[j-13], 7) ^ w[j-6]
+		}
+		for j := 0; j < 64; j++ {
+			w1[j] = w[j] ^ w[j+4]
+		}
+
+		a, b, c, dd, e, f, g, h := d.h[0], d.h[1], d.h[2], d.h[3], d.h[4], d.h[5], d.h[6], d.h[7]
+		for j := 0; j < 64; j++ {
+			var t, ff, gg uint32
+			if j < 16 {
+				t = 0x79cc4519
+				ff = a ^ b ^ c
+				gg = e ^ f ^ g
+			} else {
+				t = 0x7a879d8a
+				ff = (a & b) | (a & c) | (b & c)
+				gg = (e & f) | (^e & g)
+			}
+			a12 := bits.RotateLeft32(a, 12)
+			ss1 := bits.RotateLeft32(a12+e+bits.RotateLeft32(t, j%32), 7)
+			ss2 := ss1 ^ a12
+			tt1 := ff + dd + ss2 + w1[j]
+			tt2 := gg + h + ss1 + w[j]
+			dd = c
+			c = bits.RotateLeft32(b, 9)
+			b = a
+			a = tt1
+			h = g
+			g = bits.RotateLeft32(f, 19)
+			f = e
+			e = sm3P0(tt2)
+		}
+		d.h[0] ^= a
+		d.h[1] ^= b
+		d.h[2] ^= c
+		d.h[3] ^= dd
+		d.h[4] ^= e
+		d.h[5] ^= f
+		d.h[6] ^= g
+		d.h[7] ^= h
+		p = p[SM3BlockSize:]
+	}
+}
+
+func sm3P0(x uint32) uint32 {
+	return x ^ bits.RotateLeft32(x, 9) ^ bits.RotateLeft32(x, 17)
+}
+
+func sm3P1(x uint32) uint32 {
+	return x ^ bits.RotateLeft32(x, 15) ^ bits.RotateLeft32(x, 23)
+}
@@ -0,0 +1,241 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/helailiang/sbp-did-sdk-go/pkg/api"
+)
+
+func TestClientContextCancellation(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 模拟慢接口，直到请求被取消或测试结束
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	client := api.NewClient(srv.URL, "")
+
+	// 超时
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.RegisterDIDWithContext(ctx, &api.RegisterDIDRequest{ProjectNo: "p1"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Fatalf("request was not aborted by context deadline")
+	}
+
+	// 主动取消
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel2()
+	}()
+	_, err = client.QueryDIDWithContext(ctx2, &api.QueryDIDRequest{DID: "did:sbp:abc"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestClientWithoutContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/sys/v1/vc/verify" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"code":"0","data":{"verificationStatus":true},"message":"success"}`))
+	}))
+	defer srv.Close()
+
+	client := api.NewClient(srv.URL, "")
+	resp, err := client.VCVerify(&api.VCVerifyRequest{ProjectNo: "p1", VC: "{}"})
+	if err != nil {
+		t.Fatalf("VCVerify failed: %v", err)
+	}
+	if !resp.Data.VerificationStatus {
+		t.Fatalf("expected verificationStatus true")
+	}
+}
+
+func TestClientTypedErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/sys/v1/did/search":
+			// HTTP成功但业务码非"0"
+			w.Write([]byte(`{"code":"404","data":null,"message":"did not found"}`))
+		case "/api/sys/v1/did/register":
+			w.Write([]byte(`{"code":"409","data":null,"message":"did already exists"}`))
+		case "/api/sys/v1/issuer/search":
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"code":"401","message":"invalid token"}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("internal error"))
+		}
+	}))
+	defer srv.Close()
+
+	client := api.NewClient(srv.URL, "")
+
+	_, err := client.QueryDID(&api.QueryDIDRequest{DID: "did:sbp:none"})
+	var apiErr *api.Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *api.Error, got %T %v", err, err)
+	}
+	if apiErr.StatusCode != http.StatusOK || apiErr.Code != "404" || apiErr.Message != "did not found" {
+		t.Fatalf("unexpected error fields: %+v", apiErr)
+	}
+	if !api.IsNotFound(err) || api.IsDuplicate(err) {
+		t.Fatalf("expected not found classification")
+	}
+
+	_, err = client.RegisterDID(&api.RegisterDIDRequest{})
+	if !api.IsDuplicate(err) || !errors.Is(err, api.ErrDuplicate) {
+		t.Fatalf("expected duplicate error, got %v", err)
+	}
+
+	_, err = client.QueryIssuer(&api.QueryIssuerRequest{IssuerDid: "did:sbp:issuer"})
+	if !api.IsUnauthorized(err) {
+		t.Fatalf("expected unauthorized error, got %v", err)
+	}
+
+	_, err = client.VCVerify(&api.VCVerifyRequest{})
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError || string(apiErr.Body) != "internal error" {
+		t.Fatalf("expected 500 *api.Error with raw body, got %v", err)
+	}
+	if api.IsNotFound(err) || api.IsUnauthorized(err) || api.IsDuplicate(err) {
+		t.Fatalf("500 error should not match any classification")
+	}
+}
+
+func TestClientRetry(t *testing.T) {
+	var mu sync.Mutex
+	var keys []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys = append(keys, r.Header.Get(api.IdempotencyKeyHeader))
+		n := len(keys)
+		mu.Unlock()
+		switch n {
+		case 1:
+			// 模拟连接被重置
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Write([]byte(`{"code":"0","data":null,"message":"success"}`))
+		}
+	}))
+	defer srv.Close()
+
+	client := api.NewClient(srv.URL, "")
+	client.Retry = api.DefaultRetryPolicy()
+	client.Retry.InitialBackoff = time.Millisecond
+
+	if _, err := client.RegisterDID(&api.RegisterDIDRequest{ProjectNo: "p1"}); err != nil {
+		t.Fatalf("RegisterDID failed: %v", err)
+	}
+	if len(keys) != 3 {
+		t.Fatalf("expected 3 attempts, got %d", len(keys))
+	}
+	if keys[0] == "" || keys[0] != keys[1] || keys[1] != keys[2] {
+		t.Fatalf("idempotency key should be reused across retries: %v", keys)
+	}
+
+	// 调用方指定幂等键
+	keys = nil
+	ctx := api.WithIdempotencyKey(context.Background(), "vc-001-issue")
+	if _, err := client.IssueVCWithContext(ctx, &api.IssueVCRequest{}); err != nil {
+		t.Fatalf("IssueVC failed: %v", err)
+	}
+	if keys[len(keys)-1] != "vc-001-issue" {
+		t.Fatalf("expected caller supplied idempotency key, got %v", keys)
+	}
+}
+
+func TestClientRetryNotRetryable(t *testing.T) {
+	var count int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		if r.URL.Path == "/api/sys/v1/vc/issue" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	client := api.NewClient(srv.URL, "")
+	client.Retry = api.DefaultRetryPolicy()
+	client.Retry.InitialBackoff = time.Millisecond
+
+	// 400不在重试列表中
+	if _, err := client.IssueVC(&api.IssueVCRequest{}); err == nil {
+		t.Fatalf("expected error")
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 attempt for 400, got %d", count)
+	}
+
+	// 502重试直到用尽次数
+	count = 0
+	_, err := client.VCEvidence(&api.VCEvidenceRequest{})
+	var apiErr *api.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("expected 502 *api.Error, got %v", err)
+	}
+	if count != client.Retry.MaxAttempts {
+		t.Fatalf("expected %d attempts, got %d", client.Retry.MaxAttempts, count)
+	}
+
+	// 未设置重试策略时只请求一次
+	count = 0
+	client.Retry = nil
+	client.VCEvidence(&api.VCEvidenceRequest{})
+	if count != 1 {
+		t.Fatalf("expected 1 attempt without retry policy, got %d", count)
+	}
+}
+
+func TestClientResponseEnvelope(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/sys/v1/did/search":
+			// 空响应体
+		case "/api/sys/v1/did/register":
+			w.Write([]byte(`["not", "an", "envelope"]`))
+		default:
+			// 没有业务码的响应不视为业务错误
+			w.Write([]byte(`{"data":null}`))
+		}
+	}))
+	defer srv.Close()
+
+	client := api.NewClient(srv.URL, "")
+
+	var apiErr *api.Error
+	_, err := client.QueryDID(&api.QueryDIDRequest{DID: "did:sbp:none"})
+	if err == nil || errors.As(err, &apiErr) {
+		t.Fatalf("expected a decode error for an empty body, got %v", err)
+	}
+	_, err = client.RegisterDID(&api.RegisterDIDRequest{})
+	if err == nil || errors.As(err, &apiErr) {
+		t.Fatalf("expected a decode error for a non-envelope body, got %v", err)
+	}
+	if _, err := client.QueryIssuer(&api.QueryIssuerRequest{IssuerDid: "did:sbp:issuer"}); err != nil {
+		t.Fatalf("response without a code should succeed, got %v", err)
+	}
+}
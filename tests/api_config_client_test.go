@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/helailiang/sbp-did-sdk-go/pkg/api"
+	"github.com/helailiang/sbp-did-sdk-go/pkg/api/apitest"
+	"github.com/helailiang/sbp-did-sdk-go/pkg/config"
+)
+
+func TestNewClientFromConfigPrivateProject(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.OpenAPIEndpoint = "http://127.0.0.1:1"
+	cfg.ProjectID = "p1"
+	cfg.ProjectVisibility = "private"
+	if _, err := api.NewClientFromConfig(cfg); err == nil {
+		t.Fatalf("private project without token should be rejected")
+	}
+
+	cfg.Token = "secret"
+	client, err := api.NewClientFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewClientFromConfig failed: %v", err)
+	}
+	if client.Token != "secret" || client.ProjectNO != "p1" {
+		t.Fatalf("unexpected client: token=%q project=%q", client.Token, client.ProjectNO)
+	}
+
+	cfg.OpenAPIEndpoint = ""
+	if _, err := api.NewClientFromConfig(cfg); err == nil {
+		t.Fatalf("missing endpoint should be rejected")
+	}
+}
+
+func TestNewClientFromConfigFillsProjectNo(t *testing.T) {
+	srv := apitest.NewServer()
+	defer srv.Close()
+
+	cfg := config.NewConfig()
+	cfg.OpenAPIEndpoint = srv.URL + "/"
+	cfg.ProjectID = "p1"
+	client, err := api.NewClientFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewClientFromConfig failed: %v", err)
+	}
+
+	reg := &api.RegisterDIDRequest{DIDDocument: `{"id":"did:sbp:a"}`, Signature: "sig"}
+	if _, err := client.RegisterDID(reg); err != nil {
+		t.Fatalf("RegisterDID failed: %v", err)
+	}
+	if reg.ProjectNo != "" {
+		t.Fatalf("caller request should not be modified")
+	}
+	client.QueryDID(&api.QueryDIDRequest{DID: "did:sbp:a"})
+	client.RegisterIssuer(&api.RegisterIssuerRequest{IssuerDid: "did:sbp:a"})
+	client.VCRevokeStatus(&api.VCRevokeStatusRequest{VcId: "vc-1", ProjectNo: "explicit"})
+	// UpdateIssuerRequest没有ProjectNo字段，原样发送
+	client.UpdateIssuer(&api.UpdateIssuerRequest{IssuerDid: "did:sbp:a"})
+
+	want := []string{"p1", "p1", "p1", "explicit", ""}
+	reqs := srv.Requests()
+	if len(reqs) != len(want) {
+		t.Fatalf("expected %d requests, got %d", len(want), len(reqs))
+	}
+	for i, r := range reqs {
+		var body map[string]interface{}
+		json.Unmarshal(r.Body, &body)
+		got, _ := body["projectNo"].(string)
+		if got != want[i] {
+			t.Fatalf("%s: expected projectNo %q, got %q", r.Path, want[i], got)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/helailiang/sbp-did-sdk-go/pkg/api"
+	"github.com/helailiang/sbp-did-sdk-go/pkg/wallet"
+)
+
+// recordedRequest 记录测试服务端收到的请求
+type recordedRequest struct {
+	Method string
+	Path   string
+	Token  string
+	Body   map[string]interface{}
+}
+
+// newRecordingServer 创建记录请求并返回固定响应的测试服务端
+func newRecordingServer(t *testing.T, respBody string, got *recordedRequest) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("read body failed: %v", err)
+		}
+		got.Method = r.Method
+		got.Path = r.URL.Path
+		got.Token = r.Header.Get("token")
+		got.Body = nil
+		if err := json.Unmarshal(data, &got.Body); err != nil {
+			t.Errorf("request body is not JSON: %v", err)
+		}
+		w.Write([]byte(respBody))
+	}))
+}
+
+func TestClientProjectAndTemplateEndpoints(t *testing.T) {
+	template := api.VCTemplate{
+		TemplateId:   "tpl-001",
+		TemplateName: "学历证书",
+		IssuerDid:    "did:sbp:issuer",
+	}
+	cases := []struct {
+		name     string
+		call     func(c *api.Client) error
+		path     string
+		wantBody map[string]interface{}
+	}{
+		{
+			name: "UpdateVCTemplate",
+			call: func(c *api.Client) error {
+				_, err := c.UpdateVCTemplate(&api.UpdateVCTemplateRequest{
+					IssuerDid:  "did:sbp:issuer",
+					ProjectNo:  "p1",
+					VCTemplate: template,
+					Signature:  "sig",
+				})
+				return err
+			},
+			path: "/api/sys/v1/vc/update",
+			wantBody: map[string]interface{}{
+				"issuerDid": "did:sbp:issuer",
+				"projectNo": "p1",
+				"signature": "sig",
+			},
+		},
+		{
+			name: "VCTemplateStatus",
+			call: func(c *api.Client) error {
+				_, err := c.VCTemplateStatus(&api.VCTemplateStatusRequest{
+					IssuerDid:    "did:sbp:issuer",
+					ProjectNo:    "p1",
+					VCTemplateId: "tpl-001",
+					Signature:    "sig",
+					TxSignature:  "txsig",
+				})
+				return err
+			},
+			path: "/api/sys/v1/vc/status/update",
+			wantBody: map[string]interface{}{
+				"issuerDid":    "did:sbp:issuer",
+				"projectNo":    "p1",
+				"vcTemplateId": "tpl-001",
+				"signature":    "sig",
+				"txSignature":  "txsig",
+			},
+		},
+		{
+			name: "SearchVCEvidence",
+			call: func(c *api.Client) error {
+				_, err := c.SearchVCEvidence(&api.VCEvidenceSearchRequest{
+					VcId:      "vc-001",
+					ProjectNo: "p1",
+					IssuerDid: "did:sbp:issuer",
+				})
+				return err
+			},
+			path: "/api/sys/v1/vc/evidence/search",
+			wantBody: map[string]interface{}{
+				"vcId":      "vc-001",
+				"projectNo": "p1",
+				"issuerDid": "did:sbp:issuer",
+			},
+		},
+		{
+			name: "GetToken",
+			call: func(c *api.Client) error {
+				_, err := c.GetToken(&api.GetTokenRequest{
+					ProjectNo:      "p1",
+					ClientName:     "sdk",
+					IsProjectOwner: true,
+				})
+				return err
+			},
+			path: "/api/sys/v1/project/token",
+			wantBody: map[string]interface{}{
+				"projectNo":      "p1",
+				"clientName":     "sdk",
+				"isProjectOwner": true,
+			},
+		},
+		{
+			name: "ProjectEnable",
+			call: func(c *api.Client) error {
+				_, err := c.ProjectEnable(&api.ProjectEnableRequest{ProjectNo: "p1", Status: 1})
+				return err
+			},
+			path: "/api/sys/v1/project/status/update",
+			wantBody: map[string]interface{}{
+				"projectNo": "p1",
+				"status":    float64(1),
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got recordedRequest
+			srv := newRecordingServer(t, `{"code":"0","data":{},"message":"success"}`, &got)
+			defer srv.Close()
+
+			client := api.NewClient(srv.URL, "tk-001")
+			if err := tc.call(client); err != nil {
+				t.Fatalf("%s failed: %v", tc.name, err)
+			}
+			if got.Method != http.MethodPost {
+				t.Fatalf("expected POST, got %s", got.Method)
+			}
+			if got.Path != tc.path {
+				t.Fatalf("expected path %s, got %s", tc.path, got.Path)
+			}
+			if got.Token != "tk-001" {
+				t.Fatalf("expected token header, got %q", got.Token)
+			}
+			for k, v := range tc.wantBody {
+				if !reflect.DeepEqual(got.Body[k], v) {
+					t.Fatalf("body field %s: expected %v, got %v", k, v, got.Body[k])
+				}
+			}
+		})
+	}
+}
+
+func TestClientUpdateVCTemplatePayload(t *testing.T) {
+	var got recordedRequest
+	srv := newRecordingServer(t, `{"code":"0","data":null,"message":"success"}`, &got)
+	defer srv.Close()
+
+	client := api.NewClient(srv.URL, "")
+	_, err := client.UpdateVCTemplate(&api.UpdateVCTemplateRequest{
+		ProjectNo: "p1",
+		VCTemplate: api.VCTemplate{
+			TemplateId:         "tpl-001",
+			RegistrationFields: []api.RegistrationField{{FieldName: "name", Mandatory: true}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("UpdateVCTemplate failed: %v", err)
+	}
+	tpl, ok := got.Body["vcTemplate"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("vcTemplate should be a JSON object, got %T", got.Body["vcTemplate"])
+	}
+	if tpl["templateId"] != "tpl-001" {
+		t.Fatalf("unexpected templateId: %v", tpl["templateId"])
+	}
+	fields, ok := tpl["registrationFields"].([]interface{})
+	if !ok || len(fields) != 1 {
+		t.Fatalf("unexpected registrationFields: %v", tpl["registrationFields"])
+	}
+	if _, ok := got.Body["txSignature"]; ok {
+		t.Fatalf("empty txSignature should be omitted")
+	}
+}
+
+func TestClientGetTokenResponse(t *testing.T) {
+	var got recordedRequest
+	srv := newRecordingServer(t, `{"code":"0","data":{"token":"new-token"},"message":"success"}`, &got)
+	defer srv.Close()
+
+	client := api.NewClient(srv.URL, "")
+	resp, err := client.GetToken(&api.GetTokenRequest{ProjectNo: "p1", ClientName: "sdk"})
+	if err != nil {
+		t.Fatalf("GetToken failed: %v", err)
+	}
+	if resp.Data.Token != "new-token" {
+		t.Fatalf("expected token new-token, got %s", resp.Data.Token)
+	}
+	if _, ok := got.Body["isProjectOwner"]; ok {
+		t.Fatalf("false isProjectOwner should be omitted")
+	}
+}
+
+func TestClientGenerateVP(t *testing.T) {
+	var got recordedRequest
+	srv := newRecordingServer(t, `{"code":"0","data":{"id":"vp-001","@context":["https://www.w3.org/2018/credentials/v1"],"type":["VerifiablePresentation"],"holder":"did:sbp:holder","verifiableCredential":[],"proof":{"type":"JsonWebSignature2020","created":"2024-06-01T00:00:00Z","proofPurpose":"authentication","verificationMethod":"did:sbp:holder#keys-1","jws":"eyJ..sig"}},"message":"success"}`, &got)
+	defer srv.Close()
+
+	client := api.NewClient(srv.URL, "tk-001")
+	resp, err := client.GenerateVP(&api.GenerateVPRequest{
+		ProjectNo: "p1",
+		VerifiablePresentation: wallet.VerifiablePresentation{
+			ID:                   "vp-001",
+			Context:              []string{"https://www.w3.org/2018/credentials/v1"},
+			Type:                 []string{"VerifiablePresentation"},
+			Holder:               "did:sbp:holder",
+			VerifiableCredential: []interface{}{"vc-001"},
+		},
+		Challenge: "nonce-1",
+	})
+	if err != nil {
+		t.Fatalf("GenerateVP failed: %v", err)
+	}
+	if got.Path != "/api/sys/v1/vp/generate" || got.Token != "tk-001" {
+		t.Fatalf("unexpected request: path=%s token=%q", got.Path, got.Token)
+	}
+	if got.Body["projectNo"] != "p1" || got.Body["challenge"] != "nonce-1" {
+		t.Fatalf("unexpected body: %v", got.Body)
+	}
+	vp, ok := got.Body["vp"].(map[string]interface{})
+	if !ok || vp["holder"] != "did:sbp:holder" || vp["id"] != "vp-001" {
+		t.Fatalf("vp should be sent as JSON object, got %v", got.Body["vp"])
+	}
+	if resp.Data.Holder != "did:sbp:holder" || resp.Data.Proof == nil || resp.Data.Proof.Jws != "eyJ..sig" {
+		t.Fatalf("unexpected generated VP: %+v", resp.Data.VerifiablePresentation)
+	}
+}
+
+func TestClientVerifyVP(t *testing.T) {
+	var got recordedRequest
+	srv := newRecordingServer(t, `{"code":"0","data":{"verificationStatus":true},"message":"success"}`, &got)
+	defer srv.Close()
+
+	client := api.NewClient(srv.URL, "tk-001")
+	req := &api.VerifyVPRequest{ProjectNo: "p1"}
+	req.Holder = "did:sbp:holder"
+	req.Proof = &wallet.Proof{Type: "JsonWebSignature2020", Jws: "eyJ..sig"}
+	resp, err := client.VerifyVP(req)
+	if err != nil {
+		t.Fatalf("VerifyVP failed: %v", err)
+	}
+	if got.Path != "/api/sys/v1/vp/verify" || got.Token != "tk-001" {
+		t.Fatalf("unexpected request: path=%s token=%q", got.Path, got.Token)
+	}
+	if !resp.Data.VerificationStatus {
+		t.Fatalf("expected verificationStatus true")
+	}
+
+	// 业务码错误返回*api.Error
+	srv2 := newRecordingServer(t, `{"code":"500","data":null,"message":"invalid proof"}`, &got)
+	defer srv2.Close()
+	_, err = api.NewClient(srv2.URL, "").VerifyVP(req)
+	var apiErr *api.Error
+	if !errors.As(err, &apiErr) || apiErr.Message != "invalid proof" {
+		t.Fatalf("expected *api.Error, got %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+package tests
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/helailiang/sbp-did-sdk-go/pkg/api"
+)
+
+func TestClientMiddlewareHooks(t *testing.T) {
+	var gotUA, gotVersion, gotSig string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUA = r.Header.Get("User-Agent")
+		gotVersion = r.Header.Get(api.SDKVersionHeader)
+		data, _ := ioutil.ReadAll(r.Body)
+		sum := sha256.Sum256(data)
+		if r.Header.Get("X-Signature") == hex.EncodeToString(sum[:]) {
+			gotSig = "valid"
+		}
+		if r.URL.Path == "/api/sys/v1/vc/revoke" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"code":"0","data":null,"message":"success"}`))
+	}))
+	defer srv.Close()
+
+	var order []string
+	var errs []error
+	client := api.NewClient(srv.URL, "")
+	client.Use(
+		api.UserAgentMiddleware(""),
+		api.Middleware{
+			BeforeRequest: func(req *http.Request) error {
+				order = append(order, "before")
+				// 模拟请求签名：对请求体签名后写入请求头
+				body, err := req.GetBody()
+				if err != nil {
+					return err
+				}
+				data, err := ioutil.ReadAll(body)
+				if err != nil {
+					return err
+				}
+				sum := sha256.Sum256(data)
+				req.Header.Set("X-Signature", hex.EncodeToString(sum[:]))
+				return nil
+			},
+			AfterResponse: func(req *http.Request, resp *http.Response, body []byte, elapsed time.Duration) {
+				order = append(order, "after")
+			},
+			OnError: func(req *http.Request, err error, elapsed time.Duration) {
+				order = append(order, "error")
+				errs = append(errs, err)
+			},
+		},
+	)
+
+	if _, err := client.RegisterDID(&api.RegisterDIDRequest{ProjectNo: "p1"}); err != nil {
+		t.Fatalf("RegisterDID failed: %v", err)
+	}
+	if gotUA != api.SDKName+"/"+api.SDKVersion || gotVersion != api.SDKVersion {
+		t.Fatalf("unexpected SDK headers: ua=%q version=%q", gotUA, gotVersion)
+	}
+	if gotSig != "valid" {
+		t.Fatalf("signature header not injected or invalid")
+	}
+	if strings.Join(order, ",") != "before,after" {
+		t.Fatalf("unexpected hook order: %v", order)
+	}
+
+	order = nil
+	if _, err := client.VCRevoke(&api.VCRevokeRequest{}); err == nil {
+		t.Fatalf("expected error")
+	}
+	if strings.Join(order, ",") != "before,after,error" {
+		t.Fatalf("unexpected hook order on error: %v", order)
+	}
+	var apiErr *api.Error
+	if len(errs) != 1 || !errors.As(errs[0], &apiErr) {
+		t.Fatalf("OnError should receive *api.Error, got %v", errs)
+	}
+
+	// BeforeRequest返回错误时中止请求
+	client.Use(api.Middleware{BeforeRequest: func(req *http.Request) error {
+		return errors.New("blocked")
+	}})
+	order = nil
+	if _, err := client.QueryDID(&api.QueryDIDRequest{}); err == nil || !strings.Contains(err.Error(), "blocked") {
+		t.Fatalf("expected middleware error, got %v", err)
+	}
+	if strings.Join(order, ",") != "before" {
+		t.Fatalf("request should not be sent, hooks: %v", order)
+	}
+}
+
+func TestLoggingMiddlewareRedactsToken(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/sys/v1/did/update" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"code":"0","data":null,"message":"success"}`))
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	client := api.NewClient(srv.URL, "secret-token-abcd")
+	client.Use(api.LoggingMiddleware(log.New(&buf, "", 0)))
+
+	client.RegisterDID(&api.RegisterDIDRequest{})
+	client.UpdateDID(&api.UpdateDIDRequest{})
+
+	out := buf.String()
+	if strings.Contains(out, "secret-token") {
+		t.Fatalf("token leaked into log: %s", out)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log lines, got %d: %s", len(lines), out)
+	}
+	if !strings.Contains(lines[0], "path=/api/sys/v1/did/register") || !strings.Contains(lines[0], "status=200") {
+		t.Fatalf("unexpected request log: %s", lines[0])
+	}
+	if !strings.Contains(lines[0], "Token:****abcd") {
+		t.Fatalf("token header should be redacted: %s", lines[0])
+	}
+	if !strings.Contains(lines[1], "openapi error") || !strings.Contains(lines[1], "path=/api/sys/v1/did/update") {
+		t.Fatalf("unexpected error log: %s", lines[1])
+	}
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/sys/v1/vc/verify" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"code":"0","data":null,"message":"success"}`))
+	}))
+	defer srv.Close()
+
+	hist := api.NewLatencyHistogram(nil)
+	client := api.NewClient(srv.URL, "")
+	client.Use(api.MetricsMiddleware(hist))
+
+	for i := 0; i < 3; i++ {
+		client.IssueVC(&api.IssueVCRequest{})
+	}
+	client.VCVerify(&api.VCVerifyRequest{})
+
+	snap := hist.Snapshot()
+	issue := snap["/api/sys/v1/vc/issue"]
+	if issue.Count != 3 || issue.Errors != 0 {
+		t.Fatalf("unexpected issue stats: %+v", issue)
+	}
+	var total int64
+	for _, n := range issue.Counts {
+		total += n
+	}
+	if total != 3 || len(issue.Counts) != len(api.DefaultLatencyBuckets)+1 {
+		t.Fatalf("bucket counts mismatch: %+v", issue.Counts)
+	}
+	verify := snap["/api/sys/v1/vc/verify"]
+	if verify.Count != 1 || verify.Errors != 1 {
+		t.Fatalf("unexpected verify stats: %+v", verify)
+	}
+}
@@ -0,0 +1,165 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/helailiang/sbp-did-sdk-go/pkg/api"
+	"github.com/helailiang/sbp-did-sdk-go/pkg/api/apitest"
+	"github.com/helailiang/sbp-did-sdk-go/pkg/wallet"
+)
+
+func TestSimulatorCredentialLifecycle(t *testing.T) {
+	srv := apitest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	issuerDID := "did:sbp:issuer"
+	holderDID := "did:sbp:holder"
+	for _, id := range []string{issuerDID, holderDID} {
+		doc := `{"id":"` + id + `"}`
+		if _, err := client.RegisterDID(&api.RegisterDIDRequest{ProjectNo: "p1", DIDDocument: doc, Signature: "sig"}); err != nil {
+			t.Fatalf("RegisterDID(%s) failed: %v", id, err)
+		}
+	}
+	_, err := client.RegisterDID(&api.RegisterDIDRequest{ProjectNo: "p1", DIDDocument: `{"id":"` + issuerDID + `"}`, Signature: "sig"})
+	if !api.IsDuplicate(err) {
+		t.Fatalf("expected duplicate error, got %v", err)
+	}
+	doc, err := client.QueryDID(&api.QueryDIDRequest{DID: issuerDID})
+	if err != nil || doc.Data.DIDDocument == "" {
+		t.Fatalf("QueryDID failed: %v", err)
+	}
+	if _, err := client.QueryDID(&api.QueryDIDRequest{DID: "did:sbp:missing"}); !api.IsNotFound(err) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+
+	if _, err := client.RegisterIssuer(&api.RegisterIssuerRequest{IssuerDid: issuerDID, IssuerName: "Issuer", ProjectNo: "p1"}); err != nil {
+		t.Fatalf("RegisterIssuer failed: %v", err)
+	}
+	tpl := api.VCTemplate{TemplateId: "tpl-1", TemplateName: "Degree", IssuerDid: issuerDID}
+	if _, err := client.RegisterVCTemplate(&api.RegisterVCTemplateRequest{IssuerDid: issuerDID, ProjectNo: "p1", VCTemplate: tpl}); err != nil {
+		t.Fatalf("RegisterVCTemplate failed: %v", err)
+	}
+
+	vc := api.VerifiableCredential{ID: "vc-1", Issuer: issuerDID, CredentialSubject: map[string]interface{}{"id": holderDID}}
+	if _, err := client.IssueVC(&api.IssueVCRequest{ProjectNo: "p1", VCTemplateId: "tpl-1", VC: vc}); err != nil {
+		t.Fatalf("IssueVC failed: %v", err)
+	}
+	if _, err := client.VCEvidence(&api.VCEvidenceRequest{VcId: "vc-1", VcHash: "abc", IssuerDid: issuerDID, ProjectNo: "p1"}); err != nil {
+		t.Fatalf("VCEvidence failed: %v", err)
+	}
+	ev, err := client.SearchVCEvidence(&api.VCEvidenceSearchRequest{VcId: "vc-1"})
+	if err != nil || ev.Data.VcHash != "abc" || ev.Data.TxHash == "" {
+		t.Fatalf("SearchVCEvidence mismatch: %+v, %v", ev, err)
+	}
+
+	vcJSON, _ := json.Marshal(vc)
+	verify, err := client.VCVerify(&api.VCVerifyRequest{VC: string(vcJSON)})
+	if err != nil || !verify.Data.VerificationStatus {
+		t.Fatalf("expected VC to verify: %+v, %v", verify, err)
+	}
+
+	// 禁用发证方后VC核验失败，重新启用后恢复
+	client.IssuerStatus(&api.IssuerStatusRequest{IssuerDid: issuerDID})
+	if rec, _ := srv.Issuer(issuerDID); rec.Enabled {
+		t.Fatalf("issuer should be disabled")
+	}
+	verify, _ = client.VCVerify(&api.VCVerifyRequest{VC: string(vcJSON)})
+	if verify.Data.VerificationStatus {
+		t.Fatalf("VC of disabled issuer should not verify")
+	}
+	client.IssuerStatus(&api.IssuerStatusRequest{IssuerDid: issuerDID})
+
+	if _, err := client.VCRevoke(&api.VCRevokeRequest{VC: string(vcJSON)}); err != nil {
+		t.Fatalf("VCRevoke failed: %v", err)
+	}
+	status, err := client.VCRevokeStatus(&api.VCRevokeStatusRequest{VcId: "vc-1"})
+	if err != nil || !status.Data.RevokeStatus {
+		t.Fatalf("expected VC to be revoked: %+v, %v", status, err)
+	}
+	verify, _ = client.VCVerify(&api.VCVerifyRequest{VC: string(vcJSON)})
+	if verify.Data.VerificationStatus {
+		t.Fatalf("revoked VC should not verify")
+	}
+
+	vp, err := client.GenerateVP(&api.GenerateVPRequest{
+		VerifiablePresentation: wallet.VerifiablePresentation{ID: "vp-1", Holder: holderDID},
+		Challenge:              "nonce",
+	})
+	if err != nil || vp.Data.Proof == nil {
+		t.Fatalf("GenerateVP failed: %+v, %v", vp, err)
+	}
+	vpVerify, err := client.VerifyVP(&api.VerifyVPRequest{VerifiablePresentation: vp.Data.VerifiablePresentation, Challenge: "nonce"})
+	if err != nil || !vpVerify.Data.VerificationStatus {
+		t.Fatalf("expected VP to verify: %+v, %v", vpVerify, err)
+	}
+}
+
+func TestSimulatorFaultInjection(t *testing.T) {
+	srv := apitest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+	client.Retry = &api.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, RetryStatusCodes: []int{http.StatusServiceUnavailable}}
+
+	// 前两次返回503，第三次成功；重试请求携带相同幂等键
+	srv.InjectFault(apitest.Fault{Path: "/api/sys/v1/did/register", StatusCode: http.StatusServiceUnavailable, Times: 2})
+	if _, err := client.RegisterDID(&api.RegisterDIDRequest{DIDDocument: `{"id":"did:sbp:a"}`, Signature: "sig"}); err != nil {
+		t.Fatalf("RegisterDID should succeed after retries: %v", err)
+	}
+	reqs := srv.Requests()
+	if len(reqs) != 3 || reqs[0].IdempotencyKey == "" || reqs[0].IdempotencyKey != reqs[2].IdempotencyKey {
+		t.Fatalf("unexpected requests: %+v", reqs)
+	}
+
+	// 相同幂等键的重复提交返回首次结果而非重复错误
+	ctx := api.WithIdempotencyKey(context.Background(), "key-1")
+	for i := 0; i < 2; i++ {
+		if _, err := client.RegisterDIDWithContext(ctx, &api.RegisterDIDRequest{DIDDocument: `{"id":"did:sbp:b"}`, Signature: "sig"}); err != nil {
+			t.Fatalf("idempotent RegisterDID #%d failed: %v", i, err)
+		}
+	}
+
+	// 业务码故障
+	srv.InjectFault(apitest.Fault{Path: "/api/sys/v1/did/search", Code: apitest.CodeUnauthorized, Message: "denied", Times: 1})
+	if _, err := client.QueryDID(&api.QueryDIDRequest{DID: "did:sbp:a"}); !api.IsUnauthorized(err) {
+		t.Fatalf("expected unauthorized, got %v", err)
+	}
+	if _, err := client.QueryDID(&api.QueryDIDRequest{DID: "did:sbp:a"}); err != nil {
+		t.Fatalf("fault should be consumed: %v", err)
+	}
+
+	// 延迟故障触发超时
+	srv.InjectFault(apitest.Fault{Delay: 200 * time.Millisecond})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.QueryDIDWithContext(ctx, &api.QueryDIDRequest{DID: "did:sbp:a"}); err == nil {
+		t.Fatalf("expected timeout error")
+	}
+	srv.ClearFaults()
+}
+
+func TestSimulatorRequireToken(t *testing.T) {
+	srv := apitest.NewServer()
+	defer srv.Close()
+	srv.RequireToken = true
+
+	client := srv.Client()
+	client.TokenProvider = api.NewProjectTokenProvider(client, &api.GetTokenRequest{ProjectNo: "p1"})
+	if _, err := client.RegisterDID(&api.RegisterDIDRequest{DIDDocument: `{"id":"did:sbp:a"}`, Signature: "sig"}); err != nil {
+		t.Fatalf("RegisterDID failed: %v", err)
+	}
+	// 服务端Token失效后客户端自动刷新
+	srv.RevokeTokens()
+	if _, err := client.QueryDID(&api.QueryDIDRequest{DID: "did:sbp:a"}); err != nil {
+		t.Fatalf("QueryDID after token revocation failed: %v", err)
+	}
+
+	anonymous := srv.Client()
+	if _, err := anonymous.QueryDID(&api.QueryDIDRequest{DID: "did:sbp:a"}); !api.IsUnauthorized(err) {
+		t.Fatalf("expected unauthorized without token, got %v", err)
+	}
+}
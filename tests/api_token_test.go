@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/helailiang/sbp-did-sdk-go/pkg/api"
+)
+
+// tokenServer 模拟签发Token并校验Token的OpenAPI服务
+type tokenServer struct {
+	issued  int32 // 已签发Token数量
+	revoked sync.Map
+}
+
+func (s *tokenServer) handler(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/api/sys/v1/project/token" {
+		n := atomic.AddInt32(&s.issued, 1)
+		fmt.Fprintf(w, `{"code":"0","data":{"token":"token-%d"},"message":"success"}`, n)
+		return
+	}
+	token := r.Header.Get("token")
+	if _, revoked := s.revoked.Load(token); token == "" || revoked {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"code":"401","message":"token expired"}`))
+		return
+	}
+	w.Write([]byte(`{"code":"0","data":null,"message":"success"}`))
+}
+
+func TestProjectTokenProviderCachesToken(t *testing.T) {
+	ts := &tokenServer{}
+	srv := httptest.NewServer(http.HandlerFunc(ts.handler))
+	defer srv.Close()
+
+	client := api.NewClient(srv.URL, "")
+	client.TokenProvider = api.NewProjectTokenProvider(client, &api.GetTokenRequest{ProjectNo: "p1", ClientName: "sdk"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.RegisterDID(&api.RegisterDIDRequest{ProjectNo: "p1"}); err != nil {
+				t.Errorf("RegisterDID failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := atomic.LoadInt32(&ts.issued); n != 1 {
+		t.Fatalf("expected token to be fetched once, got %d", n)
+	}
+}
+
+func TestProjectTokenProviderRefreshOn401(t *testing.T) {
+	ts := &tokenServer{}
+	srv := httptest.NewServer(http.HandlerFunc(ts.handler))
+	defer srv.Close()
+
+	client := api.NewClient(srv.URL, "")
+	client.TokenProvider = api.NewProjectTokenProvider(client, &api.GetTokenRequest{ProjectNo: "p1", ClientName: "sdk"})
+
+	if _, err := client.QueryDID(&api.QueryDIDRequest{DID: "did:sbp:abc"}); err != nil {
+		t.Fatalf("QueryDID failed: %v", err)
+	}
+	// 服务端使token-1失效，客户端应刷新后重试
+	ts.revoked.Store("token-1", true)
+	if _, err := client.QueryDID(&api.QueryDIDRequest{DID: "did:sbp:abc"}); err != nil {
+		t.Fatalf("QueryDID after token expiry failed: %v", err)
+	}
+	if n := atomic.LoadInt32(&ts.issued); n != 2 {
+		t.Fatalf("expected token to be refreshed once, got %d issued", n)
+	}
+
+	// 刷新后仍然401时只重试一次
+	ts.revoked.Store("token-2", true)
+	ts.revoked.Store("token-3", true)
+	_, err := client.QueryDID(&api.QueryDIDRequest{DID: "did:sbp:abc"})
+	if !api.IsUnauthorized(err) {
+		t.Fatalf("expected unauthorized error, got %v", err)
+	}
+	if n := atomic.LoadInt32(&ts.issued); n != 3 {
+		t.Fatalf("expected a single refresh attempt, got %d issued", n)
+	}
+}
+
+func TestProjectTokenProviderProactiveRefresh(t *testing.T) {
+	var issued int32
+	exp := time.Now().Add(30 * time.Second).Unix()
+	jwt := "eyJhbGciOiJIUzI1NiJ9." +
+		base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"exp":%d}`, exp))) + ".sig"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&issued, 1)
+		fmt.Fprintf(w, `{"code":"0","data":{"token":"%s"},"message":"success"}`, jwt)
+	}))
+	defer srv.Close()
+
+	provider := api.NewProjectTokenProvider(api.NewClient(srv.URL, ""), &api.GetTokenRequest{ProjectNo: "p1"})
+	provider.RefreshBefore = time.Minute
+	for i := 0; i < 2; i++ {
+		if _, err := provider.Token(context.Background()); err != nil {
+			t.Fatalf("Token failed: %v", err)
+		}
+	}
+	// JWT将在RefreshBefore窗口内过期，每次获取都应刷新
+	if n := atomic.LoadInt32(&issued); n != 2 {
+		t.Fatalf("expected proactive refresh, got %d issued", n)
+	}
+
+	// 缩短刷新窗口后，未过期的Token应直接复用
+	provider.RefreshBefore = time.Second
+	provider.Token(context.Background())
+	provider.Token(context.Background())
+	if n := atomic.LoadInt32(&issued); n != 2 {
+		t.Fatalf("expected cached token to be reused, got %d issued", n)
+	}
+}
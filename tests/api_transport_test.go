@@ -0,0 +1,180 @@
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/helailiang/sbp-did-sdk-go/pkg/api"
+	"github.com/helailiang/sbp-did-sdk-go/pkg/config"
+)
+
+// testPKI 测试用私有CA及其签发的服务端、客户端证书
+type testPKI struct {
+	caPEM     []byte
+	caPool    *x509.CertPool
+	server    tls.Certificate
+	clientPEM []byte
+	clientKey []byte
+	client    tls.Certificate
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTpl, caTpl, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("create CA failed: %v", err)
+	}
+	caCert, _ := x509.ParseCertificate(caDER)
+
+	issue := func(serial int64, cn string, usage x509.ExtKeyUsage) ([]byte, []byte) {
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		tpl := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: cn},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tpl, caCert, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatalf("issue certificate failed: %v", err)
+		}
+		keyDER, _ := x509.MarshalECPrivateKey(key)
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+			pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	}
+
+	p := &testPKI{caPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})}
+	p.caPool = x509.NewCertPool()
+	p.caPool.AddCert(caCert)
+	serverCert, serverKey := issue(2, "gateway", x509.ExtKeyUsageServerAuth)
+	p.server, _ = tls.X509KeyPair(serverCert, serverKey)
+	p.clientPEM, p.clientKey = issue(3, "sdk-client", x509.ExtKeyUsageClientAuth)
+	p.client, _ = tls.X509KeyPair(p.clientPEM, p.clientKey)
+	return p
+}
+
+// newMTLSServer 启动要求客户端证书的OpenAPI网关
+func newMTLSServer(p *testPKI) *httptest.Server {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":"0","data":{"didDocument":"{}"},"message":"` + r.TLS.PeerCertificates[0].Subject.CommonName + `"}`))
+	}))
+	srv.TLS = &tls.Config{
+		Certificates: []tls.Certificate{p.server},
+		ClientCAs:    p.caPool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+	srv.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	srv.StartTLS()
+	return srv
+}
+
+func TestClientMutualTLS(t *testing.T) {
+	p := newTestPKI(t)
+	srv := newMTLSServer(p)
+	defer srv.Close()
+
+	client := api.NewClient(srv.URL, "", api.WithRootCAs(p.caPool), api.WithClientCertificate(p.client))
+	resp, err := client.QueryDID(&api.QueryDIDRequest{DID: "did:sbp:a"})
+	if err != nil {
+		t.Fatalf("QueryDID over mTLS failed: %v", err)
+	}
+	if resp.Message != "sdk-client" {
+		t.Fatalf("server saw unexpected client certificate: %s", resp.Message)
+	}
+
+	// 缺少客户端证书或不信任私有CA时握手失败
+	noCert := api.NewClient(srv.URL, "", api.WithRootCAs(p.caPool))
+	if _, err := noCert.QueryDID(&api.QueryDIDRequest{}); err == nil {
+		t.Fatalf("expected handshake failure without client certificate")
+	}
+	noCA := api.NewClient(srv.URL, "", api.WithClientCertificate(p.client))
+	if _, err := noCA.QueryDID(&api.QueryDIDRequest{}); err == nil {
+		t.Fatalf("expected handshake failure without private CA")
+	}
+}
+
+func TestClientOptionsFromConfig(t *testing.T) {
+	p := newTestPKI(t)
+	srv := newMTLSServer(p)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, data, 0600); err != nil {
+			t.Fatalf("write %s failed: %v", name, err)
+		}
+		return path
+	}
+	cfg := config.NewConfig()
+	cfg.TimeoutSeconds = 5
+	cfg.TLSCertFile = write("client.pem", p.clientPEM)
+	cfg.TLSKeyFile = write("client.key", p.clientKey)
+	cfg.TLSCAFile = write("ca.pem", p.caPEM)
+
+	opts, err := api.OptionsFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("OptionsFromConfig failed: %v", err)
+	}
+	client := api.NewClient(srv.URL, "", opts...)
+	if client.HTTPClient.Timeout != 5*time.Second {
+		t.Fatalf("unexpected timeout: %v", client.HTTPClient.Timeout)
+	}
+	if _, err := client.QueryDID(&api.QueryDIDRequest{}); err != nil {
+		t.Fatalf("QueryDID with config options failed: %v", err)
+	}
+
+	cfg.TLSCAFile = filepath.Join(dir, "missing.pem")
+	if _, err := api.OptionsFromConfig(cfg); err == nil {
+		t.Fatalf("expected error for missing CA file")
+	}
+}
+
+func TestClientProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 代理收到的是绝对URI形式的请求
+		proxied = r.URL.String()
+		w.Write([]byte(`{"code":"0","data":null,"message":"success"}`))
+	}))
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	client := api.NewClient("http://openapi.internal", "", api.WithProxy(proxyURL), api.WithTimeout(2*time.Second))
+	if _, err := client.RegisterDID(&api.RegisterDIDRequest{}); err != nil {
+		t.Fatalf("RegisterDID via proxy failed: %v", err)
+	}
+	if proxied != "http://openapi.internal/api/sys/v1/did/register" {
+		t.Fatalf("request did not go through proxy: %q", proxied)
+	}
+	if client.HTTPClient.Timeout != 2*time.Second {
+		t.Fatalf("unexpected timeout: %v", client.HTTPClient.Timeout)
+	}
+}
@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	mrand "math/rand"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/helailiang/sbp-did-sdk-go/pkg/crypto"
+)
+
+// 随机消息多次签名：签名长度固定、low-S、格式可识别且可验证
+func TestECDSASignatureFormatsProperty(t *testing.T) {
+	iterations := 3000
+	if testing.Short() {
+		iterations = 300
+	}
+	keys := map[string]*ecdsa.PrivateKey{}
+	k1, _ := btcec.NewPrivateKey()
+	keys["secp256k1"] = k1.ToECDSA()
+	keys["P-256"], _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	rng := mrand.New(mrand.NewSource(1))
+	formats := []crypto.SignatureFormat{crypto.SignatureFormatP1363, crypto.SignatureFormatDER, crypto.SignatureFormatRecoverable}
+	for name, priv := range keys {
+		halfN := new(big.Int).Rsh(priv.Curve.Params().N, 1)
+		for i := 0; i < iterations; i++ {
+			msg := make([]byte, rng.Intn(128)+1)
+			rng.Read(msg)
+			digest := sha256.Sum256(msg)
+			format := formats[i%len(formats)]
+			if format == crypto.SignatureFormatRecoverable && name != "secp256k1" {
+				format = crypto.SignatureFormatP1363
+			}
+
+			sig, err := crypto.SignECDSA(nil, priv, digest[:], format)
+			if err != nil {
+				t.Fatalf("%s/%s #%d: SignECDSA failed: %v", name, format, i, err)
+			}
+			switch format {
+			case crypto.SignatureFormatP1363:
+				if len(sig) != 64 {
+					t.Fatalf("%s #%d: P1363 signature length %d", name, i, len(sig))
+				}
+			case crypto.SignatureFormatRecoverable:
+				if len(sig) != 65 || sig[64] > 1 {
+					t.Fatalf("%s #%d: invalid recoverable signature %x", name, i, sig)
+				}
+			}
+
+			_, s, detected, err := crypto.ParseECDSASignature(priv.Curve, sig)
+			if err != nil || detected != format {
+				t.Fatalf("%s #%d: detected %s, want %s (%v)", name, i, detected, format, err)
+			}
+			if s.Cmp(halfN) > 0 {
+				t.Fatalf("%s #%d: signature is not low-S", name, i)
+			}
+			if !crypto.VerifyECDSA(&priv.PublicKey, digest[:], sig) {
+				t.Fatalf("%s/%s #%d: VerifyECDSA failed for %x", name, format, i, sig)
+			}
+			if i%50 == 0 {
+				bad := append([]byte(nil), sig...)
+				bad[len(bad)/2] ^= 0x80
+				if crypto.VerifyECDSA(&priv.PublicKey, digest[:], bad) {
+					t.Fatalf("%s/%s #%d: tampered signature verified", name, format, i)
+				}
+			}
+			if format == crypto.SignatureFormatRecoverable {
+				pub, err := crypto.RecoverSecp256k1PublicKey(digest[:], sig)
+				if err != nil || pub.X.Cmp(priv.X) != 0 || pub.Y.Cmp(priv.Y) != 0 {
+					t.Fatalf("#%d: public key recovery failed: %v", i, err)
+				}
+			}
+		}
+	}
+}
+
+func TestSignWithFormat(t *testing.T) {
+	cfg := newCryptoTestConfig()
+	kp, _ := crypto.GenerateKeyPair(cfg, crypto.AlgorithmSecp256k1, "fmt-key")
+	msg := []byte("format option")
+
+	for _, format := range []crypto.SignatureFormat{crypto.SignatureFormatDER, crypto.SignatureFormatP1363, crypto.SignatureFormatRecoverable} {
+		res, err := crypto.SignWithFormat(cfg, kp, msg, crypto.AlgorithmSecp256k1, format)
+		if err != nil {
+			t.Fatalf("%s: SignWithFormat failed: %v", format, err)
+		}
+		if res.Format != format {
+			t.Fatalf("%s: result format %s", format, res.Format)
+		}
+		sig, _ := hex.DecodeString(res.Signature)
+		v, err := crypto.VerifySignature(cfg, kp, msg, sig, crypto.AlgorithmSecp256k1)
+		if err != nil || !v.Valid {
+			t.Fatalf("%s: VerifySignature failed: %v", format, err)
+		}
+	}
+
+	p256, _ := crypto.GenerateKeyPair(cfg, crypto.AlgorithmP256, "p256")
+	if _, err := crypto.SignWithFormat(cfg, p256, msg, crypto.AlgorithmP256, crypto.SignatureFormatRecoverable); err == nil {
+		t.Fatalf("recoverable format should be rejected for P-256")
+	}
+	rsaKP, _ := crypto.GenerateKeyPair(cfg, "RSA", "rsa")
+	if _, err := crypto.SignWithFormat(cfg, rsaKP, msg, "RSA", crypto.SignatureFormatDER); err == nil {
+		t.Fatalf("signature format should be rejected for RSA")
+	}
+	if _, err := crypto.SignWithFormat(cfg, kp, msg, crypto.AlgorithmSecp256k1, "JOSE"); err == nil {
+		t.Fatalf("unknown signature format should be rejected")
+	}
+}
+
+// 短r/s（高位为0）的签名必须补齐为定长
+func TestECDSASignaturePadding(t *testing.T) {
+	priv, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	found := false
+	for i := 0; i < 5000 && !found; i++ {
+		digest := sha256.Sum256([]byte{byte(i), byte(i >> 8)})
+		sig, err := crypto.SignECDSA(nil, priv, digest[:], crypto.SignatureFormatP1363)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sig[0] == 0 || sig[32] == 0 {
+			found = true
+			if len(sig) != 64 || !crypto.VerifyECDSA(&priv.PublicKey, digest[:], sig) {
+				t.Fatalf("padded signature does not verify: %x", sig)
+			}
+		}
+	}
+	if !found {
+		t.Skip("no short r/s produced")
+	}
+}
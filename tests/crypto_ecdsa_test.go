@@ -0,0 +1,180 @@
+package tests
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/helailiang/sbp-did-sdk-go/pkg/crypto"
+	"github.com/helailiang/sbp-did-sdk-go/pkg/did"
+)
+
+var ecAlgorithmCases = []struct {
+	algorithm string
+	crv       string
+	vmType    string
+	sigLen    int
+}{
+	{crypto.AlgorithmECDSA, "secp256k1", did.TypeEcdsaSecp256k1VerificationKey2019, 64},
+	{crypto.AlgorithmSecp256k1, "secp256k1", did.TypeEcdsaSecp256k1VerificationKey2019, 64},
+	{crypto.AlgorithmP256, "P-256", did.TypeJsonWebKey2020, 64},
+	{crypto.AlgorithmP384, "P-384", did.TypeJsonWebKey2020, 96},
+}
+
+func TestECAlgorithmsSignAndDocument(t *testing.T) {
+	cfg := newCryptoTestConfig()
+	msg := []byte("hello curves")
+	for _, c := range ecAlgorithmCases {
+		cfg.DefaultAlgorithm = c.algorithm
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("%s should be a valid algorithm: %v", c.algorithm, err)
+		}
+		kp, err := crypto.GenerateKeyPair(cfg, c.algorithm, "ec-key")
+		if err != nil {
+			t.Fatalf("%s: GenerateKeyPair failed: %v", c.algorithm, err)
+		}
+		sig, err := crypto.Sign(cfg, kp, msg, c.algorithm)
+		if err != nil {
+			t.Fatalf("%s: Sign failed: %v", c.algorithm, err)
+		}
+		sigBytes, _ := hex.DecodeString(sig.Signature)
+		if len(sigBytes) != c.sigLen {
+			t.Fatalf("%s: signature length %d, want %d", c.algorithm, len(sigBytes), c.sigLen)
+		}
+		res, err := crypto.VerifySignature(cfg, kp, msg, sigBytes, c.algorithm)
+		if err != nil || !res.Valid {
+			t.Fatalf("%s: VerifySignature failed: %v", c.algorithm, err)
+		}
+
+		pubPEM, err := kp.GetPublicKeyPEM()
+		if err != nil {
+			t.Fatalf("%s: GetPublicKeyPEM failed: %v", c.algorithm, err)
+		}
+		block, _ := pem.Decode([]byte(pubPEM))
+		if _, err := crypto.ParseECPublicKey(block.Bytes); err != nil {
+			t.Fatalf("%s: public key PEM is not SPKI: %v", c.algorithm, err)
+		}
+		privPEM, _ := kp.GetPrivateKeyPEM()
+		block, _ = pem.Decode([]byte(privPEM))
+		if _, err := crypto.ParseECPrivateKey(block.Bytes); err != nil {
+			t.Fatalf("%s: private key PEM is not SEC1: %v", c.algorithm, err)
+		}
+
+		didID, _ := did.CalculateDIDIdentifier(kp, "did:sbp:")
+		doc, err := did.AssembleDIDDocument(cfg, kp, c.algorithm, didID, nil)
+		if err != nil {
+			t.Fatalf("%s: AssembleDIDDocument failed: %v", c.algorithm, err)
+		}
+		vm := doc.VerificationMethod[0]
+		jwk := vm.PublicKeyJwk.(*did.PublicKeyJwk)
+		if vm.Type != c.vmType || jwk.Crv != c.crv {
+			t.Fatalf("%s: got type %s crv %s", c.algorithm, vm.Type, jwk.Crv)
+		}
+	}
+}
+
+func TestECAlgorithmCurveMismatch(t *testing.T) {
+	cfg := newCryptoTestConfig()
+	kp, _ := crypto.GenerateKeyPair(cfg, crypto.AlgorithmP256, "p256")
+	if _, err := crypto.Sign(cfg, kp, []byte("x"), crypto.AlgorithmSecp256k1); err == nil {
+		t.Fatalf("P-256 key must not sign as SECP256K1")
+	}
+	didID, _ := did.CalculateDIDIdentifier(kp, "did:sbp:")
+	if _, err := did.AssembleDIDDocument(cfg, kp, crypto.AlgorithmP384, didID, nil); err == nil {
+		t.Fatalf("P-256 key must not be labelled as P384")
+	}
+
+	// 历史标识ECDSA以密钥实际曲线为准，不再将P-256误标为secp256k1
+	doc, err := did.AssembleDIDDocument(cfg, kp, crypto.AlgorithmECDSA, didID, nil)
+	if err != nil {
+		t.Fatalf("AssembleDIDDocument failed: %v", err)
+	}
+	if doc.VerificationMethod[0].Type != did.TypeJsonWebKey2020 {
+		t.Fatalf("P-256 key labelled as %s", doc.VerificationMethod[0].Type)
+	}
+}
+
+func TestLocalKeyManagerECCurves(t *testing.T) {
+	km := crypto.NewLocalKeyManager()
+	curves := map[crypto.KeyType]string{
+		crypto.ECDSAP256:      "P-256",
+		crypto.ECDSAP384:      "P-384",
+		crypto.ECDSASecp256k1: "secp256k1",
+	}
+	for keyType, crv := range curves {
+		keyID, pubDER, err := km.Create(keyType)
+		if err != nil {
+			t.Fatalf("%s: Create failed: %v", keyType, err)
+		}
+		pub, err := crypto.ParseECPublicKey(pubDER)
+		if err != nil {
+			t.Fatalf("%s: ParseECPublicKey failed: %v", keyType, err)
+		}
+		if got, _ := crypto.JWKCurveName(pub.Curve); got != crv {
+			t.Fatalf("%s: curve %s, want %s", keyType, got, crv)
+		}
+		digest := make([]byte, 32)
+		rand.Read(digest)
+		sig, err := km.Sign(keyID, digest)
+		if err != nil {
+			t.Fatalf("%s: Sign failed: %v", keyType, err)
+		}
+		if ok, err := km.Verify(keyID, digest, sig); err != nil || !ok {
+			t.Fatalf("%s: Verify failed: %v", keyType, err)
+		}
+
+		der, err := km.ExportPrivateKey(keyID)
+		if err != nil {
+			t.Fatalf("%s: ExportPrivateKey failed: %v", keyType, err)
+		}
+		if _, err := km.ImportPrivateKey(der, keyType); err != nil {
+			t.Fatalf("%s: ImportPrivateKey failed: %v", keyType, err)
+		}
+
+		vm, err := did.NewVerificationMethodFromKeyManager("did:sbp:abc", keyID, crypto.AlgorithmECDSA, km)
+		if err != nil {
+			t.Fatalf("%s: NewVerificationMethodFromKeyManager failed: %v", keyType, err)
+		}
+		if vm.PublicKeyJwk.(*did.PublicKeyJwk).Crv != crv {
+			t.Fatalf("%s: verification method has wrong crv", keyType)
+		}
+	}
+
+	// 导入曲线与密钥类型不一致的私钥应失败
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ := crypto.MarshalECPrivateKey(p256)
+	if _, err := km.ImportPrivateKey(der, crypto.ECDSASecp256k1); err == nil {
+		t.Fatalf("curve mismatch on import should fail")
+	}
+}
+
+func TestParseECPrivateKeySecp256k1ScalarRange(t *testing.T) {
+	k1, _ := btcec.NewPrivateKey()
+	priv := k1.ToECDSA()
+	der, err := crypto.MarshalECPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := crypto.ParseECPrivateKey(der)
+	if err != nil || parsed.D.Cmp(priv.D) != 0 || parsed.X.Cmp(priv.X) != 0 {
+		t.Fatalf("ParseECPrivateKey round trip failed: %v", err)
+	}
+
+	d := priv.D.FillBytes(make([]byte, 32))
+	for name, scalar := range map[string][]byte{
+		"zero": make([]byte, 32),
+		"N":    btcec.S256().Params().N.FillBytes(make([]byte, 32)),
+		"N+1":  new(big.Int).Add(btcec.S256().Params().N, big.NewInt(1)).FillBytes(make([]byte, 32)),
+	} {
+		bad := bytes.Replace(der, d, scalar, 1)
+		if _, err := crypto.ParseECPrivateKey(bad); err == nil {
+			t.Fatalf("ParseECPrivateKey accepted a %s scalar", name)
+		}
+	}
+}
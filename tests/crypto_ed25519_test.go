@@ -0,0 +1,176 @@
+package tests
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/helailiang/sbp-did-sdk-go/pkg/crypto"
+	"github.com/helailiang/sbp-did-sdk-go/pkg/did"
+	"github.com/helailiang/sbp-did-sdk-go/pkg/utils"
+)
+
+func TestEd25519KeyPairSignVerify(t *testing.T) {
+	cfg := newCryptoTestConfig()
+	cfg.DefaultAlgorithm = "ED25519"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("ED25519 should be a valid default algorithm: %v", err)
+	}
+
+	kp, err := crypto.GenerateKeyPair(cfg, "ED25519", "ed-key")
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	msg := []byte("hello ed25519")
+	sig, err := crypto.Sign(cfg, kp, msg, "ED25519")
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	sigBytes, _ := hex.DecodeString(sig.Signature)
+	if len(sigBytes) != ed25519.SignatureSize {
+		t.Fatalf("unexpected signature length %d", len(sigBytes))
+	}
+	res, err := crypto.VerifySignature(cfg, kp, msg, sigBytes, "ED25519")
+	if err != nil || !res.Valid {
+		t.Fatalf("VerifySignature failed: %v", err)
+	}
+	res, _ = crypto.VerifySignature(cfg, kp, []byte("tampered"), sigBytes, "ED25519")
+	if res.Valid {
+		t.Fatalf("tampered message should not verify")
+	}
+
+	// 原始格式导出
+	pub, _ := kp.GetPublicKeyBytes()
+	seed, _ := kp.GetPrivateKeyBytes()
+	if len(pub) != ed25519.PublicKeySize || len(seed) != ed25519.SeedSize {
+		t.Fatalf("unexpected raw key sizes: %d, %d", len(pub), len(seed))
+	}
+	if !bytes.Equal(ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey), pub) {
+		t.Fatalf("seed does not match public key")
+	}
+
+	// PEM导出为SPKI / PKCS#8
+	pubPEM, _ := kp.GetPublicKeyPEM()
+	block, _ := pem.Decode([]byte(pubPEM))
+	parsedPub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil || !bytes.Equal(parsedPub.(ed25519.PublicKey), pub) {
+		t.Fatalf("public key PEM round trip failed: %v", err)
+	}
+	privPEM, _ := kp.GetPrivateKeyPEM()
+	block, _ = pem.Decode([]byte(privPEM))
+	if block.Type != "PRIVATE KEY" {
+		t.Fatalf("unexpected private key PEM type %s", block.Type)
+	}
+	if _, err := x509.ParsePKCS8PrivateKey(block.Bytes); err != nil {
+		t.Fatalf("private key PEM is not PKCS#8: %v", err)
+	}
+}
+
+// RFC 8032 7.1 TEST 2
+func TestEd25519StandardVector(t *testing.T) {
+	seed, _ := hex.DecodeString("4ccd089b28ff96da9db6c346ec114e0f5b8a319f35aba624da8cf6ed4fb8a6fb")
+	wantSig := "92a009a9f0d4cab8720e820b5f642540a2b27b5416503f8fb3762223ebdb69da085ac1e43e15996e458f3613d0f11d8c387b2eaeb4302aeeb00d291612bb0c00"
+
+	km := crypto.NewLocalKeyManager()
+	keyID, err := km.ImportPrivateKey(seed, crypto.ED25519)
+	if err != nil {
+		t.Fatalf("ImportPrivateKey failed: %v", err)
+	}
+	sig, err := km.Sign(keyID, []byte{0x72})
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if hex.EncodeToString(sig) != wantSig {
+		t.Fatalf("signature mismatch: %x", sig)
+	}
+
+	exported, err := km.ExportPrivateKey(keyID)
+	if err != nil {
+		t.Fatalf("ExportPrivateKey failed: %v", err)
+	}
+	reimported, err := km.ImportPrivateKey(exported, crypto.ED25519)
+	if err != nil {
+		t.Fatalf("re-import of PKCS#8 failed: %v", err)
+	}
+	ok, err := km.Verify(reimported, []byte{0x72}, sig)
+	if err != nil || !ok {
+		t.Fatalf("verify with re-imported key failed: %v", err)
+	}
+}
+
+func TestEd25519VerificationMethods(t *testing.T) {
+	cfg := newCryptoTestConfig()
+	kp, err := crypto.GenerateKeyPair(cfg, "ED25519", "ed-did")
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	didID, _ := did.CalculateDIDIdentifier(kp, "did:sbp:")
+	doc, err := did.AssembleDIDDocument(cfg, kp, "ED25519", didID, nil)
+	if err != nil {
+		t.Fatalf("AssembleDIDDocument failed: %v", err)
+	}
+	vm := doc.VerificationMethod[0]
+	if vm.Type != did.TypeEd25519VerificationKey2020 || !strings.HasPrefix(vm.PublicKeyMultibase, "z6Mk") {
+		t.Fatalf("unexpected verification method: %+v", vm)
+	}
+	pub, err := did.Ed25519PublicKeyFromMultibase(vm.PublicKeyMultibase)
+	if err != nil || !bytes.Equal(pub, kp.PublicKey.(ed25519.PublicKey)) {
+		t.Fatalf("multibase round trip failed: %v", err)
+	}
+	found := false
+	for _, c := range doc.Context {
+		found = found || c == did.Ed25519Context
+	}
+	if !found {
+		t.Fatalf("Ed25519 context missing: %v", doc.Context)
+	}
+
+	jwkVM, err := did.NewEd25519VerificationMethod(didID+"#keys-2", didID, pub, did.TypeJsonWebKey2020)
+	if err != nil {
+		t.Fatalf("NewEd25519VerificationMethod failed: %v", err)
+	}
+	raw, _ := json.Marshal(jwkVM)
+	var out map[string]interface{}
+	json.Unmarshal(raw, &out)
+	jwk := out["publicKeyJwk"].(map[string]interface{})
+	if jwk["kty"] != "OKP" || jwk["crv"] != "Ed25519" || out["publicKeyMultibase"] != vm.PublicKeyMultibase {
+		t.Fatalf("unexpected JsonWebKey2020 method: %s", raw)
+	}
+
+	// 通过KeyManager生成的Ed25519密钥
+	km := crypto.NewLocalKeyManager()
+	keyID, _, _ := km.Create(crypto.ED25519)
+	kmVM, err := did.NewVerificationMethodFromKeyManager(didID, keyID, "ED25519", km)
+	if err != nil || kmVM.Type != did.TypeEd25519VerificationKey2020 || kmVM.PublicKeyMultibase == "" {
+		t.Fatalf("NewVerificationMethodFromKeyManager failed: %+v, %v", kmVM, err)
+	}
+}
+
+func TestBase58Multibase(t *testing.T) {
+	cases := map[string]string{
+		"":                         "",
+		"\x00\x00\x01":             "112",
+		"hello world":              "StV1DL6CwTryKyV",
+		"\x00\x00\x28\x7f\xb4\xcd": "11233QC4",
+	}
+	for in, want := range cases {
+		if got := utils.EncodeBase58([]byte(in)); got != want {
+			t.Fatalf("EncodeBase58(%x) = %s, want %s", in, got, want)
+		}
+		dec, err := utils.DecodeBase58(want)
+		if err != nil || string(dec) != in {
+			t.Fatalf("DecodeBase58(%s) = %x, %v", want, dec, err)
+		}
+	}
+	if _, err := utils.DecodeBase58("0OIl"); err == nil {
+		t.Fatalf("invalid characters should be rejected")
+	}
+	if _, err := utils.DecodeMultibase("f00"); err == nil {
+		t.Fatalf("non base58btc multibase should be rejected")
+	}
+}
@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/helailiang/sbp-did-sdk-go/pkg/crypto"
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+func TestEncryptDecryptECIES(t *testing.T) {
+	cfg := newCryptoTestConfig()
+	msg := []byte("hello ECIES")
+
+	kp, err := crypto.GenerateKeyPair(cfg, "ECDSA", "ecies-k1")
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	enc, err := crypto.Encrypt(cfg, kp, msg, "ECDSA")
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	ct, _ := hex.DecodeString(enc.EncryptedData)
+	if ct[0] != crypto.EnvelopeVersion1 || ct[1] != crypto.SchemeECIESSecp256k1 {
+		t.Fatalf("unexpected envelope header: %x", ct[:2])
+	}
+	dec, err := crypto.Decrypt(cfg, kp, ct, "ECDSA")
+	if err != nil {
+		t.Fatalf("Decrypt failed: %v", err)
+	}
+	if dec.DecryptedData != string(msg) {
+		t.Fatalf("round trip mismatch: %q", dec.DecryptedData)
+	}
+
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ct, err = crypto.EncryptECIES(nil, &p256.PublicKey, msg)
+	if err != nil {
+		t.Fatalf("EncryptECIES failed: %v", err)
+	}
+	if ct[1] != crypto.SchemeECIESP256 {
+		t.Fatalf("expected P-256 scheme, got %d", ct[1])
+	}
+	pt, err := crypto.DecryptECIES(p256, ct)
+	if err != nil || !bytes.Equal(pt, msg) {
+		t.Fatalf("P-256 round trip failed: %v", err)
+	}
+
+	// 篡改密文、错误的版本或方案均应解密失败
+	for _, i := range []int{0, 1, 2, len(ct) - 1} {
+		bad := append([]byte(nil), ct...)
+		bad[i] ^= 0x01
+		if _, err := crypto.DecryptECIES(p256, bad); err == nil {
+			t.Fatalf("tampered ciphertext at %d should fail", i)
+		}
+	}
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if _, err := crypto.DecryptECIES(other, ct); err == nil {
+		t.Fatalf("decryption with wrong key should fail")
+	}
+}
+
+func TestEncryptDecryptSM2(t *testing.T) {
+	cfg := newCryptoTestConfig()
+	msg := []byte("国密SM2加密")
+
+	kp, err := crypto.GenerateKeyPair(cfg, "SM2", "sm2-enc")
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	enc, err := crypto.Encrypt(cfg, kp, msg, "SM2")
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	ct, _ := hex.DecodeString(enc.EncryptedData)
+	if ct[0] != crypto.EnvelopeVersion1 || ct[1] != crypto.SchemeSM2 {
+		t.Fatalf("unexpected envelope header: %x", ct[:2])
+	}
+	dec, err := crypto.Decrypt(cfg, kp, ct, "SM2")
+	if err != nil || dec.DecryptedData != string(msg) {
+		t.Fatalf("SM2 round trip failed: %v", err)
+	}
+
+	bad := append([]byte(nil), ct...)
+	bad[len(bad)-1] ^= 0x01
+	if _, err := crypto.Decrypt(cfg, kp, bad, "SM2"); err == nil {
+		t.Fatalf("tampered SM2 ciphertext should fail")
+	}
+	bad = append([]byte(nil), ct...)
+	bad[1] = crypto.SchemeECIESP256
+	if _, err := crypto.Decrypt(cfg, kp, bad, "SM2"); err == nil {
+		t.Fatalf("scheme mismatch should fail")
+	}
+	if _, err := crypto.Decrypt(cfg, kp, ct, "ECDSA"); err == nil {
+		t.Fatalf("SM2 key should not decrypt as ECDSA")
+	}
+}
+
+// 与gmsm的C1C3C2密文互通
+func TestSM2EncryptionInterop(t *testing.T) {
+	priv, err := crypto.GenerateSM2Key(nil)
+	if err != nil {
+		t.Fatalf("GenerateSM2Key failed: %v", err)
+	}
+	msg := bytes.Repeat([]byte("interop"), 20)
+
+	ours, err := crypto.EncryptSM2(nil, &priv.PublicKey, msg)
+	if err != nil {
+		t.Fatalf("EncryptSM2 failed: %v", err)
+	}
+	pt, err := sm2.Decrypt(priv, ours, sm2.C1C3C2)
+	if err != nil || !bytes.Equal(pt, msg) {
+		t.Fatalf("gmsm failed to decrypt our ciphertext: %v", err)
+	}
+
+	theirs, err := sm2.Encrypt(&priv.PublicKey, msg, rand.Reader, sm2.C1C3C2)
+	if err != nil {
+		t.Fatalf("gmsm Encrypt failed: %v", err)
+	}
+	pt, err = crypto.DecryptSM2(priv, theirs)
+	if err != nil || !bytes.Equal(pt, msg) {
+		t.Fatalf("failed to decrypt gmsm ciphertext: %v", err)
+	}
+}
+
+func TestLocalKeyManagerEncrypt(t *testing.T) {
+	km := crypto.NewLocalKeyManager()
+	msg := []byte("hello local encryption")
+	for _, keyType := range []crypto.KeyType{crypto.ECDSAP256, crypto.RSA2048, crypto.SM2} {
+		keyID, _, err := km.Create(keyType)
+		if err != nil {
+			t.Fatalf("%s: Create failed: %v", keyType, err)
+		}
+		ct, err := km.Encrypt(keyID, msg)
+		if err != nil {
+			t.Fatalf("%s: Encrypt failed: %v", keyType, err)
+		}
+		pt, err := km.Decrypt(keyID, ct)
+		if err != nil || !bytes.Equal(pt, msg) {
+			t.Fatalf("%s: Decrypt failed: %v", keyType, err)
+		}
+		ct[len(ct)-1] ^= 0x01
+		if _, err := km.Decrypt(keyID, ct); err == nil {
+			t.Fatalf("%s: tampered ciphertext decrypted", keyType)
+		}
+	}
+	if _, err := km.Encrypt("missing", msg); err == nil {
+		t.Fatal("Encrypt with unknown key should fail")
+	}
+}
@@ -0,0 +1,308 @@
+package tests
+
+import (
+	"bytes"
+	stdcrypto "crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+	"net/http"
+	"testing"
+
+	"github.com/helailiang/sbp-did-sdk-go/pkg/crypto"
+	"github.com/helailiang/sbp-did-sdk-go/pkg/crypto/kmsemu"
+)
+
+func TestHuaweiKMSSignVerify(t *testing.T) {
+	_, km := newTestHuaweiKMS(t)
+	cfg := newCryptoTestConfig()
+	msg := []byte("kms signed message")
+	cases := []struct {
+		keyType   crypto.KeyType
+		algorithm string
+		data      []byte
+	}{
+		{crypto.ECDSAP256, crypto.AlgorithmP256, kmsDigest(sha256.New(), msg)},
+		{crypto.ECDSASecp256k1, crypto.AlgorithmSecp256k1, kmsDigest(sha256.New(), msg)},
+		{crypto.ECDSAP384, crypto.AlgorithmP384, kmsDigest(sha512.New384(), msg)},
+		{crypto.RSA2048, "RSA", kmsDigest(sha256.New(), msg)},
+		{crypto.SM2, "SM2", msg},
+	}
+	for _, c := range cases {
+		keyID, pubDER, err := km.Create(c.keyType)
+		if err != nil {
+			t.Fatalf("%s: Create failed: %v", c.keyType, err)
+		}
+		pub, err := crypto.ParsePublicKey(pubDER, "")
+		if err != nil {
+			t.Fatalf("%s: KMS public key does not parse: %v", c.keyType, err)
+		}
+		if pub.Algorithm != crypto.KeyTypeAlgorithm(c.keyType) {
+			t.Fatalf("%s: public key algorithm = %s", c.keyType, pub.Algorithm)
+		}
+		sig, err := km.Sign(keyID, c.data)
+		if err != nil {
+			t.Fatalf("%s: Sign failed: %v", c.keyType, err)
+		}
+		valid, err := km.Verify(keyID, c.data, sig)
+		if err != nil || !valid {
+			t.Fatalf("%s: Verify failed: %v", c.keyType, err)
+		}
+		// KMS签名须能用普通验签函数对原始消息验证
+		res, err := crypto.VerifySignature(cfg, pub, msg, sig, c.algorithm)
+		if err != nil || !res.Valid {
+			t.Fatalf("%s: KMS signature does not verify locally: %v", c.keyType, err)
+		}
+		tampered := append([]byte(nil), c.data...)
+		tampered[0] ^= 0xff
+		if valid, err := km.Verify(keyID, tampered, sig); err != nil || valid {
+			t.Fatalf("%s: tampered data verified: %v", c.keyType, err)
+		}
+	}
+}
+
+func TestHuaweiKMSRSADigestSizes(t *testing.T) {
+	_, km := newTestHuaweiKMS(t)
+	keyID, pubDER, err := km.Create(crypto.RSA2048)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	pub, _ := crypto.ParsePublicKey(pubDER, "")
+	for _, h := range []stdcrypto.Hash{stdcrypto.SHA256, stdcrypto.SHA384, stdcrypto.SHA512} {
+		digest := kmsDigest(h.New(), []byte("digest size"))
+		sig, err := km.SignRSADigest(keyID, h, digest)
+		if err != nil {
+			t.Fatalf("%v: SignRSADigest failed: %v", h, err)
+		}
+		if err := rsa.VerifyPKCS1v15(pub.PublicKey.(*rsa.PublicKey), h, digest, sig); err != nil {
+			t.Fatalf("%v: signature does not verify: %v", h, err)
+		}
+		if valid, err := km.VerifyRSADigest(keyID, h, digest, sig); err != nil || !valid {
+			t.Fatalf("%v: VerifyRSADigest = %v, %v", h, valid, err)
+		}
+	}
+	// Sign只接受SHA-256摘要，SHA-384/512须使用SignRSADigest
+	for _, size := range []int{20, 33, 48, 64, 128} {
+		if _, err := km.Sign(keyID, make([]byte, size)); err == nil {
+			t.Fatalf("Sign accepted a %d-byte RSA digest", size)
+		}
+		if _, err := km.Verify(keyID, make([]byte, size), make([]byte, 256)); err == nil {
+			t.Fatalf("Verify accepted a %d-byte RSA digest", size)
+		}
+	}
+	if _, err := km.SignRSADigest(keyID, stdcrypto.SHA384, kmsDigest(sha256.New(), []byte("x"))); err == nil {
+		t.Fatal("SignRSADigest accepted a digest whose length does not match the hash")
+	}
+	ecID, _, err := km.Create(crypto.ECDSAP256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := km.SignRSADigest(ecID, stdcrypto.SHA256, kmsDigest(sha256.New(), []byte("x"))); err == nil {
+		t.Fatal("SignRSADigest accepted an EC key")
+	}
+}
+
+func TestHuaweiKMSDataKeyEnvelope(t *testing.T) {
+	srv, km := newTestHuaweiKMS(t)
+	keyID, pub, err := km.Create(crypto.AES256)
+	if err != nil {
+		t.Fatalf("Create AES256 failed: %v", err)
+	}
+	if pub != nil {
+		t.Fatal("AES256 key should have no public key")
+	}
+	if _, err := km.Get(keyID); err == nil {
+		t.Fatal("Get should fail for a symmetric key")
+	}
+	if _, err := km.Sign(keyID, make([]byte, 32)); err == nil {
+		t.Fatal("Sign should fail for an encryption key")
+	}
+
+	plaintext := bytes.Repeat([]byte("envelope "), 1000)
+	ct, err := km.Encrypt(keyID, plaintext)
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if ct[0] != crypto.EnvelopeVersion1 || ct[1] != crypto.SchemeKMSDataKey {
+		t.Fatalf("unexpected envelope header %x", ct[:2])
+	}
+	if srv != nil && srv.Calls("create-datakey") != 1 {
+		t.Fatalf("expected one data key request, got %d", srv.Calls("create-datakey"))
+	}
+	got, err := km.Decrypt(keyID, ct)
+	if err != nil || !bytes.Equal(got, plaintext) {
+		t.Fatalf("Decrypt failed: %v", err)
+	}
+	ct2, _ := km.Encrypt(keyID, plaintext)
+	if bytes.Equal(ct, ct2) {
+		t.Fatal("each encryption should use a fresh data key")
+	}
+
+	tampered := append([]byte(nil), ct...)
+	tampered[len(tampered)-1] ^= 0x01
+	if _, err := km.Decrypt(keyID, tampered); err == nil {
+		t.Fatal("tampered ciphertext decrypted")
+	}
+	tampered = append([]byte(nil), ct...)
+	tampered[5] ^= 0x01 // 加密的数据密钥
+	if _, err := km.Decrypt(keyID, tampered); err == nil {
+		t.Fatal("ciphertext with tampered data key decrypted")
+	}
+	otherID, _, _ := km.Create(crypto.AES256)
+	if _, err := km.Decrypt(otherID, ct); err == nil {
+		t.Fatal("ciphertext decrypted with another master key")
+	}
+	if _, _, err := km.Create(crypto.AES256, crypto.WithPurpose(crypto.PurposeSigning)); err == nil {
+		t.Fatal("AES256 signing key should be rejected")
+	}
+}
+
+func TestHuaweiKMSAsymmetricEncryption(t *testing.T) {
+	_, km := newTestHuaweiKMS(t)
+	for _, keyType := range []crypto.KeyType{crypto.RSA2048, crypto.SM2} {
+		keyID, _, err := km.Create(keyType, crypto.WithPurpose(crypto.PurposeEncryption))
+		if err != nil {
+			t.Fatalf("%s: Create failed: %v", keyType, err)
+		}
+		ct, err := km.Encrypt(keyID, []byte("short secret"))
+		if err != nil {
+			t.Fatalf("%s: Encrypt failed: %v", keyType, err)
+		}
+		got, err := km.Decrypt(keyID, ct)
+		if err != nil || string(got) != "short secret" {
+			t.Fatalf("%s: Decrypt failed: %v", keyType, err)
+		}
+		if _, err := km.Sign(keyID, make([]byte, 32)); err == nil {
+			t.Fatalf("%s: Sign should fail for an encryption key", keyType)
+		}
+	}
+	for _, keyType := range []crypto.KeyType{crypto.ECDSAP256, crypto.ECDSASecp256k1} {
+		if _, _, err := km.Create(keyType, crypto.WithPurpose(crypto.PurposeEncryption)); err == nil {
+			t.Fatalf("%s: encryption purpose should be rejected", keyType)
+		}
+	}
+	signID, _, _ := km.Create(crypto.SM2)
+	if _, err := km.Encrypt(signID, []byte("x")); err == nil {
+		t.Fatal("Encrypt should fail for a signing key")
+	}
+}
+
+func TestHuaweiKMSListDeleteAndDescribe(t *testing.T) {
+	srv, km := newTestHuaweiKMS(t)
+	if srv == nil {
+		t.Skip("requires the in-process KMS emulator")
+	}
+	srv.PageSize = 2
+	created := map[string]bool{}
+	for _, keyType := range []crypto.KeyType{crypto.ECDSAP256, crypto.SM2, crypto.AES256, crypto.ECDSASecp256k1, crypto.RSA2048} {
+		keyID, _, err := km.Create(keyType)
+		if err != nil {
+			t.Fatalf("%s: Create failed: %v", keyType, err)
+		}
+		created[keyID] = true
+	}
+	ids, err := km.List()
+	if err != nil || len(ids) != len(created) {
+		t.Fatalf("List returned %v, %v", ids, err)
+	}
+	for _, id := range ids {
+		if !created[id] {
+			t.Fatalf("unexpected key %s", id)
+		}
+	}
+	if srv.Calls("list-keys") < 3 {
+		t.Fatalf("expected paginated listing, got %d calls", srv.Calls("list-keys"))
+	}
+
+	// 新实例没有本地缓存，通过describe-key获取密钥规格
+	km2, err := srv.KeyManager()
+	if err != nil {
+		t.Fatalf("NewHuaweiKMSKeyManager failed: %v", err)
+	}
+	digest := kmsDigest(sha256.New(), []byte("describe"))
+	for id := range created {
+		sig, err := km.Sign(id, digest)
+		if err != nil {
+			continue // AES256
+		}
+		if valid, err := km2.Verify(id, digest, sig); err != nil || !valid {
+			t.Fatalf("Verify with a fresh manager failed: %v", err)
+		}
+	}
+	if srv.Calls("describe-key") == 0 {
+		t.Fatal("fresh manager did not describe keys")
+	}
+
+	for id := range created {
+		if err := km.Delete(id); err != nil {
+			t.Fatalf("Delete failed: %v", err)
+		}
+		if _, err := km.Get(id); err == nil {
+			t.Fatal("Get should fail after Delete")
+		}
+		if rec, _ := srv.Key(id); rec.KeyState != kmsemu.KeyStatePendingDeletion || rec.PendingDays != "7" {
+			t.Fatalf("unexpected key state after Delete: %+v", rec)
+		}
+	}
+	if ids, _ := km.List(); len(ids) != 0 {
+		t.Fatalf("keys left after Delete: %v", ids)
+	}
+	if _, err := km.Sign("missing", digest); err == nil {
+		t.Fatal("Sign with an unknown key should fail")
+	}
+	if _, err := km.ImportPrivateKey([]byte("k"), crypto.SM2); err == nil {
+		t.Fatal("ImportPrivateKey should not be supported")
+	}
+}
+
+func TestKMSEmulatorFaultsAndKeyState(t *testing.T) {
+	srv, km := newTestHuaweiKMS(t)
+	if srv == nil {
+		t.Skip("requires the in-process KMS emulator")
+	}
+	keyID, _, err := km.Create(crypto.ECDSAP256)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	digest := kmsDigest(sha256.New(), []byte("fault"))
+
+	srv.InjectFault(kmsemu.Fault{Action: "sign", StatusCode: http.StatusServiceUnavailable, Message: "busy", Times: 1})
+	if _, err := km.Sign(keyID, digest); err == nil {
+		t.Fatal("expected injected sign failure")
+	}
+	if _, err := km.Sign(keyID, digest); err != nil {
+		t.Fatalf("fault should apply once: %v", err)
+	}
+
+	srv.SetKeyState(keyID, kmsemu.KeyStateDisabled)
+	if _, err := km.Sign(keyID, digest); err == nil {
+		t.Fatal("disabled key should not sign")
+	}
+	if ids, _ := km.List(); len(ids) != 0 {
+		t.Fatalf("disabled key listed: %v", ids)
+	}
+	srv.SetKeyState(keyID, kmsemu.KeyStateEnabled)
+
+	// 摘要长度与算法不符时KMS拒绝请求
+	if _, err := km.Sign(keyID, []byte("not a digest")); err == nil {
+		t.Fatal("expected error for a non-digest message")
+	}
+	bad, err := crypto.NewHuaweiKMSKeyManager(srv.URL, "other-ak", kmsemu.SecretKey, kmsemu.ProjectID)
+	if err != nil {
+		t.Fatalf("NewHuaweiKMSKeyManager failed: %v", err)
+	}
+	if _, err := bad.Get(keyID); err == nil {
+		t.Fatal("request with unknown credentials should fail")
+	}
+	for _, r := range srv.Requests() {
+		if r.Method != http.MethodPost || r.ProjectID != kmsemu.ProjectID {
+			t.Fatalf("unexpected request %+v", r)
+		}
+	}
+}
+
+func kmsDigest(h hash.Hash, msg []byte) []byte {
+	h.Write(msg)
+	return h.Sum(nil)
+}
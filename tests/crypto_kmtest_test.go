@@ -0,0 +1,25 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/helailiang/sbp-did-sdk-go/pkg/crypto"
+	"github.com/helailiang/sbp-did-sdk-go/pkg/crypto/kmtest"
+)
+
+func TestLocalKeyManagerConformance(t *testing.T) {
+	kmtest.Run(t, func(t *testing.T) kmtest.Backend {
+		return crypto.NewLocalKeyManager()
+	})
+}
+
+func TestHuaweiKMSKeyManagerConformance(t *testing.T) {
+	kmtest.Run(t, func(t *testing.T) kmtest.Backend {
+		_, km := newTestHuaweiKMS(t)
+		return km
+	},
+		kmtest.WithKeyTypes(crypto.ECDSAP256, crypto.ECDSAP384, crypto.ECDSASecp256k1, crypto.RSA2048, crypto.SM2, crypto.AES256),
+		kmtest.WithEncryptionKeyTypes(crypto.AES256, crypto.RSA2048, crypto.SM2),
+		kmtest.WithoutExport(),
+	)
+}
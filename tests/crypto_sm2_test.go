@@ -0,0 +1,173 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"testing"
+
+	"github.com/helailiang/sbp-did-sdk-go/pkg/config"
+	"github.com/helailiang/sbp-did-sdk-go/pkg/crypto"
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+// newCryptoTestConfig 返回通过校验的测试配置
+func newCryptoTestConfig() *config.Config {
+	cfg := config.NewConfig()
+	cfg.HuaweiCloudEndpoint = "https://kms.example.com"
+	cfg.HuaweiCloudAccessKey = "ak"
+	cfg.HuaweiCloudSecretKey = "sk"
+	cfg.OpenAPIEndpoint = "https://openapi.example.com"
+	cfg.ProjectID = "p1"
+	return cfg
+}
+
+func mustBig(t *testing.T, s string) *big.Int {
+	t.Helper()
+	v, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		t.Fatalf("invalid hex integer %s", s)
+	}
+	return v
+}
+
+// GM/T 0003.5 推荐曲线签名示例
+func TestSM2StandardVector(t *testing.T) {
+	d, _ := hex.DecodeString("3945208F7B2144B13F36E38AC6D39F95889393692860B51A42FB81EF4DF7C5B8")
+	priv, err := crypto.NewSM2PrivateKey(d)
+	if err != nil {
+		t.Fatalf("NewSM2PrivateKey failed: %v", err)
+	}
+	if priv.X.Cmp(mustBig(t, "09F9DF311E5421A150DD7D161E4BC5C672179FAD1833FC076BB08FF356F35020")) != 0 ||
+		priv.Y.Cmp(mustBig(t, "CCEA490CE26775A52DC6EA718CC1AA600AED05FBF35E084A6632F6072DA9AD13")) != 0 {
+		t.Fatalf("unexpected public key: %X, %X", priv.X, priv.Y)
+	}
+
+	za, err := crypto.SM2ZA(&priv.PublicKey, []byte("1234567812345678"))
+	if err != nil {
+		t.Fatalf("SM2ZA failed: %v", err)
+	}
+	if got := hex.EncodeToString(za); got != "b2e14c5c79c6df5b85f4fe7ed8db7a262b9da7e07ccb0ea9f4747b8ccda8a4f3" {
+		t.Fatalf("unexpected ZA: %s", got)
+	}
+
+	// 随机数k在[1, n-1]内按 k = (b mod (n-1)) + 1 生成，构造b = k-1以复现示例中的k
+	k := mustBig(t, "59276E27D506861A16680F3AD9C02DCCEF3CC1FA3CDBE4CE6D54B80DEAC1BC21")
+	nonce := new(big.Int).Sub(k, big.NewInt(1)).FillBytes(make([]byte, 40))
+	msg := []byte("message digest")
+	sig, err := crypto.SignSM2(bytes.NewReader(nonce), priv, msg, nil)
+	if err != nil {
+		t.Fatalf("SignSM2 failed: %v", err)
+	}
+	var rs struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(sig, &rs); err != nil {
+		t.Fatalf("signature is not DER: %v", err)
+	}
+	if rs.R.Cmp(mustBig(t, "F5A03B0648D2C4630EEAC513E1BB81A15944DA3827D5B74143AC7EACEEE720B3")) != 0 ||
+		rs.S.Cmp(mustBig(t, "B1B6AA29DF212FD8763182BC0D421CA1BB9038FD1F7F42D4840B69C485BBC1AA")) != 0 {
+		t.Fatalf("unexpected signature r=%X s=%X", rs.R, rs.S)
+	}
+	if !crypto.VerifySM2(&priv.PublicKey, msg, nil, sig) {
+		t.Fatalf("standard signature should verify")
+	}
+	if crypto.VerifySM2(&priv.PublicKey, msg, []byte("ALICE123@YAHOO.COM"), sig) {
+		t.Fatalf("signature should be bound to the user id")
+	}
+}
+
+func TestSM2KeyPairSignVerify(t *testing.T) {
+	cfg := newCryptoTestConfig()
+	kp, err := crypto.GenerateKeyPair(cfg, "SM2", "sm2-key")
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	pub, ok := kp.PublicKey.(*sm2.PublicKey)
+	if !ok || pub.Curve.Params().Name != crypto.SM2Curve().Params().Name {
+		t.Fatalf("SM2 key pair should use sm2p256v1, got %T", kp.PublicKey)
+	}
+
+	data := []byte("sbp did document")
+	res, err := crypto.Sign(cfg, kp, data, "SM2")
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	sig, _ := hex.DecodeString(res.Signature)
+	vr, err := crypto.VerifySignature(cfg, kp, data, sig, "SM2")
+	if err != nil || !vr.Valid {
+		t.Fatalf("VerifySignature failed: %+v, %v", vr, err)
+	}
+	vr, _ = crypto.VerifySignature(cfg, kp, []byte("tampered"), sig, "SM2")
+	if vr.Valid {
+		t.Fatalf("tampered data should not verify")
+	}
+
+	// PEM导出后可重新解析
+	pubPEM, err := kp.GetPublicKeyPEM()
+	if err != nil {
+		t.Fatalf("GetPublicKeyPEM failed: %v", err)
+	}
+	block, _ := pem.Decode([]byte(pubPEM))
+	parsedPub, err := crypto.ParseSM2PublicKey(block.Bytes)
+	if err != nil || parsedPub.X.Cmp(pub.X) != 0 || parsedPub.Y.Cmp(pub.Y) != 0 {
+		t.Fatalf("public key round trip failed: %v", err)
+	}
+	privPEM, err := kp.GetPrivateKeyPEM()
+	if err != nil {
+		t.Fatalf("GetPrivateKeyPEM failed: %v", err)
+	}
+	block, _ = pem.Decode([]byte(privPEM))
+	if block.Type != "PRIVATE KEY" {
+		t.Fatalf("unexpected private key PEM type: %s", block.Type)
+	}
+	parsedPriv, err := crypto.ParseSM2PrivateKey(block.Bytes)
+	if err != nil {
+		t.Fatalf("ParseSM2PrivateKey failed: %v", err)
+	}
+	if !crypto.VerifySM2(parsedPub, data, nil, mustSignSM2(t, parsedPriv, data)) {
+		t.Fatalf("parsed key pair should interoperate")
+	}
+}
+
+func mustSignSM2(t *testing.T, priv *sm2.PrivateKey, data []byte) []byte {
+	t.Helper()
+	sig, err := crypto.SignSM2(nil, priv, data, nil)
+	if err != nil {
+		t.Fatalf("SignSM2 failed: %v", err)
+	}
+	return sig
+}
+
+func TestLocalKeyManagerSM2(t *testing.T) {
+	km := crypto.NewLocalKeyManager()
+	keyID, pubDER, err := km.Create(crypto.SM2)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	pub, err := crypto.ParseSM2PublicKey(pubDER)
+	if err != nil {
+		t.Fatalf("ParseSM2PublicKey failed: %v", err)
+	}
+	msg := []byte("hello sm2")
+	sig, err := km.Sign(keyID, msg)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if !crypto.VerifySM2(pub, msg, nil, sig) {
+		t.Fatalf("key manager signature should verify with exported public key")
+	}
+
+	exported, err := km.ExportPrivateKey(keyID)
+	if err != nil {
+		t.Fatalf("ExportPrivateKey failed: %v", err)
+	}
+	imported, err := km.ImportPrivateKey(exported, crypto.SM2)
+	if err != nil {
+		t.Fatalf("ImportPrivateKey failed: %v", err)
+	}
+	valid, err := km.Verify(imported, msg, sig)
+	if err != nil || !valid {
+		t.Fatalf("imported key should verify original signature: %v", err)
+	}
+}
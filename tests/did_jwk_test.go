@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"crypto/rsa"
+	"encoding/hex"
+	"testing"
+
+	"github.com/helailiang/sbp-did-sdk-go/pkg/crypto"
+	"github.com/helailiang/sbp-did-sdk-go/pkg/did"
+)
+
+// RFC 7638 3.1 与 RFC 8037 A.3 的指纹向量
+func TestJwkThumbprintVectors(t *testing.T) {
+	rsaJwk := &did.PublicKeyJwk{
+		Kty: "RSA",
+		N:   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E:   "AQAB",
+	}
+	if tp, err := rsaJwk.Thumbprint(); err != nil || tp != "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs" {
+		t.Fatalf("RSA thumbprint mismatch: %s %v", tp, err)
+	}
+	edJwk := &did.PublicKeyJwk{Kty: "OKP", Crv: "Ed25519", X: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}
+	if tp, err := edJwk.Thumbprint(); err != nil || tp != "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k" {
+		t.Fatalf("Ed25519 thumbprint mismatch: %s %v", tp, err)
+	}
+
+	// RSA公钥转换为JWK后n、e与RFC示例一致
+	pub, err := rsaJwk.PublicKey()
+	if err != nil {
+		t.Fatalf("RSA JWK to public key failed: %v", err)
+	}
+	jwk, err := did.NewPublicKeyJwk(pub.(*rsa.PublicKey))
+	if err != nil || jwk.N != rsaJwk.N || jwk.E != "AQAB" || jwk.Kid != "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs" {
+		t.Fatalf("RSA JWK round trip mismatch: %+v %v", jwk, err)
+	}
+}
+
+func TestJwkRoundTripAllAlgorithms(t *testing.T) {
+	cfg := newCryptoTestConfig()
+	for _, alg := range parseAlgorithms {
+		kp, err := crypto.GenerateKeyPair(cfg, alg, "jwk-"+alg)
+		if err != nil {
+			t.Fatalf("%s: GenerateKeyPair failed: %v", alg, err)
+		}
+		jwk, err := did.NewPublicKeyJwk(kp)
+		if err != nil {
+			t.Fatalf("%s: NewPublicKeyJwk failed: %v", alg, err)
+		}
+		if tp, _ := jwk.Thumbprint(); jwk.Kid == "" || jwk.Kid != tp {
+			t.Fatalf("%s: kid %q is not the thumbprint %q", alg, jwk.Kid, tp)
+		}
+
+		// 文档序列化后publicKeyJwk为map形式，仍可还原公钥并验签
+		didID, _ := did.CalculateDIDIdentifier(kp, "did:sbp:")
+		doc, err := did.AssembleDIDDocument(cfg, kp, alg, didID, nil)
+		if err != nil {
+			t.Fatalf("%s: AssembleDIDDocument failed: %v", alg, err)
+		}
+		data, _ := doc.ToJSON()
+		parsed, err := did.FromJSON(data)
+		if err != nil {
+			t.Fatalf("%s: FromJSON failed: %v", alg, err)
+		}
+		pub, err := parsed.VerificationMethod[0].PublicKey()
+		if err != nil {
+			t.Fatalf("%s: VerificationMethod.PublicKey failed: %v", alg, err)
+		}
+		sig, _ := crypto.Sign(cfg, kp, []byte("jwk"), alg)
+		raw, _ := hex.DecodeString(sig.Signature)
+		res, err := crypto.VerifySignature(cfg, pub, []byte("jwk"), raw, alg)
+		if err != nil || !res.Valid {
+			t.Fatalf("%s: signature does not verify with key from DID document: %v", alg, err)
+		}
+
+		if alg == "ED25519" {
+			continue
+		}
+		vmJwk, err := parsed.VerificationMethod[0].GetPublicKeyJwk()
+		if err != nil || *vmJwk != *jwk {
+			t.Fatalf("%s: publicKeyJwk changed after JSON round trip: %+v %v", alg, vmJwk, err)
+		}
+	}
+}
+
+func TestParsePublicKeyJwkRejectsInvalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"private":  `{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo","d":"nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A"}`,
+		"offCurve": `{"kty":"EC","crv":"P-256","x":"AQ","y":"AQ"}`,
+		"unknown":  `{"kty":"oct","k":"AQ"}`,
+	} {
+		if _, err := did.ParsePublicKeyJwk([]byte(data)); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
+
+func TestVerificationMethodFromKeyManagerRSA(t *testing.T) {
+	km := crypto.NewLocalKeyManager()
+	keyID, _, err := km.Create(crypto.RSA2048)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	vm, err := did.NewVerificationMethodFromKeyManager("did:sbp:rsa", keyID, "RSA", km)
+	if err != nil {
+		t.Fatalf("NewVerificationMethodFromKeyManager failed: %v", err)
+	}
+	pub, err := vm.PublicKey()
+	if err != nil {
+		t.Fatalf("PublicKey failed: %v", err)
+	}
+	der, _ := km.Get(keyID)
+	want, err := crypto.ParsePublicKey(der, "RSA")
+	if err != nil {
+		t.Fatalf("ParsePublicKey failed: %v", err)
+	}
+	if pub.PublicKey.(*rsa.PublicKey).N.Cmp(want.PublicKey.(*rsa.PublicKey).N) != 0 {
+		t.Fatalf("RSA modulus in publicKeyJwk does not match the stored key")
+	}
+}
+
+func TestAssembleDIDDocumentHexPublicKey(t *testing.T) {
+	cfg := newCryptoTestConfig()
+	kp, err := crypto.GenerateKeyPair(cfg, "RSA", "jwk-hex")
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	pubHex, err := kp.GetPublicKeyHex()
+	if err != nil {
+		t.Fatalf("GetPublicKeyHex failed: %v", err)
+	}
+	doc, err := did.AssembleDIDDocument(cfg, pubHex, "RSA", "did:sbp:hex", nil)
+	if err != nil {
+		t.Fatalf("AssembleDIDDocument failed: %v", err)
+	}
+	if doc.VerificationMethod[0].PublicKeyJwk == nil {
+		t.Fatal("publicKeyJwk missing for a hex public key")
+	}
+	// 无法解析的公钥返回错误，而不是生成缺少publicKeyJwk的验证方法
+	if _, err := did.AssembleDIDDocument(cfg, "not-a-public-key", "RSA", "did:sbp:hex", nil); err == nil {
+		t.Fatal("AssembleDIDDocument accepted an unparsable public key")
+	}
+}
@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/helailiang/sbp-did-sdk-go/pkg/crypto"
+	"github.com/helailiang/sbp-did-sdk-go/pkg/did"
+	"github.com/helailiang/sbp-did-sdk-go/pkg/jose"
+)
+
+type jweTestKey struct {
+	keyID string
+	vm    *did.VerificationMethod
+}
+
+// newKeyAgreementDID 创建以给定密钥类型作为keyAgreement的DID文档
+func newKeyAgreementDID(t *testing.T, km crypto.KeyManager, didID string, keyTypes ...crypto.KeyType) (*did.DIDDocument, []jweTestKey) {
+	t.Helper()
+	doc := did.AssembleMultiKeyDIDDocument(didID, nil, nil, nil)
+	var keys []jweTestKey
+	for _, keyType := range keyTypes {
+		keyID, _, err := km.Create(keyType)
+		if err != nil {
+			t.Fatalf("%s: Create failed: %v", keyType, err)
+		}
+		vm, err := did.NewVerificationMethodFromKeyManager(didID, keyID, crypto.KeyTypeAlgorithm(keyType), km)
+		if err != nil {
+			t.Fatalf("%s: NewVerificationMethodFromKeyManager failed: %v", keyType, err)
+		}
+		doc.AddKey(*vm, "keyAgreement")
+		keys = append(keys, jweTestKey{keyID: keyID, vm: vm})
+	}
+	return doc, keys
+}
+
+func TestJWEMultipleDIDs(t *testing.T) {
+	km := crypto.NewLocalKeyManager()
+	alice, aliceKeys := newKeyAgreementDID(t, km, "did:sbp:alice", crypto.ECDSAP256)
+	bob, bobKeys := newKeyAgreementDID(t, km, "did:sbp:bob", crypto.RSA2048, crypto.ECDSASecp256k1, crypto.ECDSAP384)
+
+	// 经过JSON往返的DID文档（publicKeyJwk为map形式）
+	data, _ := bob.ToJSON()
+	bob, err := did.FromJSON(data)
+	if err != nil {
+		t.Fatalf("FromJSON failed: %v", err)
+	}
+
+	plaintext := []byte(`{"message":"hello DIDs"}`)
+	jwe, err := jose.EncryptForDIDs(plaintext, []*did.DIDDocument{alice, bob}, jose.WithAAD([]byte("context")))
+	if err != nil {
+		t.Fatalf("EncryptForDIDs failed: %v", err)
+	}
+	if len(jwe.Recipients) != 4 {
+		t.Fatalf("expected 4 recipients, got %d", len(jwe.Recipients))
+	}
+	serialized, err := jwe.Serialize()
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	parsed, err := jose.ParseJWE(serialized)
+	if err != nil {
+		t.Fatalf("ParseJWE failed: %v", err)
+	}
+
+	wantAlg := []string{jose.AlgECDHESA256KW, jose.AlgRSAOAEP256, jose.AlgECDHESA256KW, jose.AlgECDHESA256KW}
+	for i, k := range append(aliceKeys, bobKeys...) {
+		r := parsed.Recipients[i]
+		if r.Header.Kid != k.vm.ID || r.Header.Alg != wantAlg[i] {
+			t.Fatalf("recipient %d: got kid %s alg %s", i, r.Header.Kid, r.Header.Alg)
+		}
+		if (r.Header.Alg == jose.AlgECDHESA256KW) != (r.Header.Epk != nil) {
+			t.Fatalf("recipient %d: unexpected epk %+v", i, r.Header.Epk)
+		}
+		dec, err := jose.NewKeyManagerDecrypter(km, k.keyID, k.vm.ID)
+		if err != nil {
+			t.Fatalf("recipient %d: NewKeyManagerDecrypter failed: %v", i, err)
+		}
+		got, err := parsed.Decrypt(dec)
+		if err != nil || string(got) != string(plaintext) {
+			t.Fatalf("recipient %d: Decrypt failed: %v", i, err)
+		}
+	}
+
+	// 未列为接收者的密钥无法解密
+	_, others := newKeyAgreementDID(t, km, "did:sbp:eve", crypto.ECDSAP256)
+	dec, _ := jose.NewKeyManagerDecrypter(km, others[0].keyID, "")
+	if _, err := parsed.Decrypt(dec); !errors.Is(err, jose.ErrNoMatchingRecipient) {
+		t.Fatalf("expected ErrNoMatchingRecipient, got %v", err)
+	}
+}
+
+func TestJWETampering(t *testing.T) {
+	cfg := newCryptoTestConfig()
+	kp, _ := crypto.GenerateKeyPair(cfg, crypto.AlgorithmP256, "jwe")
+	jwe, err := jose.Encrypt([]byte("secret"), []jose.RecipientKey{{Kid: "did:sbp:x#keys-1", PublicKey: kp}})
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	dec, _ := jose.NewKeyPairDecrypter(kp, "#keys-1")
+	if got, err := jwe.Decrypt(dec); err != nil || string(got) != "secret" {
+		t.Fatalf("Decrypt failed: %v", err)
+	}
+
+	tampered := *jwe
+	tampered.AAD = base64.RawURLEncoding.EncodeToString([]byte("extra"))
+	if _, err := tampered.Decrypt(dec); err == nil {
+		t.Fatalf("JWE with modified aad decrypted")
+	}
+	tampered = *jwe
+	ct, _ := base64.RawURLEncoding.DecodeString(jwe.Ciphertext)
+	ct[0] ^= 1
+	tampered.Ciphertext = base64.RawURLEncoding.EncodeToString(ct)
+	if _, err := tampered.Decrypt(dec); err == nil {
+		t.Fatalf("JWE with modified ciphertext decrypted")
+	}
+	protected, _ := json.Marshal(map[string]string{"enc": "A128GCM"})
+	tampered = *jwe
+	tampered.Protected = base64.RawURLEncoding.EncodeToString(protected)
+	if _, err := tampered.Decrypt(dec); err == nil {
+		t.Fatalf("JWE with unsupported enc decrypted")
+	}
+}
+
+func TestJWEFlattenedAndUnsupportedKeys(t *testing.T) {
+	cfg := newCryptoTestConfig()
+	kp, _ := crypto.GenerateKeyPair(cfg, "RSA", "jwe")
+	jwe, err := jose.Encrypt([]byte("flat"), []jose.RecipientKey{{PublicKey: kp}})
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	flattened, _ := json.Marshal(map[string]interface{}{
+		"protected":     jwe.Protected,
+		"header":        jwe.Recipients[0].Header,
+		"encrypted_key": jwe.Recipients[0].EncryptedKey,
+		"iv":            jwe.IV,
+		"ciphertext":    jwe.Ciphertext,
+		"tag":           jwe.Tag,
+	})
+	parsed, err := jose.ParseJWE(flattened)
+	if err != nil {
+		t.Fatalf("ParseJWE(flattened) failed: %v", err)
+	}
+	dec, _ := jose.NewKeyPairDecrypter(kp, "")
+	if got, err := parsed.Decrypt(dec); err != nil || string(got) != "flat" {
+		t.Fatalf("Decrypt failed: %v", err)
+	}
+
+	for _, alg := range []string{"ED25519", "SM2"} {
+		kp, _ := crypto.GenerateKeyPair(cfg, alg, "jwe")
+		if _, err := jose.Encrypt([]byte("x"), []jose.RecipientKey{{PublicKey: kp}}); err == nil {
+			t.Fatalf("%s: expected unsupported key error", alg)
+		}
+	}
+	doc := did.AssembleMultiKeyDIDDocument("did:sbp:none", nil, nil, nil)
+	if _, err := jose.EncryptForDIDs([]byte("x"), []*did.DIDDocument{doc}); err == nil {
+		t.Fatalf("DID without keyAgreement should fail")
+	}
+}
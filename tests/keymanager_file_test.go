@@ -0,0 +1,266 @@
+package tests
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/helailiang/sbp-did-sdk-go/pkg/crypto"
+	"github.com/helailiang/sbp-did-sdk-go/pkg/crypto/kmtest"
+)
+
+func newTestFileKeyManager(t *testing.T, dir string) *crypto.FileKeyManager {
+	t.Helper()
+	km, err := crypto.NewFileKeyManager(dir, crypto.WithScryptParams(1<<10, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return km
+}
+
+func TestFileKeyManagerConformance(t *testing.T) {
+	kmtest.Run(t, func(t *testing.T) kmtest.Backend {
+		km := newTestFileKeyManager(t, t.TempDir())
+		if err := km.Unlock("password"); err != nil {
+			t.Fatal(err)
+		}
+		return km
+	})
+}
+
+func TestFileKeyManagerPersistence(t *testing.T) {
+	dir := t.TempDir()
+	km := newTestFileKeyManager(t, dir)
+	if _, _, err := km.Create(crypto.ECDSAP256); !errors.Is(err, crypto.ErrKeystoreLocked) {
+		t.Fatalf("Create on locked keystore: %v", err)
+	}
+	if err := km.Unlock("password"); err != nil {
+		t.Fatal(err)
+	}
+	signID, pub, err := km.Create(crypto.ECDSAP256, crypto.WithPurpose(crypto.PurposeSigning))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encID, _, err := km.Create(crypto.AES256, crypto.WithPurpose(crypto.PurposeEncryption))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct, err := km.Encrypt(encID, []byte("hello world"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256([]byte("hello world"))
+
+	// 模拟重启：新实例读取同一目录
+	reopened := newTestFileKeyManager(t, dir)
+	if !reopened.Locked() {
+		t.Fatal("new FileKeyManager should be locked")
+	}
+	got, err := reopened.Get(signID)
+	if err != nil || string(got) != string(pub) {
+		t.Fatalf("Get on locked keystore: %v", err)
+	}
+	meta, err := reopened.Metadata(encID)
+	if err != nil || meta.KeyType != crypto.AES256 || meta.Purpose != crypto.PurposeEncryption || meta.CreatedAt.IsZero() {
+		t.Fatalf("Metadata = %+v, %v", meta, err)
+	}
+	ids, err := reopened.List()
+	if err != nil || len(ids) != 2 {
+		t.Fatalf("List = %v, %v", ids, err)
+	}
+	if _, err := reopened.Sign(signID, digest[:]); !errors.Is(err, crypto.ErrKeystoreLocked) {
+		t.Fatalf("Sign on locked keystore: %v", err)
+	}
+	if err := reopened.Unlock("wrong"); !errors.Is(err, crypto.ErrWrongPassword) {
+		t.Fatalf("Unlock with wrong password: %v", err)
+	}
+	if err := reopened.Unlock("password"); err != nil {
+		t.Fatal(err)
+	}
+	sig, err := reopened.Sign(signID, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := km.Verify(signID, digest[:], sig); err != nil || !ok {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	pt, err := reopened.Decrypt(encID, ct)
+	if err != nil || string(pt) != "hello world" {
+		t.Fatalf("Decrypt after reopen: %v", err)
+	}
+
+	reopened.Lock()
+	if _, err := reopened.Decrypt(encID, ct); !errors.Is(err, crypto.ErrKeystoreLocked) {
+		t.Fatalf("Decrypt after Lock: %v", err)
+	}
+}
+
+func TestFileKeyManagerFileFormat(t *testing.T) {
+	dir := t.TempDir()
+	km := newTestFileKeyManager(t, dir)
+	if err := km.Unlock("password"); err != nil {
+		t.Fatal(err)
+	}
+	keyID, _, err := km.Create(crypto.ECDSASecp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := km.ExportPrivateKey(keyID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, keyID+".json")
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Fatalf("key file mode = %v", info.Mode().Perm())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file struct {
+		ID      string `json:"id"`
+		KeyType string `json:"keyType"`
+		Crypto  struct {
+			Cipher string `json:"cipher"`
+			KDF    string `json:"kdf"`
+		} `json:"crypto"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatal(err)
+	}
+	if file.ID != keyID || file.KeyType != string(crypto.ECDSASecp256k1) || file.Crypto.Cipher != "aes-256-gcm" || file.Crypto.KDF != "scrypt" {
+		t.Fatalf("unexpected key file: %s", data)
+	}
+	if strings.Contains(string(data), hex.EncodeToString(raw)) {
+		t.Fatal("key file contains plaintext private key")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".tmp-") {
+			t.Fatalf("temporary file left behind: %s", e.Name())
+		}
+	}
+
+	// 篡改明文字段后无法解密
+	tampered := strings.Replace(string(data), string(crypto.ECDSASecp256k1), string(crypto.ECDSAP256), 1)
+	if err := os.WriteFile(path, []byte(tampered), 0600); err != nil {
+		t.Fatal(err)
+	}
+	reopened := newTestFileKeyManager(t, dir)
+	if err := reopened.Unlock("password"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reopened.ExportPrivateKey(keyID); err == nil {
+		t.Fatal("tampered key file decrypted")
+	}
+	if _, err := reopened.Get("../" + keyID); err == nil {
+		t.Fatal("path traversal key id accepted")
+	}
+}
+
+func TestFileKeyManagerChangePassword(t *testing.T) {
+	dir := t.TempDir()
+	km := newTestFileKeyManager(t, dir)
+	if err := km.Unlock("old-password"); err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, kt := range []crypto.KeyType{crypto.ED25519, crypto.RSA2048, crypto.SM2} {
+		id, _, err := km.Create(kt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	other := newTestFileKeyManager(t, dir)
+	if err := other.Unlock("old-password"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := km.ChangePassword("wrong", "new-password"); !errors.Is(err, crypto.ErrWrongPassword) {
+		t.Fatalf("ChangePassword with wrong password: %v", err)
+	}
+	if err := km.ChangePassword("old-password", "new-password"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := km.Create(crypto.ECDSAP256); err != nil {
+		t.Fatalf("Create after ChangePassword: %v", err)
+	}
+	// 其他实例持有旧口令，不能再写入旧口令加密的密钥
+	if _, _, err := other.Create(crypto.ECDSAP256); !errors.Is(err, crypto.ErrWrongPassword) {
+		t.Fatalf("Create with stale password: %v", err)
+	}
+
+	reopened := newTestFileKeyManager(t, dir)
+	if err := reopened.Unlock("old-password"); !errors.Is(err, crypto.ErrWrongPassword) {
+		t.Fatalf("Unlock with old password: %v", err)
+	}
+	if err := reopened.Unlock("new-password"); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range ids {
+		if _, err := reopened.ExportPrivateKey(id); err != nil {
+			t.Fatalf("ExportPrivateKey %s after ChangePassword: %v", id, err)
+		}
+	}
+}
+
+func TestFileKeyManagerMultiInstance(t *testing.T) {
+	dir := t.TempDir()
+	const instances, perInstance = 4, 5
+	managers := make([]*crypto.FileKeyManager, instances)
+	for i := range managers {
+		managers[i] = newTestFileKeyManager(t, dir)
+		if err := managers[i].Unlock("password"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, instances*perInstance)
+	for _, km := range managers {
+		wg.Add(1)
+		go func(km *crypto.FileKeyManager) {
+			defer wg.Done()
+			for i := 0; i < perInstance; i++ {
+				if _, _, err := km.Create(crypto.ECDSAP256); err != nil {
+					errs <- err
+				}
+			}
+		}(km)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	ids, err := managers[0].List()
+	if err != nil || len(ids) != instances*perInstance {
+		t.Fatalf("List = %d keys, %v", len(ids), err)
+	}
+	digest := sha256.Sum256([]byte("hello"))
+	if _, err := managers[1].Sign(ids[0], digest[:]); err != nil {
+		t.Fatal(err)
+	}
+	if err := managers[0].Delete(ids[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := managers[1].Sign(ids[0], digest[:]); err == nil {
+		t.Fatal("key deleted by another instance still usable")
+	}
+}
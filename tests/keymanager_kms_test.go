@@ -1,39 +1,56 @@
 package tests
 
 import (
+	"crypto/sha256"
 	"os"
 	"testing"
+
 	"github.com/helailiang/sbp-did-sdk-go/pkg/crypto"
+	"github.com/helailiang/sbp-did-sdk-go/pkg/crypto/kmsemu"
 )
 
-func TestHuaweiKMSKeyManager(t *testing.T) {
-	endpoint := os.Getenv("KMS_ENDPOINT")
-	ak := os.Getenv("KMS_AK")
-	sk := os.Getenv("KMS_SK")
-	projectId := os.Getenv("KMS_PROJECT_ID")
-	km, err := crypto.NewHuaweiKMSKeyManager(endpoint, ak, sk, projectId)
+// newTestHuaweiKMS 设置KMS_ENDPOINT时连接真实KMS，否则使用进程内模拟服务
+// 连接真实KMS时返回的模拟服务为nil，依赖模拟服务的测试应跳过
+func newTestHuaweiKMS(t *testing.T) (*kmsemu.Server, *crypto.HuaweiKMSKeyManager) {
+	t.Helper()
+	if endpoint := os.Getenv("KMS_ENDPOINT"); endpoint != "" {
+		km, err := crypto.NewHuaweiKMSKeyManager(endpoint, os.Getenv("KMS_AK"), os.Getenv("KMS_SK"), os.Getenv("KMS_PROJECT_ID"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return nil, km
+	}
+	srv := kmsemu.NewServer()
+	t.Cleanup(srv.Close)
+	km, err := srv.KeyManager()
 	if err != nil {
 		t.Fatal(err)
 	}
-	keyName := "test-key"
-	_, err = km.GenerateKey("RSA", keyName)
+	return srv, km
+}
+
+func TestHuaweiKMSKeyManager(t *testing.T) {
+	_, km := newTestHuaweiKMS(t)
+	keyID, _, err := km.Create(crypto.RSA2048)
 	if err != nil {
 		t.Fatal(err)
 	}
-	pub, err := km.GetPublicKey(keyName)
+	defer km.Delete(keyID)
+	pub, err := km.Get(keyID)
 	if err != nil {
 		t.Fatal(err)
 	}
-	t.Logf("PublicKey: %s", pub)
-	sig, err := km.Sign(keyName, []byte("hello world"))
+	t.Logf("PublicKey: %x", pub)
+	digest := sha256.Sum256([]byte("hello world"))
+	sig, err := km.Sign(keyID, digest[:])
 	if err != nil {
 		t.Fatal(err)
 	}
-	valid, err := km.Verify(keyName, []byte("hello world"), sig)
+	valid, err := km.Verify(keyID, digest[:], sig)
 	if err != nil {
 		t.Fatal(err)
 	}
 	if !valid {
 		t.Fatal("Signature verify failed")
 	}
-} 
\ No newline at end of file
+}
@@ -7,21 +7,20 @@ import (
 
 func TestLocalKeyManager(t *testing.T) {
 	km := crypto.NewLocalKeyManager()
-	keyName := "local-key"
-	_, err := km.Create(crypto.ED25519, keyName)
+	keyID, _, err := km.Create(crypto.ED25519)
 	if err != nil {
 		t.Fatal(err)
 	}
-	pub, err := km.GetPublicKey(keyName)
+	pub, err := km.Get(keyID)
 	if err != nil {
 		t.Fatal(err)
 	}
 	t.Logf("PublicKey: %x", pub)
-	sig, err := km.Sign(keyName, []byte("hello world"))
+	sig, err := km.Sign(keyID, []byte("hello world"))
 	if err != nil {
 		t.Fatal(err)
 	}
-	valid, err := km.Verify(keyName, []byte("hello world"), sig)
+	valid, err := km.Verify(keyID, []byte("hello world"), sig)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -29,3 +28,45 @@ func TestLocalKeyManager(t *testing.T) {
 		t.Fatal("Signature verify failed")
 	}
 }
+
+func TestLocalKeyManagerAES256(t *testing.T) {
+	km := crypto.NewLocalKeyManager()
+	keyID, pub, err := km.Create(crypto.AES256)
+	if err != nil || pub != nil {
+		t.Fatalf("Create AES256 failed: %v", err)
+	}
+	ct, err := km.Encrypt(keyID, []byte("hello world"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct[1] != crypto.SchemeAES256GCM {
+		t.Fatalf("unexpected scheme %d", ct[1])
+	}
+	pt, err := km.Decrypt(keyID, ct)
+	if err != nil || string(pt) != "hello world" {
+		t.Fatalf("Decrypt failed: %v", err)
+	}
+	ct[len(ct)-1] ^= 0x01
+	if _, err := km.Decrypt(keyID, ct); err == nil {
+		t.Fatal("tampered ciphertext decrypted")
+	}
+
+	raw, err := km.ExportPrivateKey(keyID)
+	if err != nil || len(raw) != 32 {
+		t.Fatalf("ExportPrivateKey failed: %v", err)
+	}
+	importedID, err := km.ImportPrivateKey(raw, crypto.AES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct[len(ct)-1] ^= 0x01
+	if pt, err := km.Decrypt(importedID, ct); err != nil || string(pt) != "hello world" {
+		t.Fatalf("imported key cannot decrypt: %v", err)
+	}
+	if _, err := km.Get(keyID); err == nil {
+		t.Fatal("Get should fail for a symmetric key")
+	}
+	if _, err := km.Sign(keyID, []byte("hello world")); err == nil {
+		t.Fatal("Sign should fail for a symmetric key")
+	}
+}
@@ -0,0 +1,198 @@
+//go:build cgo
+
+package tests
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/miekg/pkcs11"
+
+	"github.com/helailiang/sbp-did-sdk-go/pkg/crypto"
+	"github.com/helailiang/sbp-did-sdk-go/pkg/crypto/hsm"
+	"github.com/helailiang/sbp-did-sdk-go/pkg/crypto/kmtest"
+)
+
+const (
+	softHSMTokenLabel = "sbp-did-sdk-test"
+	softHSMPIN        = "1234"
+	softHSMSOPIN      = "12345678"
+)
+
+// softHSMModules SoftHSMv2常见安装路径，可通过SOFTHSM2_MODULE指定
+var softHSMModules = []string{
+	"/usr/lib/softhsm/libsofthsm2.so",
+	"/usr/lib/x86_64-linux-gnu/softhsm/libsofthsm2.so",
+	"/usr/lib64/pkcs11/libsofthsm2.so",
+	"/usr/local/lib/softhsm/libsofthsm2.so",
+	"/opt/homebrew/lib/softhsm/libsofthsm2.so",
+}
+
+var (
+	softHSMOnce   sync.Once
+	softHSMModule string
+	softHSMErr    error
+)
+
+// setupSoftHSM 在临时目录中初始化一个SoftHSMv2令牌，同一进程内只执行一次
+// 未安装SoftHSMv2时跳过测试
+func setupSoftHSM(t *testing.T) string {
+	t.Helper()
+	softHSMOnce.Do(func() {
+		module := os.Getenv("SOFTHSM2_MODULE")
+		if module == "" {
+			for _, path := range softHSMModules {
+				if _, err := os.Stat(path); err == nil {
+					module = path
+					break
+				}
+			}
+		}
+		if module == "" {
+			return
+		}
+		softHSMModule = module
+		softHSMErr = initSoftHSMToken(module)
+	})
+	if softHSMModule == "" {
+		t.Skip("SoftHSMv2 not found; set SOFTHSM2_MODULE to run PKCS#11 tests")
+	}
+	if softHSMErr != nil {
+		t.Fatal(softHSMErr)
+	}
+	return softHSMModule
+}
+
+func initSoftHSMToken(module string) error {
+	dir, err := os.MkdirTemp("", "softhsm")
+	if err != nil {
+		return err
+	}
+	tokens := filepath.Join(dir, "tokens")
+	if err := os.Mkdir(tokens, 0700); err != nil {
+		return err
+	}
+	conf := filepath.Join(dir, "softhsm2.conf")
+	if err := os.WriteFile(conf, []byte("directories.tokendir = "+tokens+"\nobjectstore.backend = file\n"), 0600); err != nil {
+		return err
+	}
+	os.Setenv("SOFTHSM2_CONF", conf)
+
+	p := pkcs11.New(module)
+	if p == nil {
+		return fmt.Errorf("failed to load %s", module)
+	}
+	defer p.Destroy()
+	if err := p.Initialize(); err != nil {
+		return err
+	}
+	defer p.Finalize()
+	slots, err := p.GetSlotList(true)
+	if err != nil || len(slots) == 0 {
+		return fmt.Errorf("no SoftHSM slot: %v", err)
+	}
+	if err := p.InitToken(slots[0], softHSMSOPIN, softHSMTokenLabel); err != nil {
+		return fmt.Errorf("C_InitToken: %w", err)
+	}
+	// SoftHSM初始化令牌后会重新分配slot编号
+	slots, err = p.GetSlotList(true)
+	if err != nil {
+		return err
+	}
+	for _, slot := range slots {
+		info, err := p.GetTokenInfo(slot)
+		if err != nil || strings.TrimRight(info.Label, " ") != softHSMTokenLabel {
+			continue
+		}
+		session, err := p.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+		if err != nil {
+			return err
+		}
+		defer p.CloseSession(session)
+		if err := p.Login(session, pkcs11.CKU_SO, softHSMSOPIN); err != nil {
+			return fmt.Errorf("C_Login(SO): %w", err)
+		}
+		defer p.Logout(session)
+		return p.InitPIN(session, softHSMPIN)
+	}
+	return fmt.Errorf("token %s not found after initialization", softHSMTokenLabel)
+}
+
+func newTestPKCS11KeyManager(t *testing.T) *hsm.PKCS11KeyManager {
+	t.Helper()
+	module := setupSoftHSM(t)
+	km, err := hsm.NewPKCS11KeyManager(hsm.Config{
+		Module:     module,
+		TokenLabel: softHSMTokenLabel,
+		PIN:        softHSMPIN,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { km.Close() })
+	return km
+}
+
+func TestPKCS11KeyManagerConformance(t *testing.T) {
+	kmtest.Run(t, func(t *testing.T) kmtest.Backend {
+		return newTestPKCS11KeyManager(t)
+	},
+		kmtest.WithKeyTypes(crypto.ECDSAP256, crypto.ECDSAP384, crypto.ECDSASecp256k1, crypto.RSA2048),
+		kmtest.WithEncryptionKeyTypes(),
+		kmtest.WithoutExport(),
+	)
+}
+
+func TestPKCS11KeyManager(t *testing.T) {
+	km := newTestPKCS11KeyManager(t)
+	keyID, pub, err := km.Create(crypto.ECDSAP256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256([]byte("hello world"))
+	sig, err := km.Sign(keyID, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 签名可由本地公钥独立验证
+	ecPub, err := crypto.ParseECPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !crypto.VerifyECDSA(ecPub, digest[:], sig) {
+		t.Fatal("signature does not verify with the exported public key")
+	}
+
+	// 其他实例（同一令牌的新会话）可使用已生成的密钥
+	other := newTestPKCS11KeyManager(t)
+	if ok, err := other.Verify(keyID, digest[:], sig); err != nil || !ok {
+		t.Fatalf("Verify from another session failed: %v", err)
+	}
+	if _, err := other.ExportPrivateKey(keyID); err == nil {
+		t.Fatal("private key should not be extractable")
+	}
+	if _, _, err := km.Create(crypto.SM2); err == nil {
+		t.Fatal("SM2 should require vendor mechanisms")
+	}
+	if err := other.Delete(keyID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := km.Sign(keyID, digest[:]); err == nil {
+		t.Fatal("deleted key still usable")
+	}
+}
+
+func TestPKCS11KeyManagerConfigErrors(t *testing.T) {
+	if _, err := hsm.NewPKCS11KeyManager(hsm.Config{PIN: softHSMPIN}); err == nil {
+		t.Fatal("missing module path accepted")
+	}
+	if _, err := hsm.NewPKCS11KeyManager(hsm.Config{Module: filepath.Join(t.TempDir(), "missing.so")}); err == nil {
+		t.Fatal("nonexistent module loaded")
+	}
+}
@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/helailiang/sbp-did-sdk-go/pkg/utils"
+	"github.com/tjfoc/gmsm/sm3"
+)
+
+// GB/T 32905-2016 附录A示例
+var sm3Vectors = []struct {
+	in   string
+	want string
+}{
+	{"abc", "66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0"},
+	{strings.Repeat("abcd", 16), "debe9ff92275b8a138604889c18e5a4d6fdb70e5387e5765293dcba39c0c5732"},
+}
+
+func TestSM3StandardVectors(t *testing.T) {
+	for _, v := range sm3Vectors {
+		got, err := utils.CalculateHash([]byte(v.in), utils.SM3)
+		if err != nil {
+			t.Fatalf("CalculateHash failed: %v", err)
+		}
+		if got != v.want {
+			t.Fatalf("SM3(%q) = %s, want %s", v.in, got, v.want)
+		}
+		sum := utils.SM3Sum([]byte(v.in))
+		if hex.EncodeToString(sum[:]) != v.want {
+			t.Fatalf("SM3Sum(%q) mismatch", v.in)
+		}
+	}
+}
+
+func TestSM3Streaming(t *testing.T) {
+	data := make([]byte, 1<<20+37)
+	rand.Read(data)
+	oneShot, _ := utils.CalculateHash(data, utils.SM3)
+
+	// 分块写入与一次性计算结果一致，Sum不影响后续写入
+	h := utils.NewSM3()
+	sizes := []int{1, 63, 64, 65, 4099}
+	for off, i := 0, 0; off < len(data); i++ {
+		end := off + sizes[i%len(sizes)]
+		if end > len(data) {
+			end = len(data)
+		}
+		h.Write(data[off:end])
+		h.Sum(nil)
+		off = end
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != oneShot {
+		t.Fatalf("chunked hash mismatch: %s != %s", got, oneShot)
+	}
+
+	fromReader, err := utils.CalculateHashFromReader(bytes.NewReader(data), utils.SM3)
+	if err != nil || fromReader != oneShot {
+		t.Fatalf("CalculateHashFromReader mismatch: %s, %v", fromReader, err)
+	}
+
+	h.Reset()
+	h.Write([]byte("abc"))
+	if hex.EncodeToString(h.Sum(nil)) != sm3Vectors[0].want {
+		t.Fatalf("Reset should restore initial state")
+	}
+}
+
+func TestSM3MatchesReferenceImplementation(t *testing.T) {
+	for n := 0; n < 300; n++ {
+		data := make([]byte, n)
+		rand.Read(data)
+		want := sm3.Sm3Sum(data)
+		got := utils.SM3Sum(data)
+		if !bytes.Equal(got[:], want) {
+			t.Fatalf("length %d: %x != %x", n, got, want)
+		}
+	}
+}
+
+func TestCalculateHashAlgorithms(t *testing.T) {
+	sha, _ := utils.CalculateHash([]byte("abc"), utils.SHA256)
+	if sha != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" {
+		t.Fatalf("unexpected SHA256: %s", sha)
+	}
+	sm, _ := utils.CalculateHash([]byte("abc"), utils.SM3)
+	if sm == sha {
+		t.Fatalf("SM3 must not fall back to SHA256")
+	}
+	if _, err := utils.NewHash("MD5"); err == nil {
+		t.Fatalf("expected error for unsupported algorithm")
+	}
+}